@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -74,7 +76,7 @@ func TestPairStairAcceptance(t *testing.T) {
 			},
 			args: []string{"--strategy", "least-recent", "--window", "1y"},
 			wantContains: []string{
-				"least recent collaborations",
+				"Pairing Recommendations (least recent collaborations first, greedy matching):",
 				"days ago",
 			},
 			wantExitCode: 0,
@@ -91,6 +93,540 @@ func TestPairStairAcceptance(t *testing.T) {
 			},
 			wantExitCode: 0,
 		},
+		{
+			name: "missing sub-team lists the available sub-teams",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithSubTeams(t, repoDir)
+			},
+			args:         []string{"--team", "mobile", "--window", "1y"},
+			wantContains: []string{`sub-team "mobile" not found (available: frontend, backend)`},
+			wantExitCode: 1,
+		},
+		{
+			name: "sub-team tags in recommendations",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithSubTeams(t, repoDir)
+			},
+			args: []string{"--team", "frontend", "--window", "1y"},
+			wantContains: []string{
+				"CF [frontend] <-> DU [frontend]",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "rotation plan",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args: []string{"--plan", "3", "--window", "1y"},
+			wantContains: []string{
+				"Pairing Plan (next 3 working days, least-paired)",
+				"(unpaired)",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "init-team requires an organization",
+			setupRepo: func(t *testing.T, repoDir string) {
+				// No repo setup needed for init-team
+			},
+			args:         []string{"init-team"},
+			wantContains: []string{"-github-org is required"},
+			wantExitCode: 1,
+		},
+		{
+			name: "metric prints a single value",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"--metric", "coverage", "--window", "1y"},
+			wantContains: []string{"1.00\n"},
+			wantExitCode: 0,
+		},
+		{
+			name: "strict team lists participants missing from the team file",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"--strict-team", "--window", "1y"},
+			wantContains: []string{"1 participant(s) not in .team", "test@example.com"},
+			wantExitCode: 1,
+		},
+		{
+			name: "matrix subcommand shares the window flag",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"matrix", "--window", "1y"},
+			wantContains: []string{"Legend:", "Alice Smith"},
+			wantExitCode: 0,
+		},
+		{
+			name: "recommend subcommand",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"recommend", "--window", "1y"},
+			wantContains: []string{"Pairing Recommendations"},
+			wantExitCode: 0,
+		},
+		{
+			name: "stats subcommand prints every metric",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"stats", "--window", "1y"},
+			wantContains: []string{"coverage: 1.00\n", "developers: "},
+			wantExitCode: 0,
+		},
+		{
+			name: "validate-team subcommand lists participants missing from the team file",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"validate-team", "--window", "1y"},
+			wantContains: []string{"1 participant(s) not in .team", "test@example.com"},
+			wantExitCode: 1,
+		},
+		{
+			name: "detect self pairs warns about a co-author with the author's name",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Pair with myself\n\nCo-authored-by: Test User <test@home.example>")
+			},
+			args:         []string{"--detect-self-pairs", "--window", "1y"},
+			wantContains: []string{"Test User: test@example.com with co-author test@home.example (1 commits)"},
+			wantExitCode: 0,
+		},
+		{
+			name: "matrix totals",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+			},
+			args:         []string{"matrix", "--totals", "--window", "1y"},
+			wantContains: []string{"Total   \n", "\nTotal   "},
+			wantExitCode: 0,
+		},
+		{
+			name: "outside a git repository",
+			setupRepo: func(t *testing.T, repoDir string) {
+				// No repo: the directory is empty
+			},
+			args:         []string{"--window", "1y"},
+			wantContains: []string{"is not in a git repository: cd into a repository, or pass -repo <path>"},
+			wantExitCode: 3,
+		},
+		{
+			name: "repo flag analyzes another directory",
+			setupRepo: func(t *testing.T, repoDir string) {
+				project := filepath.Join(repoDir, "project")
+				if err := os.Mkdir(project, 0755); err != nil {
+					t.Fatalf("failed to create project directory: %v", err)
+				}
+				setupRepoWithTeamFile(t, project)
+			},
+			args:         []string{"--repo", "project", "--window", "1y"},
+			wantContains: []string{"Alice Smith", "Legend:"},
+			wantExitCode: 0,
+		},
+		{
+			name: "repo flag analyzes a bare repository",
+			setupRepo: func(t *testing.T, repoDir string) {
+				project := filepath.Join(repoDir, "project")
+				if err := os.Mkdir(project, 0755); err != nil {
+					t.Fatalf("failed to create project directory: %v", err)
+				}
+				setupBasicPairingRepo(t, project)
+				runGitCommand(t, repoDir, "clone", "--bare", "project", "server.git")
+			},
+			args:         []string{"--repo", "server.git", "--window", "1y"},
+			wantContains: []string{"alice@example.com", "bob@example.com", "Pairing Recommendations"},
+			wantExitCode: 0,
+		},
+		{
+			name: "name variants report",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Nickname\n\nCo-authored-by: ally <alice@example.com>")
+			},
+			args:         []string{"--report", "name-variants", "--window", "1y"},
+			wantContains: []string{`alice@example.com: "Alice Smith" (2), "ally" (1)`},
+			wantExitCode: 0,
+		},
+		{
+			name:         "missing-trailers report",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"--report", "missing-trailers", "--window", "1y"},
+			wantContains: []string{"Missing Trailers", "1 of 4 commits (25%)", "Overall: 1 of 4 commits (25%) have no co-authors"},
+			wantExitCode: 0,
+		},
+		{
+			name:         "several outputs to files",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"--output", "html,json", "--out", "report.html,report.json", "--window", "1y"},
+			wantContains: []string{"Wrote html output to report.html", "Wrote json output to report.json"},
+			wantExitCode: 0,
+		},
+		{
+			name:         "narrative report output",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"--output", "report", "--window", "1y"},
+			wantContains: []string{"Over the last year,", "paired most", "Next, we suggest"},
+			wantExitCode: 0,
+		},
+		{
+			name: "recency window widens last paired",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommandWithDate(t, repoDir, time.Now().AddDate(0, 0, -40), "commit", "--allow-empty", "-m", "Old work\n\nCo-authored-by: Bob Jones <bob@example.com>\nCo-authored-by: Carol Davis <carol@example.com>")
+				runGitCommandWithDate(t, repoDir, time.Now().AddDate(0, 0, -1), "commit", "--allow-empty", "-m", "New work\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\n")
+			},
+			args:         []string{"--window", "2w", "--recency-window", "1y", "--strategy", "least-recent"},
+			wantContains: []string{"last paired 40 days ago"},
+			wantExitCode: 0,
+		},
+		{
+			name: "invalid co-author emails are warned about",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Typo\n\nCo-authored-by: Alice <not an email>")
+			},
+			args:         []string{"--window", "1y"},
+			wantContains: []string{"pass -strict-emails to ignore them", `"not an email"`, "Alice"},
+			wantExitCode: 0,
+		},
+		{
+			name: "strict emails ignores invalid co-authors",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Typo\n\nCo-authored-by: Alice <not an email>")
+			},
+			args:         []string{"--strict-emails", "--window", "1y"},
+			wantContains: []string{`Note: ignoring co-authors with invalid emails: "not an email"`},
+			wantExitCode: 0,
+		},
+		{
+			name: "suggestions per person report",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\nFrank Green <frank@example.com>\n")
+			},
+			args:         []string{"--report", "suggestions-per-person", "--window", "1y"},
+			wantContains: []string{"Suggestions Per Person", "Alice Smith          -> Frank Green (never paired)", "Carol Davis          -> Frank Green (never paired)"},
+			wantExitCode: 0,
+		},
+		{
+			name: "dump commits prints each parsed commit as JSON",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+			},
+			args:         []string{"--dump-commits", "--window", "1y"},
+			wantContains: []string{`"author":{"name":"Test User","email":"test@example.com"}`, `"co_authors":[{"name":"Alice Smith","email":"alice@example.com"},{"name":"Bob Jones","email":"bob@example.com"}]`, `"co_authors":[]`},
+			wantExitCode: 0,
+		},
+		{
+			name: "newcomers report lists developers who first paired in the window",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Test User")
+				runGitCommand(t, repoDir, "config", "user.email", "test@example.com")
+				runGitCommandWithDate(t, repoDir, time.Now().AddDate(-1, 0, 0), "commit", "--allow-empty", "-m", "Old pairing\n\nCo-authored-by: Alice Smith <alice@example.com>")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Recent pairing\n\nCo-authored-by: Alice Smith <alice@example.com>")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "First pairing\n\nCo-authored-by: Bob Jones <bob@example.com>")
+			},
+			args:         []string{"--report", "newcomers", "--window", "1w"},
+			wantContains: []string{"Newcomers (first paired in this window):", "Bob Jones            first paired " + time.Now().Format("2006-01-02")},
+			wantExitCode: 0,
+		},
+		{
+			name: "legend shows roles from the team file",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com> [Lead]\nBob Jones <bob@example.com>\n")
+			},
+			args:         []string{"matrix", "--window", "1y"},
+			wantContains: []string{"AS     = Alice Smith          [Lead] alice@example.com", "BJ     = Bob Jones                   bob@example.com"},
+			wantExitCode: 0,
+		},
+		{
+			name: "window all reads the whole history",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Test User")
+				runGitCommand(t, repoDir, "config", "user.email", "test@example.com")
+				runGitCommandWithDate(t, repoDir, time.Now().AddDate(-10, 0, 0), "commit", "--allow-empty", "-m", "Ancient pairing\n\nCo-authored-by: Alice Smith <alice@example.com>")
+			},
+			args:         []string{"matrix", "--window", "all"},
+			wantContains: []string{"Note: -window all reads the repository's whole history", "AS     = Alice Smith", "TU      1"},
+			wantExitCode: 0,
+		},
+		{
+			name: "invalid window is a usage error",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+			},
+			args:         []string{"--window", "2x"},
+			wantContains: []string{`Invalid options: invalid -window "2x"`},
+			wantExitCode: 2,
+		},
+		{
+			name: "coverage dropping past the threshold fails the gate",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupRepoWithTeamFile(t, repoDir)
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\nFrank Green <frank@example.com>\n")
+				writeFile(t, repoDir, "baseline.json", `{"schema_version": 1, "coverage": {"paired": 6, "possible": 6, "ratio": 1}}`)
+			},
+			args:         []string{"--baseline", "baseline.json", "--max-coverage-drop", "5", "--window", "1y"},
+			wantContains: []string{"Coverage dropped by", "more than the allowed 5.0"},
+			wantExitCode: 4,
+		},
+		{
+			name: "no pairing fails the empty gate",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Working alone")
+			},
+			args:         []string{"--fail-on-empty", "--window", "1y"},
+			wantContains: []string{"No pairing found in the last year"},
+			wantExitCode: 4,
+		},
+		{
+			name:         "pairing passes the empty gate",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"matrix", "--fail-on-empty", "--window", "1y"},
+			wantContains: []string{"AS     = Alice Smith"},
+			wantExitCode: 0,
+		},
+		{
+			name: "optimal cutoff note doesn't count observers",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\nTest User <test@example.com> @observer\n")
+			},
+			args:         []string{"--strategy", "coverage", "--optimal-cutoff", "1", "--window", "1y"},
+			wantContains: []string{"Note: 3 developers is more than the optimal cutoff (1); using greedy matching"},
+			wantExitCode: 0,
+		},
+		{
+			name:         "matrix shows the commit sizes",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"matrix", "--window", "1y"},
+			wantContains: []string{"Commit sizes: 25% solo, 0% pairs, 75% mobs (of 4 commits)"},
+			wantExitCode: 0,
+		},
+		{
+			name:         "commit sizes only count the -only developers",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"matrix", "--only", "alice@example.com,bob@example.com", "--window", "1y"},
+			wantContains: []string{"Commit sizes: 67% solo, 33% pairs, 0% mobs (of 3 commits)"},
+			wantExitCode: 0,
+		},
+		{
+			name: "matrix style recency bands shows how recently each pair paired",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+			},
+			args:         []string{"matrix", "--matrix-style", "recency-bands", "--no-unicode", "--window", "1y"},
+			wantContains: []string{"Last paired: # within a week, + within a month, o within a quarter, . longer ago, blank never", "AS      -       #"},
+			wantExitCode: 0,
+		},
+		{
+			name: "linked worktree reads its own team file from any subdirectory",
+			setupRepo: func(t *testing.T, repoDir string) {
+				primary := filepath.Join(repoDir, "main")
+				runGitCommand(t, repoDir, "init", primary)
+				runGitCommand(t, primary, "config", "user.name", "Test User")
+				runGitCommand(t, primary, "config", "user.email", "test@example.com")
+				runGitCommand(t, primary, "commit", "--allow-empty", "-m", "Pairing\n\nCo-authored-by: Alice Smith <alice@example.com>\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommand(t, primary, "worktree", "add", "-b", "feature", filepath.Join(repoDir, "worktree"))
+				writeFile(t, filepath.Join(repoDir, "worktree"), ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\n")
+				if err := os.Mkdir(filepath.Join(repoDir, "worktree", "docs"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			},
+			args:         []string{"matrix", "--window", "1y", "--repo", "worktree/docs"},
+			wantContains: []string{"AS     = Alice Smith", "BJ     = Bob Jones", "AS      -       1"},
+			wantExitCode: 0,
+		},
+		{
+			name: "recurse submodules adds the submodules' pairing",
+			setupRepo: func(t *testing.T, repoDir string) {
+				library := filepath.Join(repoDir, "library")
+				runGitCommand(t, repoDir, "init", library)
+				runGitCommand(t, library, "config", "user.name", "Test User")
+				runGitCommand(t, library, "config", "user.email", "test@example.com")
+				runGitCommand(t, library, "commit", "--allow-empty", "-m", "Library pairing\n\nCo-authored-by: Carol Davis <carol@example.com>")
+
+				app := filepath.Join(repoDir, "app")
+				runGitCommand(t, repoDir, "init", app)
+				runGitCommand(t, app, "config", "user.name", "Test User")
+				runGitCommand(t, app, "config", "user.email", "test@example.com")
+				runGitCommand(t, app, "-c", "protocol.file.allow=always", "submodule", "add", library, "library")
+				runGitCommand(t, app, "commit", "-m", "App pairing\n\nCo-authored-by: Alice Smith <alice@example.com>")
+			},
+			args:         []string{"matrix", "--window", "1y", "--repo", "app", "--recurse-submodules"},
+			wantContains: []string{"AS     = Alice Smith", "CD     = Carol Davis"},
+			wantExitCode: 0,
+		},
+		{
+			name: "too few active developers skips recommendations and says why",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Working alone")
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\n")
+			},
+			args:         []string{"--window", "1y"},
+			wantContains: []string{"Skipping pairing recommendations - not enough active developers to recommend (need 2, have 1)"},
+			wantExitCode: 0,
+		},
+		{
+			name: "min-team raises the number of active developers needed",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+			},
+			args:         []string{"recommend", "--min-team", "5", "--window", "1y"},
+			wantContains: []string{"not enough active developers to recommend (need 5, have 4)"},
+			wantExitCode: 0,
+		},
+		{
+			name: "include-reviewers shows reviews in their own matrix",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Pairing, reviewed\n\nCo-authored-by: Bob Jones <bob@example.com>\nReviewed-by: Carol Davis <carol@example.com>")
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\n")
+			},
+			args: []string{"matrix", "--window", "1y", "--include-reviewers"},
+			wantContains: []string{
+				"AS      -       1       0",
+				"Reviews (Reviewed-by trailers, not counted as pairing):",
+				"AS      -       0       1",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "stale-developers report flags developers over the threshold",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommandWithDate(t, repoDir, time.Now().AddDate(0, 0, -20), "commit", "--allow-empty", "-m", "Pairing\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nFrank Green <frank@example.com>\n")
+			},
+			args: []string{"--report", "stale-developers", "--stale-threshold", "2w", "--window", "1y"},
+			wantContains: []string{
+				"Stale Developers (days since last pairing, ! over 14 days):",
+				"! FG     Frank Green          never paired",
+				"! AS     Alice Smith          20 days ago",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "mailmap consolidates a developer's emails without a team file",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "alice")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@old.example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Old address\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "New address\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				writeFile(t, repoDir, ".mailmap", "Alice Smith <alice@example.com> <alice@old.example.com>\n")
+			},
+			args:         []string{"matrix", "--window", "1y"},
+			wantContains: []string{"AS     = Alice Smith          alice@example.com", "\nBJ      1       -       \n"},
+			wantExitCode: 0,
+		},
+		{
+			name: "since and until analyze a fixed date range",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommandWithDate(t, repoDir, time.Date(2024, 2, 10, 12, 0, 0, 0, time.Local), "commit", "--allow-empty", "-m", "In the quarter\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommandWithDate(t, repoDir, time.Date(2024, 3, 31, 18, 0, 0, 0, time.Local), "commit", "--allow-empty", "-m", "On its last day\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommandWithDate(t, repoDir, time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local), "commit", "--allow-empty", "-m", "After the quarter\n\nCo-authored-by: Carol Davis <carol@example.com>")
+			},
+			args:         []string{"matrix", "--since", "2024-01-01", "--until", "2024-03-31", "--window", "1w"},
+			wantContains: []string{"Note: -window is ignored with -since and -until", "\nAS      -       2       \nBJ      2       -       \n"},
+			wantExitCode: 0,
+		},
+		{
+			name:         "since after until is a usage error",
+			setupRepo:    setupBasicPairingRepo,
+			args:         []string{"--since", "2024-03-31", "--until", "2024-01-01"},
+			wantContains: []string{"-since 2024-03-31 is after -until 2024-01-01"},
+			wantExitCode: 2,
+		},
+		{
+			name: "list-teams prints each sub-team with its member count",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				writeFile(t, repoDir, ".team", "Alice Smith <alice@example.com>\nBob Jones <bob@example.com>\nCarol Davis <carol@example.com>\n\n[frontend]\nAlice Smith <alice@example.com>\nBob Jones <bob@example.com>\n\n[backend]\nCarol Davis <carol@example.com>\n")
+			},
+			args:         []string{"--list-teams"},
+			wantContains: []string{"Teams (choose a sub-team with -team):", "(main)    3 members", "frontend  2 members", "backend   1 member\n"},
+			wantExitCode: 0,
+		},
+		{
+			name: "a missing include in the team file is an error",
+			setupRepo: func(t *testing.T, repoDir string) {
+				setupBasicPairingRepo(t, repoDir)
+				writeFile(t, repoDir, ".team", "include missing.team\n")
+			},
+			args:         []string{"validate-team"},
+			wantContains: []string{"Error reading .team file: include", "missing.team"},
+			wantExitCode: 1,
+		},
+		{
+			name: "attribution report has the authors down the side",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Driving\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Driving again\n\nCo-authored-by: Bob Jones <bob@example.com>")
+			},
+			args: []string{"--report", "attribution", "--window", "1y"},
+			wantContains: []string{
+				"Attribution Matrix (rows authored with columns as co-author):",
+				"\nAS      -       2       \nBJ      0       -       \n",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "transpose puts the attribution report's authors across the top",
+			setupRepo: func(t *testing.T, repoDir string) {
+				runGitCommand(t, repoDir, "init")
+				runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+				runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Driving\n\nCo-authored-by: Bob Jones <bob@example.com>")
+				runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Driving again\n\nCo-authored-by: Bob Jones <bob@example.com>")
+			},
+			args: []string{"--report", "attribution", "--transpose", "--window", "1y"},
+			wantContains: []string{
+				"Attribution Matrix (columns authored with rows as co-author):",
+				"\nAS      -       0       \nBJ      2       -       \n",
+			},
+			wantExitCode: 0,
+		},
+		{
+			name: "unknown subcommand",
+			setupRepo: func(t *testing.T, repoDir string) {
+				// No repo setup needed for an unknown command
+			},
+			args:         []string{"matrx"},
+			wantContains: []string{"unknown command: matrx"},
+			wantExitCode: 2,
+		},
 
 	}
 
@@ -122,6 +658,116 @@ func TestPairStairAcceptance(t *testing.T) {
 	}
 }
 
+// TestProgressGoesToStderr checks -progress reports on stderr and leaves stdout
+// to the output
+func TestProgressGoesToStderr(t *testing.T) {
+	binaryPath := buildPairStairBinary(t)
+	defer os.Remove(binaryPath)
+
+	repoDir := t.TempDir()
+	setupBasicPairingRepo(t, repoDir)
+
+	cmd := exec.Command(binaryPath, "--progress", "--output", "json", "--window", "1y")
+	cmd.Dir = repoDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("pairstair failed: %v\n%s", err, stderr.String())
+	}
+
+	for _, want := range []string{"Fetching commits…\n", "Parsed 4 commits\n", "Building matrix…\n"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr should contain %q, but got:\n%s", want, stderr.String())
+		}
+	}
+	if !json.Valid(stdout.Bytes()) {
+		t.Errorf("stdout should be nothing but the JSON output, but got:\n%s", stdout.String())
+	}
+}
+
+func TestRunLogRecordsFailedRuns(t *testing.T) {
+	binaryPath := buildPairStairBinary(t)
+	defer os.Remove(binaryPath)
+
+	repoDir := t.TempDir()
+	runGitCommand(t, repoDir, "init")
+	runGitCommand(t, repoDir, "config", "user.name", "Alice Smith")
+	runGitCommand(t, repoDir, "config", "user.email", "alice@example.com")
+	runGitCommand(t, repoDir, "commit", "--allow-empty", "-m", "Working alone")
+	logFile := filepath.Join(t.TempDir(), "pairstair.log")
+
+	output, exitCode := runPairStair(t, binaryPath, repoDir, []string{"--fail-on-empty", "--window", "1y", "--log-file", logFile})
+	if exitCode != 4 {
+		t.Fatalf("Expected the empty gate to fail with exit code 4, got %d:\n%s", exitCode, output)
+	}
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Expected the failed run to be logged: %v", err)
+	}
+	if strings.Count(string(content), "\n") != 1 {
+		t.Errorf("Expected one record for the run, got:\n%s", content)
+	}
+	for _, want := range []string{`"level":"ERROR"`, `"status":4`, `"error":"No pairing found in the last year"`, `"developers":1`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected the log to contain %s, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	binaryPath := buildPairStairBinary(t)
+	defer os.Remove(binaryPath)
+
+	repoDir := t.TempDir()
+	setupRepoWithTeamFile(t, repoDir)
+	hook := filepath.Join(repoDir, ".git", "hooks", "prepare-commit-msg")
+
+	output, exitCode := runPairStair(t, binaryPath, repoDir, []string{"install-hook", "-yes"})
+	if exitCode != 0 {
+		t.Fatalf("install-hook failed with exit code %d:\n%s", exitCode, output)
+	}
+	info, err := os.Stat(hook)
+	if err != nil {
+		t.Fatalf("Expected the hook to be created: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Errorf("Expected the hook to be executable, got mode %v", info.Mode())
+	}
+	content, _ := os.ReadFile(hook)
+	for _, want := range []string{"# Co-authored-by: Alice Smith <alice@example.com>", "# Co-authored-by: Carol Davis <carol@example.com>"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected the hook to offer %q from .team, got:\n%s", want, content)
+		}
+	}
+
+	// An existing hook is only replaced with -force
+	writeFile(t, repoDir, ".git/hooks/prepare-commit-msg", "#!/bin/sh\necho mine\n")
+	output, exitCode = runPairStair(t, binaryPath, repoDir, []string{"install-hook", "-yes"})
+	if exitCode == 0 || !strings.Contains(output, "use -force to overwrite it") {
+		t.Errorf("Expected install-hook to refuse to overwrite the hook, got exit code %d:\n%s", exitCode, output)
+	}
+	if content, _ := os.ReadFile(hook); string(content) != "#!/bin/sh\necho mine\n" {
+		t.Errorf("Expected the existing hook to be kept, got:\n%s", content)
+	}
+	if output, exitCode = runPairStair(t, binaryPath, repoDir, []string{"install-hook", "-yes", "-force"}); exitCode != 0 {
+		t.Errorf("Expected -force to overwrite the hook, got exit code %d:\n%s", exitCode, output)
+	}
+
+	// Without -yes it asks first, and anything but yes leaves the hook alone
+	os.Remove(hook)
+	cmd := exec.Command(binaryPath, "install-hook")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("n\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "Hook not installed") {
+		t.Errorf("Expected install-hook to ask before writing, got %v:\n%s", err, out)
+	}
+	if _, err := os.Stat(hook); err == nil {
+		t.Error("Expected no hook after declining")
+	}
+}
+
 // buildPairStairBinary builds the pairstair binary and returns its path
 func buildPairStairBinary(t *testing.T) string {
 	t.Helper()
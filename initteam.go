@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/github"
+)
+
+// runInitTeam implements the init-team subcommand, which writes a draft .team
+// file listing the members of a GitHub organization
+func runInitTeam(args []string) error {
+	flags := flag.NewFlagSet("init-team", flag.ExitOnError)
+	org := flags.String("github-org", "", "GitHub organization whose members should be listed (required)")
+	path := flags.String("file", ".team", "Path of the team file to write")
+	force := flags.Bool("force", false, "Overwrite the team file if it already exists")
+	flags.Parse(args)
+
+	if *org == "" {
+		return fmt.Errorf("-github-org is required")
+	}
+	if _, err := os.Stat(*path); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite it)", *path)
+	}
+
+	members, err := github.FetchOrgMembers(*org, os.Getenv("GITHUB_TOKEN"))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeDraftTeam(file, *org, members); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d members of %s to %s; review it before use\n", len(members), *org, *path)
+	return nil
+}
+
+// writeDraftTeam writes a .team file for the organization members
+func writeDraftTeam(w io.Writer, org string, members []github.Member) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Draft generated from the members of the %s GitHub organization.\n", org)
+	b.WriteString("# Remove anyone who isn't on the team, and add any other emails they commit with.\n")
+	for _, member := range members {
+		b.WriteString(member.TeamEntry() + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
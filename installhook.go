@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+// hookName is the git hook install-hook writes. prepare-commit-msg runs before the
+// editor opens, so the template is there to uncomment.
+const hookName = "prepare-commit-msg"
+
+// runInstallHook implements the install-hook subcommand, which writes a git hook
+// adding a commented-out Co-authored-by trailer for each developer in the .team
+// file to new commit messages
+func runInstallHook(args []string, stdin io.Reader) error {
+	flags := flag.NewFlagSet("install-hook", flag.ExitOnError)
+	repo := flags.String("repo", "", "Path of the repository to install the hook in (default: the current directory)")
+	force := flags.Bool("force", false, "Overwrite the hook if it already exists")
+	yes := flags.Bool("yes", false, "Install the hook without asking for confirmation")
+	flags.Parse(args)
+
+	wd, err := repoDir(*repo)
+	if err != nil {
+		return err
+	}
+	hooks, err := git.HooksDir(wd)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(hooks, hookName)
+	if _, err := os.Stat(path); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite it)", path)
+	}
+
+	var developers []git.Developer
+	teamObj, err := team.NewTeamFromFiles(teamFiles(wd), "")
+	if err == nil {
+		developers = teamObj.GetDevelopers()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading .team file: %w", err)
+	}
+
+	if !*yes && !confirm(stdin, fmt.Sprintf("Write a %s hook listing %d developers to %s? [y/N] ", hookName, len(developers), path)) {
+		fmt.Fprintln(os.Stderr, "Hook not installed")
+		return nil
+	}
+
+	if err := os.MkdirAll(hooks, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(coAuthorHook(developers)), 0o755); err != nil {
+		return err
+	}
+	// WriteFile leaves the permissions of an existing file alone
+	if err := os.Chmod(path, 0o755); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+	return nil
+}
+
+// confirm asks the question on stderr, and reports whether the answer read from r
+// is yes
+func confirm(r io.Reader, question string) bool {
+	fmt.Fprint(os.Stderr, question)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// coAuthorHook returns the prepare-commit-msg hook script. It appends a
+// commented-out Co-authored-by trailer for each developer to the message of a new
+// commit, for the committer to uncomment those they paired with; git drops the
+// rest as comments. Messages given with -m, merges, amends and messages that
+// already have trailers are left alone. Without developers there's one example
+// trailer to fill in.
+func coAuthorHook(developers []git.Developer) string {
+	var b strings.Builder
+	b.WriteString(`#!/bin/sh
+# Installed by pairstair install-hook: reminds you to credit who you paired with.
+
+# $2 is empty for a new commit in the editor, or "template" with commit.template set
+case "$2" in
+  ""|template) ;;
+  *) exit 0 ;;
+esac
+grep -q '^Co-authored-by:' "$1" && exit 0
+
+cat >> "$1" <<'TRAILERS'
+
+# Paired? Uncomment everyone you paired with:
+`)
+	if len(developers) == 0 {
+		b.WriteString("# Co-authored-by: Name <email@example.com>\n")
+	}
+	for _, dev := range developers {
+		fmt.Fprintf(&b, "# Co-authored-by: %s <%s>\n", dev.DisplayName, dev.CanonicalEmail())
+	}
+	b.WriteString("TRAILERS\n")
+	return b.String()
+}
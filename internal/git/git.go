@@ -8,11 +8,16 @@ package git
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Developer represents a developer extracted from git commits.
@@ -39,60 +44,423 @@ func NewDeveloper(entry string) Developer {
 
 // Commit represents a git commit with author and co-author information
 type Commit struct {
+	Hash      string
 	Date      time.Time
 	Author    Developer
 	CoAuthors []Developer
+	// Reviewers are named by Reviewed-by trailers. Reviewing isn't pairing, so
+	// they're kept apart from the co-authors.
+	Reviewers []Developer
 }
 
+// LogOptions controls which commits are read from the git log
+type LogOptions struct {
+	Since   string // Value passed to git log's --since
+	Until   string // Value passed to git log's --until, which compares committer dates
+	AllRefs bool   // Read commits reachable from all refs, not just HEAD
+	Notes   string // Notes ref to also read Co-authored-by trailers from, e.g. "commits"
+	Range   string // Revision range such as "v1.0..v1.1", read instead of everything since Since
+	Dir     string // Directory of the repository to read, if not the current directory
+	Limit   int    // Read at most this many of the most recent commits, whatever their date, if not zero
+	// Progress, if set, is called with the number of commits read so far as each
+	// one is parsed, while git log is still running
+	Progress func(commits int)
+	// DateBasis is which of a commit's dates is its Date. The zero value means AuthorDate.
+	DateBasis DateBasis
+	// After drops commits whose Date is before it, if it isn't zero. git log's
+	// --since only ever compares committer dates, so this is what keeps a window
+	// to commits authored in it.
+	After time.Time
+	// Before drops commits whose Date isn't before it, if it isn't zero
+	Before time.Time
+	// FirstParent only follows the first parent of merge commits, so commits made
+	// on a branch that was merged in are skipped and only the merges are read
+	FirstParent bool
+	// NoMailmap reads names and emails as they were committed, rather than mapped
+	// through the repository's .mailmap as git log shows them
+	NoMailmap bool
+}
+
+// DateBasis chooses which of a commit's dates is used for its Date
+type DateBasis string
+
+const (
+	// AuthorDate is when the change was first written, which a rebase or cherry-pick keeps
+	AuthorDate DateBasis = "author"
+	// CommitterDate is when the commit was last made, such as when it was rebased
+	// or cherry-picked onto the branch
+	CommitterDate DateBasis = "committer"
+)
+
+// ParseDateBasis converts a date basis name to a DateBasis. An empty name means AuthorDate.
+func ParseDateBasis(name string) (DateBasis, error) {
+	switch basis := DateBasis(name); basis {
+	case AuthorDate, CommitterDate:
+		return basis, nil
+	case "":
+		return AuthorDate, nil
+	default:
+		return "", fmt.Errorf("unknown date basis %q: use 'author' or 'committer'", name)
+	}
+}
+
+// ErrNotARepo is returned when commits are read from a directory that isn't in a git repository
+var ErrNotARepo = errors.New("not a git repository")
+
+// ErrGitNotFound is returned when the git executable isn't installed, or isn't on the PATH
+var ErrGitNotFound = errors.New("git was not found on the PATH")
+
 // GetCommitsSince retrieves git commits from the current repository within the specified time window
 func GetCommitsSince(window string) ([]Commit, error) {
 	if err := ValidateWindow(window); err != nil {
 		return nil, err
 	}
 	
-	sinceArg := WindowToGitSince(window)
-	cmd := exec.Command("git", "log", "--since="+sinceArg, "--pretty=format:%H%n%an <%ae>%n%ad%n%B%n==END==", "--date=iso")
-	out, err := cmd.Output()
+	return GetCommits(LogOptions{Since: WindowToGitSince(window)})
+}
+
+// GetCommits retrieves git commits from the current repository using the given options
+func GetCommits(opts LogOptions) ([]Commit, error) {
+	cmd := gitCommand(opts.Dir, BuildLogArgs(opts)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, ErrGitNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	
-	return ParseGitLogOutput(string(out)), nil
+	// Commits are parsed as git writes them, so progress can be shown
+	commits, parseErr := parseGitLog(stdout, opts.Notes != "", opts.Progress)
+	// Read whatever is left after a parse error so git isn't left blocked writing
+	_, _ = io.Copy(io.Discard, stdout)
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && strings.Contains(stderr.String(), "not a git repository") {
+		return nil, ErrNotARepo
+	}
+	if errors.As(err, &exitErr) && stderr.Len() > 0 {
+		// Pass on git's explanation, such as a revision that doesn't exist
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	if !opts.After.IsZero() {
+		commits = slices.DeleteFunc(commits, func(c Commit) bool { return c.Date.Before(opts.After) })
+	}
+	if !opts.Before.IsZero() {
+		commits = slices.DeleteFunc(commits, func(c Commit) bool { return !c.Date.Before(opts.Before) })
+	}
+	if !opts.NoMailmap && parseErr == nil {
+		if err := mapTrailers(opts.Dir, commits); err != nil {
+			return nil, err
+		}
+	}
+	return commits, parseErr
+}
+
+// mapTrailers maps the co-authors and reviewers of the commits through the .mailmap
+// of the repository in dir. git log maps authors itself, but not the people named
+// in trailers.
+func mapTrailers(dir string, commits []Commit) error {
+	var idents []string
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		for _, d := range slices.Concat(c.CoAuthors, c.Reviewers) {
+			if ident := mailmapIdent(d); !seen[ident] {
+				seen[ident] = true
+				idents = append(idents, ident)
+			}
+		}
+	}
+	if len(idents) == 0 {
+		return nil
+	}
+
+	cmd := gitCommand(dir, "check-mailmap", "--stdin")
+	cmd.Stdin = strings.NewReader(strings.Join(idents, "\n") + "\n")
+	out, err := cmd.CombinedOutput()
+	if err := gitError("git check-mailmap", err, out); err != nil {
+		return err
+	}
+	mapped := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(mapped) != len(idents) {
+		return fmt.Errorf("git check-mailmap: expected %d identities, got %d", len(idents), len(mapped))
+	}
+	mailmap := make(map[string]Developer, len(idents))
+	for i, ident := range idents {
+		mailmap[ident] = newDeveloper(mapped[i])
+	}
+
+	for i := range commits {
+		for j, d := range commits[i].CoAuthors {
+			commits[i].CoAuthors[j] = mailmap[mailmapIdent(d)]
+		}
+		for j, d := range commits[i].Reviewers {
+			commits[i].Reviewers[j] = mailmap[mailmapIdent(d)]
+		}
+	}
+	return nil
+}
+
+// mailmapIdent is how git check-mailmap reads a developer, e.g. "Alice <alice@example.com>"
+func mailmapIdent(d Developer) string {
+	return fmt.Sprintf("%s <%s>", d.DisplayName, d.CanonicalEmail())
+}
+
+// AddNote attaches the note to HEAD of the repository in dir, under the given notes
+// ref, replacing any note already there
+func AddNote(dir, ref, note string) error {
+	cmd := gitCommand(dir, "notes", "--ref="+ref, "add", "--force", "--file=-", "HEAD")
+	cmd.Stdin = strings.NewReader(note)
+	out, err := cmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrGitNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("git notes add: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// HooksDir returns the absolute path of the directory git runs hooks from for the
+// repository in dir, usually .git/hooks, following core.hooksPath if it's set
+func HooksDir(dir string) (string, error) {
+	return revParse(dir, "--path-format=absolute", "--git-path", "hooks")
+}
+
+// CommonDir returns the absolute path of the git directory shared by all the
+// working trees of the repository in dir. In a linked worktree (see git worktree)
+// that's the main repository's .git, not the worktree's own git directory.
+func CommonDir(dir string) (string, error) {
+	return revParse(dir, "--path-format=absolute", "--git-common-dir")
+}
+
+// TopLevel returns the absolute path of the root of the working tree dir is in,
+// which is the worktree's own root in a linked worktree, and the submodule's in a
+// submodule. A bare repository has no working tree, so it's an error.
+func TopLevel(dir string) (string, error) {
+	return revParse(dir, "--show-toplevel")
+}
+
+// Submodules returns the absolute paths of the checked out submodules of the
+// repository in dir, and of their submodules in turn
+func Submodules(dir string) ([]string, error) {
+	cmd := gitCommand(dir, "submodule", "foreach", "--quiet", "--recursive", "pwd")
+	out, err := cmd.CombinedOutput()
+	if err := gitError("git submodule", err, out); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(string(out), "\n") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// revParse runs git rev-parse with the arguments for the repository in dir,
+// returning its output
+func revParse(dir string, args ...string) (string, error) {
+	cmd := gitCommand(dir, append([]string{"rev-parse"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err := gitError("git rev-parse", err, out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitError converts the error from running a git command into ErrGitNotFound or
+// ErrNotARepo where it's one of those, or an error including git's output
+func gitError(command string, err error, out []byte) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrGitNotFound
+	}
+	if err != nil && strings.Contains(string(out), "not a git repository") {
+		return ErrNotARepo
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// gitCommand returns a git command run against the repository in dir, or the
+// current directory if dir is empty. A bare repository, or the .git directory of
+// a working tree, has no working tree to run in, so it's passed with --git-dir.
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	if gitDir, ok := bareGitDir(dir); ok {
+		return exec.Command("git", append([]string{"--git-dir=" + gitDir}, args...)...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// bareGitDir returns the absolute path of the git directory when dir is inside
+// one, as it is in a bare repository, rather than in a working tree
+func bareGitDir(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-git-dir", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", false
+	}
+	inside, gitDir, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return gitDir, inside == "true"
+}
+
+// BuildLogArgs returns the arguments for the git log invocation described by the options
+func BuildLogArgs(opts LogOptions) []string {
+	args := []string{"log"}
+	if opts.AllRefs {
+		args = append(args, "--all")
+	}
+	if opts.FirstParent {
+		args = append(args, "--first-parent")
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Until != "" {
+		args = append(args, "--until="+opts.Until)
+	}
+	if opts.Limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", opts.Limit))
+	}
+	date := "%ad"
+	if opts.DateBasis == CommitterDate {
+		date = "%cd"
+	}
+	// %aN and %aE are the author as mapped by .mailmap
+	author := "%aN <%aE>"
+	if opts.NoMailmap {
+		args = append(args, "--no-use-mailmap")
+		author = "%an <%ae>"
+	} else {
+		args = append(args, "--use-mailmap")
+	}
+	if opts.Notes != "" {
+		args = append(args, "--notes="+opts.Notes, "--pretty=format:%H%n"+author+"%n"+date+"%n%B%n"+notesMarker+"%n%N%n==END==", "--date=iso-strict")
+	} else {
+		args = append(args, "--pretty=format:%H%n"+author+"%n"+date+"%n%B%n==END==", "--date=iso-strict")
+	}
+	if opts.Range != "" {
+		// The range goes after the options, between --end-of-options and --, so git
+		// reads it as nothing but a revision range
+		args = append(args, "--end-of-options", opts.Range, "--")
+	}
+	return args
+}
+
+// ValidateRange checks that a revision range looks like "A..B" or "A...B". Only
+// the shape is checked: git reports revisions that don't exist.
+func ValidateRange(revisionRange string) error {
+	from, to, ok := strings.Cut(revisionRange, "..")
+	to = strings.TrimPrefix(to, ".")
+	if !ok || from == "" && to == "" || strings.HasPrefix(revisionRange, "-") || strings.ContainsAny(revisionRange, " \t\n") {
+		return fmt.Errorf("invalid range: %s (expected a revision range such as 'v1.0..v1.1')", revisionRange)
+	}
+	return nil
+}
+
+// notesMarker separates a commit's message from its notes in the git log output
+const notesMarker = "==NOTES=="
+
+// dateLayouts are the commit date formats ParseGitLogOutput understands, in the
+// order they are tried. We ask git for --date=iso-strict (RFC 3339), but
+// accept the other common formats too in case the date format is overridden
+// (for example by log.date in the user's git config).
+var dateLayouts = []string{
+	time.RFC3339,                     // --date=iso-strict
+	"2006-01-02 15:04:05 -0700",      // --date=iso
+	"Mon, 2 Jan 2006 15:04:05 -0700", // --date=rfc
+	"Mon Jan 2 15:04:05 2006 -0700",  // --date=default
+}
+
+// parseDate parses a commit date in any of the accepted layouts
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized commit date %q: %w", value, err)
 }
 
 // ParseGitLogOutput parses the output from git log command and returns commits
 // This function is exported to allow testing with mock data
 func ParseGitLogOutput(output string) []Commit {
-	scanner := bufio.NewScanner(bytes.NewReader([]byte(output)))
+	commits, _ := parseGitLog(strings.NewReader(output), false, nil)
+	return commits
+}
+
+// ParseGitLogOutputWithNotes parses the output from a git log command that was
+// asked for notes, as with LogOptions.Notes, and returns commits
+func ParseGitLogOutputWithNotes(output string) []Commit {
+	commits, _ := parseGitLog(strings.NewReader(output), true, nil)
+	return commits
+}
+
+// parseGitLog parses git log output as it's read, calling progress, if it isn't
+// nil, with the number of commits parsed so far after each one. The notes marker
+// is only looked for when notes were asked for, so a message that happens to
+// contain it isn't cut short.
+func parseGitLog(r io.Reader, notes bool, progress func(commits int)) ([]Commit, error) {
+	scanner := bufio.NewScanner(r)
 	var commits []Commit
 	var c Commit
-	var bodyLines []string
+	var bodyLines, notesLines []string
+	inNotes := false
 	lineNum := 0
 	
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line == "==END==" {
 			c.CoAuthors = ParseCoAuthors(strings.Join(bodyLines, "\n"))
+			c.CoAuthors = appendNewCoAuthors(c.CoAuthors, ParseCoAuthors(strings.Join(notesLines, "\n")))
+			c.Reviewers = ParseReviewers(strings.Join(bodyLines, "\n"))
+			c.Reviewers = appendNewCoAuthors(c.Reviewers, ParseReviewers(strings.Join(notesLines, "\n")))
 			commits = append(commits, c)
+			if progress != nil {
+				progress(len(commits))
+			}
 			c = Commit{}
-			bodyLines = nil
+			bodyLines, notesLines = nil, nil
+			inNotes = false
 			lineNum = 0
 			continue
 		}
+		if notes && lineNum > 2 && line == notesMarker {
+			inNotes = true
+			continue
+		}
+		if inNotes {
+			notesLines = append(notesLines, line)
+			continue
+		}
 		
 		switch lineNum {
 		case 0:
-			// commit hash, skip
+			c.Hash = line
 		case 1:
 			c.Author = newDeveloper(line)
 		case 2:
-			t, err := time.Parse("2006-01-02 15:04:05 -0700", line)
+			t, err := parseDate(line)
 			if err != nil {
-				// Try alternative format if first parse fails
-				t, err = time.Parse("2006-01-02 15:04:05 -0700", line)
-				if err != nil {
-					break
-				}
+				break
 			}
 			c.Date = t
 		default:
@@ -101,15 +469,36 @@ func ParseGitLogOutput(output string) []Commit {
 		lineNum++
 	}
 	
-	return commits
+	return commits, scanner.Err()
+}
+
+// appendNewCoAuthors adds the co-authors from notes that aren't already listed in the message
+func appendNewCoAuthors(coAuthors, fromNotes []Developer) []Developer {
+	for _, d := range fromNotes {
+		if !slices.ContainsFunc(coAuthors, func(existing Developer) bool { return existing.CanonicalEmail() == d.CanonicalEmail() }) {
+			coAuthors = append(coAuthors, d)
+		}
+	}
+	return coAuthors
 }
 
 // ParseCoAuthors extracts co-author information from a commit message body
 func ParseCoAuthors(body string) []Developer {
+	return parseTrailers(body, "Co-authored-by")
+}
+
+// ParseReviewers extracts the developers named by Reviewed-by trailers from a
+// commit message body
+func ParseReviewers(body string) []Developer {
+	return parseTrailers(body, "Reviewed-by")
+}
+
+// parseTrailers extracts the developers named by the trailer from a commit message body
+func parseTrailers(body string, trailer string) []Developer {
 	var coAuthors []Developer
-	coAuthorRe := regexp.MustCompile(`Co-authored-by:\s*(.+?)\s*<(.+?)>`)
+	coAuthorRe := regexp.MustCompile(regexp.QuoteMeta(trailer) + `:\s*(.+?)\s*<(.+?)>`)
 	
-	for _, line := range strings.Split(body, "\n") {
+	for _, line := range unfoldLines(body) {
 		matches := coAuthorRe.FindStringSubmatch(line)
 		if matches != nil && len(matches) >= 3 {
 			authorString := fmt.Sprintf("%s <%s>", matches[1], matches[2])
@@ -120,8 +509,113 @@ func ParseCoAuthors(body string) []Developer {
 	return coAuthors
 }
 
-// WindowToGitSince converts a time window string (e.g., "2w", "1m") to git's --since format
+// unfoldLines splits a commit message into lines, joining any line that starts with
+// whitespace onto the line before, as git does for a trailer wrapped onto more than
+// one line
+func unfoldLines(body string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if len(lines) > 0 && strings.TrimSpace(line) != "" && strings.TrimLeft(line, " \t") != line {
+			lines[len(lines)-1] = strings.TrimRight(lines[len(lines)-1], " \t") + " " + strings.TrimSpace(line)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// DefaultPlaceholderCoAuthors are the names and emails left behind by PR and commit
+// templates when nobody fills in the Co-authored-by trailer
+var DefaultPlaceholderCoAuthors = []string{
+	"name@example.com",
+	"your-email@example.com",
+	"your.email@example.com",
+	"you@example.com",
+	"email@example.com",
+	"user@example.com",
+	"Your Name",
+	"Full Name",
+}
+
+// RemoveCoAuthors returns the commits without any co-authors whose name or email
+// matches one of the ignored values, ignoring case
+func RemoveCoAuthors(commits []Commit, ignore []string) []Commit {
+	if len(ignore) == 0 {
+		return commits
+	}
+
+	ignored := make(map[string]bool)
+	for _, value := range ignore {
+		ignored[strings.ToLower(strings.TrimSpace(value))] = true
+	}
+
+	filtered := make([]Commit, len(commits))
+	for i, c := range commits {
+		filtered[i] = Commit{Hash: c.Hash, Date: c.Date, Author: c.Author, Reviewers: c.Reviewers}
+		for _, coAuthor := range c.CoAuthors {
+			if ignored[strings.ToLower(coAuthor.DisplayName)] || ignored[coAuthor.CanonicalEmail()] {
+				continue
+			}
+			filtered[i].CoAuthors = append(filtered[i].CoAuthors, coAuthor)
+		}
+	}
+	return filtered
+}
+
+// ValidEmail reports whether an email looks like an email address: something
+// before and after an "@", with no spaces or brackets and a domain without empty
+// labels. It is deliberately lenient, so unusual but valid addresses such as
+// "dev+tag@localhost" pass, and only catches clear mistakes like "not an email".
+func ValidEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return false
+	}
+	if strings.ContainsFunc(email, func(r rune) bool { return unicode.IsSpace(r) || strings.ContainsRune("<>,;", r) }) {
+		return false
+	}
+	return !slices.Contains(strings.Split(email[at+1:], "."), "")
+}
+
+// InvalidCoAuthorEmails returns the co-author emails in the commits that fail
+// ValidEmail, sorted and without duplicates
+func InvalidCoAuthorEmails(commits []Commit) []string {
+	var invalid []string
+	for _, c := range commits {
+		for _, coAuthor := range c.CoAuthors {
+			if email := coAuthor.CanonicalEmail(); !ValidEmail(email) {
+				invalid = append(invalid, email)
+			}
+		}
+	}
+	slices.Sort(invalid)
+	return slices.Compact(invalid)
+}
+
+// RemoveInvalidCoAuthors returns the commits without any co-authors whose email
+// fails ValidEmail
+func RemoveInvalidCoAuthors(commits []Commit) []Commit {
+	filtered := make([]Commit, len(commits))
+	for i, c := range commits {
+		filtered[i] = Commit{Hash: c.Hash, Date: c.Date, Author: c.Author, Reviewers: c.Reviewers}
+		for _, coAuthor := range c.CoAuthors {
+			if ValidEmail(coAuthor.CanonicalEmail()) {
+				filtered[i].CoAuthors = append(filtered[i].CoAuthors, coAuthor)
+			}
+		}
+	}
+	return filtered
+}
+
+// AllTime is the window covering a repository's whole history
+const AllTime = "all"
+
+// WindowToGitSince converts a time window string (e.g., "2w", "1m") to git's --since format.
+// AllTime has no --since, so it converts to "".
 func WindowToGitSince(window string) string {
+	if window == AllTime {
+		return ""
+	}
 	unitMap := map[byte]string{
 		'd': "day",
 		'w': "week",
@@ -143,8 +637,35 @@ func WindowToGitSince(window string) string {
 	return window
 }
 
-// ValidateWindow checks if a time window string is in valid format (e.g., "2w", "1m", "7d")
+// WindowStart returns the time a window (e.g. "2w", "1m") reaching back from now starts.
+// AllTime has no start, so it starts at the zero time.
+func WindowStart(window string, now time.Time) (time.Time, error) {
+	if err := ValidateWindow(window); err != nil {
+		return time.Time{}, err
+	}
+	if window == AllTime {
+		return time.Time{}, nil
+	}
+
+	n, _ := strconv.Atoi(window[:len(window)-1])
+	switch window[len(window)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	default: // 'y'
+		return now.AddDate(-n, 0, 0), nil
+	}
+}
+
+// ValidateWindow checks if a time window string is in valid format (e.g., "2w", "1m", "7d"),
+// or is AllTime
 func ValidateWindow(window string) error {
+	if window == AllTime {
+		return nil
+	}
 	validWindow := regexp.MustCompile(`^\d+[dwmy]$`)
 	if !validWindow.MatchString(window) {
 		return fmt.Errorf("invalid window format: %s", window)
@@ -152,6 +673,42 @@ func ValidateWindow(window string) error {
 	return nil
 }
 
+// ValidateDay checks that a date is in YYYY-MM-DD format
+func ValidateDay(day string) error {
+	_, err := ParseDay(day)
+	return err
+}
+
+// ParseDay parses a YYYY-MM-DD date as the start of that day in local time
+func ParseDay(day string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", day, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", day)
+	}
+	return t, nil
+}
+
+// DateRange returns the start of the since date and the end of the until date, so a
+// commit is in the range if it's at or after the one and before the other. An empty
+// date leaves that end open, as the zero time.
+func DateRange(since, until string) (after, before time.Time, err error) {
+	if since != "" {
+		if after, err = ParseDay(since); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if until != "" {
+		if before, err = ParseDay(until); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		before = before.AddDate(0, 0, 1)
+	}
+	if since != "" && until != "" && !after.Before(before) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since %s is after until %s", since, until)
+	}
+	return after, before, nil
+}
+
 // newDeveloper creates a developer from a "Name <email>" string
 // This is internal to the git package
 func newDeveloper(entry string) Developer {
@@ -165,7 +722,7 @@ func newDeveloper(entry string) Developer {
 	return Developer{
 		DisplayName:     name,
 		EmailAddresses:  emails,
-		AbbreviatedName: shortName(name),
+		AbbreviatedName: Initials(name),
 	}
 }
 
@@ -203,23 +760,17 @@ func ExtractAllEmails(author string) []string {
 	return emails
 }
 
-// shortName creates an abbreviated name from a full name
-func shortName(name string) string {
-	// Initials of all the words in a string
+// Initials abbreviates a full name to the upper-cased initials of its words,
+// e.g. "Jane Doe" gives "JD"
+func Initials(name string) string {
 	words := strings.Fields(name)
 	if len(words) == 0 {
 		return "NAN"
 	}
 
-	initials := make([]string, len(words))
-
-	for i, word := range words {
-		if len(word) > 0 {
-			initials[i] = strings.ToUpper(string(word[0]))
-		} else {
-			initials[i] = "."
-		}
+	var initials strings.Builder
+	for _, word := range words {
+		initials.WriteString(strings.ToUpper(string([]rune(word)[0])))
 	}
-
-	return strings.Join(initials, "")
+	return initials.String()
 }
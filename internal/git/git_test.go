@@ -1,8 +1,15 @@
 package git_test
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
 )
@@ -41,6 +48,14 @@ func TestParseCoAuthors(t *testing.T) {
 				git.NewDeveloper("Bob Jones <bob@example.com>"),
 			},
 		},
+		{
+			name:  "co-author folded onto the next line",
+			input: "Some commit message\n\nCo-authored-by: Alice Smith With A Long Name\n  <alice@example.com>\nCo-authored-by:\n\tBob Jones <bob@example.com>",
+			expected: []git.Developer{
+				git.NewDeveloper("Alice Smith With A Long Name <alice@example.com>"),
+				git.NewDeveloper("Bob Jones <bob@example.com>"),
+			},
+		},
 		{
 			name:  "mixed content with co-authors",
 			input: "Fix bug in parser\n\nThis fixes the issue where the parser would fail.\n\nCo-authored-by: Alice Smith <alice@example.com>\nSome other text\nCo-authored-by: Bob Jones <bob@example.com>",
@@ -80,6 +95,93 @@ func TestParseCoAuthors(t *testing.T) {
 	}
 }
 
+func TestRemoveCoAuthors(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00-08:00
+Add new feature
+
+Co-authored-by: Bob Jones <bob@example.com>
+Co-authored-by: Name <name@example.com>
+Co-authored-by: Your Name <someone@example.org>
+==END==`
+
+	commits := git.RemoveCoAuthors(git.ParseGitLogOutput(mockGitOutput), git.DefaultPlaceholderCoAuthors)
+
+	if len(commits) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(commits))
+	}
+	coAuthors := commits[0].CoAuthors
+	if len(coAuthors) != 1 || coAuthors[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected only Bob to remain as a co-author, got %v", coAuthors)
+	}
+	if commits[0].Author.CanonicalEmail() != "alice@example.com" {
+		t.Errorf("Expected the author to be kept, got %v", commits[0].Author)
+	}
+
+	unfiltered := git.RemoveCoAuthors(git.ParseGitLogOutput(mockGitOutput), nil)
+	if len(unfiltered[0].CoAuthors) != 3 {
+		t.Errorf("Expected all 3 co-authors with no ignore list, got %d", len(unfiltered[0].CoAuthors))
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{"alice@example.com", true},
+		{"dev+tag@localhost", true},
+		{"first.last@mail.example.co.uk", true},
+		{"12345+octocat@users.noreply.github.com", true},
+		{"not an email", false},
+		{"alice", false},
+		{"@example.com", false},
+		{"alice@", false},
+		{"alice@example..com", false},
+		{"alice@example.com.", false},
+		{"alice smith@example.com", false},
+		{"alice@example.com, bob@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := git.ValidEmail(tt.email); got != tt.valid {
+				t.Errorf("ValidEmail(%q) = %v, want %v", tt.email, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestInvalidCoAuthors(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00-08:00
+Add new feature
+
+Co-authored-by: Bob Jones <bob@example.com>
+Co-authored-by: Carol <not an email>
+Co-authored-by: Dan <dan@>
+Co-authored-by: Carol <Not An Email>
+==END==`
+
+	commits := git.ParseGitLogOutput(mockGitOutput)
+
+	invalid := git.InvalidCoAuthorEmails(commits)
+	if want := []string{"dan@", "not an email"}; !slices.Equal(invalid, want) {
+		t.Errorf("Expected invalid emails %v, got %v", want, invalid)
+	}
+
+	filtered := git.RemoveInvalidCoAuthors(commits)
+	coAuthors := filtered[0].CoAuthors
+	if len(coAuthors) != 1 || coAuthors[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected only Bob to remain as a co-author, got %v", coAuthors)
+	}
+	if len(commits[0].CoAuthors) != 4 {
+		t.Errorf("Expected the original commits to keep all 4 co-authors, got %d", len(commits[0].CoAuthors))
+	}
+}
+
 func TestWindowToGitSince(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -116,6 +218,11 @@ func TestWindowToGitSince(t *testing.T) {
 			window:   "30d",
 			expected: "30.days",
 		},
+		{
+			name:     "all time has no since",
+			window:   "all",
+			expected: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -128,6 +235,62 @@ func TestWindowToGitSince(t *testing.T) {
 	}
 }
 
+func TestWindowStart(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		window   string
+		expected time.Time
+	}{
+		{"3d", time.Date(2024, 6, 9, 15, 0, 0, 0, time.UTC)},
+		{"2w", time.Date(2024, 5, 29, 15, 0, 0, 0, time.UTC)},
+		{"1m", time.Date(2024, 5, 12, 15, 0, 0, 0, time.UTC)},
+		{"1y", time.Date(2023, 6, 12, 15, 0, 0, 0, time.UTC)},
+		{"all", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		start, err := git.WindowStart(tt.window, now)
+		if err != nil {
+			t.Errorf("WindowStart(%q) unexpected error: %v", tt.window, err)
+			continue
+		}
+		if !start.Equal(tt.expected) {
+			t.Errorf("WindowStart(%q): expected %v, got %v", tt.window, tt.expected, start)
+		}
+	}
+
+	if _, err := git.WindowStart("2x", now); err == nil {
+		t.Error("Expected error for invalid window")
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	after, before, err := git.DateRange("2024-01-01", "2024-03-31")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local); !after.Equal(want) {
+		t.Errorf("Expected the range to start at %v, got %v", want, after)
+	}
+	if want := time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local); !before.Equal(want) {
+		t.Errorf("Expected the range to include the whole of the until date, ending at %v, got %v", want, before)
+	}
+
+	if after, before, err := git.DateRange("", "2024-03-31"); err != nil || !after.IsZero() || before.IsZero() {
+		t.Errorf("Expected an open start, got %v, %v, %v", after, before, err)
+	}
+	if _, _, err := git.DateRange("2024-03-31", "2024-03-31"); err != nil {
+		t.Errorf("Expected a single day to be a valid range, got %v", err)
+	}
+	if _, _, err := git.DateRange("2024-03-31", "2024-01-01"); err == nil || !strings.Contains(err.Error(), "since 2024-03-31 is after until 2024-01-01") {
+		t.Errorf("Expected an error for since after until, got %v", err)
+	}
+	if _, _, err := git.DateRange("01/01/2024", ""); err == nil || !strings.Contains(err.Error(), "use YYYY-MM-DD") {
+		t.Errorf("Expected an error for a date that isn't YYYY-MM-DD, got %v", err)
+	}
+}
+
 func TestValidateWindow(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -154,6 +317,16 @@ func TestValidateWindow(t *testing.T) {
 			window:  "1y",
 			wantErr: false,
 		},
+		{
+			name:    "valid all time",
+			window:  "all",
+			wantErr: false,
+		},
+		{
+			name:    "invalid format - all with a number",
+			window:  "1all",
+			wantErr: true,
+		},
 		{
 			name:    "invalid format - no number",
 			window:  "d",
@@ -282,3 +455,673 @@ Fix bug in parser
 		t.Errorf("Second commit co-authors: got %d, expected 0", len(commit2.CoAuthors))
 	}
 }
+
+func TestParseGitLogOutput_DateLayouts(t *testing.T) {
+	expected := time.Date(2024, 1, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		date string
+	}{
+		{"iso", "2024-01-15 10:30:00 -0800"},
+		{"iso-strict", "2024-01-15T10:30:00-08:00"},
+		{"iso-strict in UTC", "2024-01-15T18:30:00Z"},
+		{"rfc", "Mon, 15 Jan 2024 10:30:00 -0800"},
+		{"default", "Mon Jan 15 10:30:00 2024 -0800"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGitOutput := "abc123\nAlice Smith <alice@example.com>\n" + tt.date + "\nAdd new feature\n==END=="
+
+			result := git.ParseGitLogOutput(mockGitOutput)
+
+			if len(result) != 1 {
+				t.Fatalf("Expected 1 commit, got %d", len(result))
+			}
+			if !result[0].Date.Equal(expected) {
+				t.Errorf("Expected date %v, got %v", expected, result[0].Date)
+			}
+		})
+	}
+}
+
+func TestParseGitLogOutput_NonISODateIsNotLost(t *testing.T) {
+	// git's default date format, as produced when log.date isn't iso. The old
+	// fallback retried the same ISO layout, so this commit ended up with no date.
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+Tue Jan 2 09:05:00 2024 +0100
+Add new feature
+
+Co-authored-by: Bob Jones <bob@example.com>
+==END==`
+
+	result := git.ParseGitLogOutput(mockGitOutput)
+
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(result))
+	}
+	if result[0].Date.IsZero() {
+		t.Fatal("Expected the commit date to be parsed, got the zero time")
+	}
+	expected := time.Date(2024, 1, 2, 8, 5, 0, 0, time.UTC)
+	if !result[0].Date.Equal(expected) {
+		t.Errorf("Expected date %v, got %v", expected, result[0].Date)
+	}
+}
+
+func TestParseGitLogOutput_ReviewersAreNotCoAuthors(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00Z
+Add new feature
+
+Co-authored-by: Bob Jones <bob@example.com>
+Reviewed-by: Carol Davis <carol@example.com>
+==NOTES==
+Reviewed-by: Dave Brown <dave@example.com>
+==END==`
+
+	result := git.ParseGitLogOutputWithNotes(mockGitOutput)
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(result))
+	}
+
+	emails := func(developers []git.Developer) string {
+		var emails []string
+		for _, d := range developers {
+			emails = append(emails, d.CanonicalEmail())
+		}
+		return strings.Join(emails, ",")
+	}
+	if got := emails(result[0].CoAuthors); got != "bob@example.com" {
+		t.Errorf("Expected only Bob as a co-author, got %s", got)
+	}
+	if got := emails(result[0].Reviewers); got != "carol@example.com,dave@example.com" {
+		t.Errorf("Expected Carol and Dave as reviewers, got %s", got)
+	}
+}
+
+func TestParseReviewers(t *testing.T) {
+	reviewers := git.ParseReviewers("Fix bug\n\nCo-authored-by: Bob Jones <bob@example.com>\nReviewed-by: Carol Davis <carol@example.com>")
+	if len(reviewers) != 1 || reviewers[0].CanonicalEmail() != "carol@example.com" {
+		t.Errorf("Expected only Carol as a reviewer, got %v", reviewers)
+	}
+	if coAuthors := git.ParseCoAuthors("Fix bug\n\nReviewed-by: Carol Davis <carol@example.com>"); len(coAuthors) != 0 {
+		t.Errorf("Expected a reviewer not to be a co-author, got %v", coAuthors)
+	}
+}
+
+func TestParseGitLogOutput_NotesMarkerInMessageWithoutNotes(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00Z
+Explain the ==NOTES== marker
+==NOTES==
+Reviewed-by: Carol Davis <carol@example.com>
+
+Co-authored-by: Bob Jones <bob@example.com>
+==END==`
+
+	result := git.ParseGitLogOutput(mockGitOutput)
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(result))
+	}
+	if len(result[0].CoAuthors) != 1 || result[0].CoAuthors[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected Bob as the co-author from the message, got %v", result[0].CoAuthors)
+	}
+	if len(result[0].Reviewers) != 1 || result[0].Reviewers[0].CanonicalEmail() != "carol@example.com" {
+		t.Errorf("Expected Carol as the reviewer from the message, got %v", result[0].Reviewers)
+	}
+}
+
+func TestParseGitLogOutput_CoAuthorsFromNotes(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00Z
+Add new feature
+==NOTES==
+Co-authored-by: Bob Jones <bob@example.com>
+==END==
+def456
+Alice Smith <alice@example.com>
+2024-01-16T10:30:00Z
+Fix bug
+
+Co-authored-by: Carol Davis <carol@example.com>
+==NOTES==
+Co-authored-by: Carol Davis <carol@example.com>
+Co-authored-by: Dave Brown <dave@example.com>
+==END==
+ghi789
+Alice Smith <alice@example.com>
+2024-01-17T10:30:00Z
+No notes on this one
+==NOTES==
+
+==END==`
+
+	result := git.ParseGitLogOutputWithNotes(mockGitOutput)
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(result))
+	}
+
+	tests := []struct {
+		name      string
+		commit    git.Commit
+		coAuthors []string
+	}{
+		{"co-author only in the notes", result[0], []string{"bob@example.com"}},
+		{"co-authors in the message and notes, without duplicates", result[1], []string{"carol@example.com", "dave@example.com"}},
+		{"empty notes", result[2], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var emails []string
+			for _, d := range tt.commit.CoAuthors {
+				emails = append(emails, d.CanonicalEmail())
+			}
+			if strings.Join(emails, ",") != strings.Join(tt.coAuthors, ",") {
+				t.Errorf("Expected co-authors %v, got %v", tt.coAuthors, emails)
+			}
+		})
+	}
+}
+
+func TestBuildLogArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     git.LogOptions
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "since only",
+			opts:     git.LogOptions{Since: "2.weeks"},
+			contains: []string{"log", "--since=2.weeks", "--date=iso-strict"},
+			excludes: []string{"--all"},
+		},
+		{
+			name:     "all refs",
+			opts:     git.LogOptions{Since: "2.weeks", AllRefs: true},
+			contains: []string{"log", "--all", "--since=2.weeks"},
+		},
+		{
+			name:     "notes",
+			opts:     git.LogOptions{Since: "2.weeks", Notes: "pairing"},
+			contains: []string{"--notes=pairing"},
+		},
+		{
+			name:     "first parent",
+			opts:     git.LogOptions{Since: "2.weeks", FirstParent: true},
+			contains: []string{"log", "--first-parent", "--since=2.weeks"},
+		},
+		{
+			name:     "all parents by default",
+			opts:     git.LogOptions{Since: "2.weeks"},
+			excludes: []string{"--first-parent"},
+		},
+		{
+			name:     "until",
+			opts:     git.LogOptions{Since: "2024-01-01", Until: "2024-04-01"},
+			contains: []string{"--since=2024-01-01", "--until=2024-04-01"},
+		},
+		{
+			name:     "mailmap by default",
+			opts:     git.LogOptions{Since: "2.weeks"},
+			contains: []string{"--use-mailmap"},
+		},
+		{
+			name:     "no mailmap",
+			opts:     git.LogOptions{Since: "2.weeks", NoMailmap: true},
+			contains: []string{"--no-use-mailmap"},
+			excludes: []string{"--use-mailmap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := git.BuildLogArgs(tt.opts)
+			for _, want := range tt.contains {
+				if !containsArg(args, want) {
+					t.Errorf("Expected args %v to contain %q", args, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if containsArg(args, unwanted) {
+					t.Errorf("Expected args %v not to contain %q", args, unwanted)
+				}
+			}
+			if args[0] != "log" {
+				t.Errorf("Expected first argument to be the log subcommand, got %q", args[0])
+			}
+		})
+	}
+}
+
+func TestBuildLogArgsForAllTime(t *testing.T) {
+	args := git.BuildLogArgs(git.LogOptions{Since: git.WindowToGitSince(git.AllTime)})
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--since") {
+			t.Errorf("Expected no --since for the whole history, got %v", args)
+		}
+	}
+}
+
+func TestBuildLogArgsWithRange(t *testing.T) {
+	args := git.BuildLogArgs(git.LogOptions{Range: "v1.0..v1.1"})
+
+	want := []string{"--end-of-options", "v1.0..v1.1", "--"}
+	if tail := args[len(args)-3:]; strings.Join(tail, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected args to end with %v, got %v", want, args)
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--since") {
+			t.Errorf("Expected no --since with a range, got %v", args)
+		}
+	}
+	if containsArg(git.BuildLogArgs(git.LogOptions{Since: "2.weeks"}), "--end-of-options") {
+		t.Error("Expected no revisions without a range")
+	}
+}
+
+func TestBuildLogArgsWithLimit(t *testing.T) {
+	args := git.BuildLogArgs(git.LogOptions{Limit: 20})
+	if !containsArg(args, "--max-count=20") {
+		t.Errorf("Expected args %v to contain --max-count=20", args)
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--since") {
+			t.Errorf("Expected no --since with only a limit, got %v", args)
+		}
+	}
+
+	// A limit applies within a range
+	args = git.BuildLogArgs(git.LogOptions{Limit: 5, Range: "v1.0..v1.1"})
+	if !containsArg(args, "--max-count=5") || args[len(args)-2] != "v1.0..v1.1" {
+		t.Errorf("Expected a limit before the range, got %v", args)
+	}
+
+	for _, arg := range git.BuildLogArgs(git.LogOptions{Since: "2.weeks"}) {
+		if strings.HasPrefix(arg, "--max-count") {
+			t.Error("Expected no limit by default")
+		}
+	}
+}
+
+func TestGetCommitsWithLimit(t *testing.T) {
+	dir := t.TempDir()
+	commands := [][]string{
+		{"init", dir},
+		{"-C", dir, "config", "user.name", "Alice"},
+		{"-C", dir, "config", "user.email", "alice@example.com"},
+	}
+	// Five commits, a year apart and long ago, each with a different co-author
+	for i, coAuthor := range []string{"Bob", "Carol", "Dave", "Eve", "Frank"} {
+		date := fmt.Sprintf("%d-06-01T12:00:00Z", 2015+i)
+		commands = append(commands, []string{"-C", dir, "-c", "core.hooksPath=/dev/null", "commit", "--allow-empty", "--date=" + date,
+			"-m", fmt.Sprintf("Commit %d\n\nCo-authored-by: %s <%s@example.com>", i, coAuthor, strings.ToLower(coAuthor))})
+	}
+	for _, args := range commands {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	commits, err := git.GetCommits(git.LogOptions{Limit: 2, Dir: dir})
+	if err != nil {
+		t.Fatalf("GetCommits failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected the 2 most recent commits, got %d", len(commits))
+	}
+	for i, want := range []string{"frank@example.com", "eve@example.com"} {
+		if got := commits[i].CoAuthors[0].CanonicalEmail(); got != want {
+			t.Errorf("Expected commit %d to be with %s, got %s", i, want, got)
+		}
+	}
+
+	// However old they are, they are all there without a window
+	commits, err = git.GetCommits(git.LogOptions{Limit: 10, Dir: dir})
+	if err != nil {
+		t.Fatalf("GetCommits failed: %v", err)
+	}
+	if len(commits) != 5 {
+		t.Errorf("Expected all 5 commits under the limit, got %d", len(commits))
+	}
+}
+
+func TestGetCommitsReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	commands := [][]string{
+		{"init", dir},
+		{"-C", dir, "config", "user.name", "Alice"},
+		{"-C", dir, "config", "user.email", "alice@example.com"},
+	}
+	for i := 0; i < 3; i++ {
+		commands = append(commands, []string{"-C", dir, "-c", "core.hooksPath=/dev/null", "commit", "--allow-empty", "-m", fmt.Sprintf("Commit %d", i)})
+	}
+	for _, args := range commands {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	var reported []int
+	commits, err := git.GetCommits(git.LogOptions{Dir: dir, Progress: func(n int) { reported = append(reported, n) }})
+	if err != nil {
+		t.Fatalf("GetCommits failed: %v", err)
+	}
+	if len(commits) != 3 || fmt.Sprint(reported) != "[1 2 3]" {
+		t.Errorf("Expected progress after each of 3 commits, got %v for %d commits", reported, len(commits))
+	}
+}
+
+func TestGetCommitsDateBasis(t *testing.T) {
+	dir := t.TempDir()
+	authored := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, args := range [][]string{
+		{"init", dir},
+		{"-C", dir, "config", "user.name", "Alice"},
+		{"-C", dir, "config", "user.email", "alice@example.com"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	// A commit written long ago and rebased today
+	cmd := exec.Command("git", "-C", dir, "-c", "core.hooksPath=/dev/null", "commit", "--allow-empty", "-m", "Rebased\n\nCo-authored-by: Bob <bob@example.com>")
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+authored.Format(time.RFC3339))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v: %s", err, out)
+	}
+	weekAgo := time.Now().AddDate(0, 0, -7)
+
+	tests := []struct {
+		basis     git.DateBasis
+		wantDate  func(time.Time) bool
+		inTheWeek bool
+	}{
+		{git.AuthorDate, func(d time.Time) bool { return d.Equal(authored) }, false},
+		{git.CommitterDate, func(d time.Time) bool { return d.After(weekAgo) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.basis), func(t *testing.T) {
+			commits, err := git.GetCommits(git.LogOptions{Dir: dir, DateBasis: tt.basis})
+			if err != nil {
+				t.Fatalf("GetCommits failed: %v", err)
+			}
+			if len(commits) != 1 || !tt.wantDate(commits[0].Date) {
+				t.Fatalf("Expected the %s date, got %v", tt.basis, commits)
+			}
+
+			// git's --since finds the commit by its committer date either way, but only
+			// the committer date puts it in the last week
+			commits, err = git.GetCommits(git.LogOptions{Dir: dir, DateBasis: tt.basis, Since: "1.week", After: weekAgo})
+			if err != nil {
+				t.Fatalf("GetCommits failed: %v", err)
+			}
+			if got := len(commits) == 1; got != tt.inTheWeek {
+				t.Errorf("Expected the commit in the last week to be %v, got %d commits", tt.inTheWeek, len(commits))
+			}
+		})
+	}
+
+	if args := git.BuildLogArgs(git.LogOptions{DateBasis: git.CommitterDate}); !strings.Contains(strings.Join(args, " "), "%cd") {
+		t.Errorf("Expected the committer date to be fetched, got %v", args)
+	}
+	if _, err := git.ParseDateBasis("commit"); err == nil {
+		t.Error("Expected an error for an unknown date basis")
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	for _, valid := range []string{"v1.0..v1.1", "v1.0...v1.1", "main..feature/x", "v1.0..", "..HEAD", "abc123..HEAD~2"} {
+		if err := git.ValidateRange(valid); err != nil {
+			t.Errorf("Expected %q to be a valid range, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"v1.0", "", "..", "...", "--all..HEAD", "v1.0 ..v1.1"} {
+		if err := git.ValidateRange(invalid); err == nil {
+			t.Errorf("Expected an error for range %q", invalid)
+		}
+	}
+}
+
+func containsArg(args []string, want string) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddNote(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Alice"},
+		{"config", "user.email", "alice@example.com"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	// A second note replaces the first
+	for _, note := range []string{`{"pairs":[]}`, `{"pairs":[{"a":"alice@example.com"}]}`} {
+		if err := git.AddNote(dir, "pairstair", note); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		out, err := exec.Command("git", "-C", dir, "notes", "--ref=pairstair", "show", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("git notes show failed: %v", err)
+		}
+		if got := strings.TrimSpace(string(out)); got != note {
+			t.Errorf("Expected note %q, got %q", note, got)
+		}
+	}
+
+	// Notes under other refs are untouched
+	if err := exec.Command("git", "-C", dir, "notes", "show", "HEAD").Run(); err == nil {
+		t.Errorf("Expected no note under the default notes ref")
+	}
+}
+
+func TestAddNoteOutsideRepository(t *testing.T) {
+	if err := git.AddNote(t.TempDir(), "pairstair", "note"); err == nil {
+		t.Errorf("Expected an error outside a git repository")
+	}
+}
+
+func TestGetCommitsOutsideRepository(t *testing.T) {
+	_, err := git.GetCommits(git.LogOptions{Since: "1.weeks", Dir: t.TempDir()})
+	if !errors.Is(err, git.ErrNotARepo) {
+		t.Errorf("Expected ErrNotARepo, got %v", err)
+	}
+}
+
+func TestGitNotFound(t *testing.T) {
+	// With nothing on the PATH, the git executable can't be found
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := git.GetCommits(git.LogOptions{Since: "1.weeks"}); !errors.Is(err, git.ErrGitNotFound) {
+		t.Errorf("Expected ErrGitNotFound reading commits, got %v", err)
+	}
+	if _, err := git.GetCommits(git.LogOptions{Since: "1.weeks", Dir: t.TempDir()}); !errors.Is(err, git.ErrGitNotFound) {
+		t.Errorf("Expected ErrGitNotFound reading commits from a directory, got %v", err)
+	}
+	if err := git.AddNote(t.TempDir(), "pairstair", "note"); !errors.Is(err, git.ErrGitNotFound) {
+		t.Errorf("Expected ErrGitNotFound adding a note, got %v", err)
+	}
+}
+
+func TestGetCommitsFromBareRepository(t *testing.T) {
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+	bare := filepath.Join(dir, "bare.git")
+	for _, args := range [][]string{
+		{"init", work},
+		{"-C", work, "config", "user.name", "Alice"},
+		{"-C", work, "config", "user.email", "alice@example.com"},
+		{"-C", work, "commit", "--allow-empty", "-m", "Pair\n\nCo-authored-by: Bob Jones <bob@example.com>"},
+		{"clone", "--bare", work, bare},
+		{"-C", bare, "config", "user.name", "Alice"},
+		{"-C", bare, "config", "user.email", "alice@example.com"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	for name, repo := range map[string]string{
+		"bare clone":     bare,
+		".git directory": filepath.Join(work, ".git"),
+		"working tree":   work,
+	} {
+		t.Run(name, func(t *testing.T) {
+			commits, err := git.GetCommits(git.LogOptions{Since: "1.weeks", Dir: repo})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(commits) != 1 || len(commits[0].CoAuthors) != 1 || commits[0].CoAuthors[0].CanonicalEmail() != "bob@example.com" {
+				t.Errorf("Expected the commit with Bob as co-author, got %+v", commits)
+			}
+		})
+	}
+
+	if err := git.AddNote(bare, "pairstair", "{}"); err != nil {
+		t.Errorf("Expected to add a note in a bare repository, got %v", err)
+	}
+}
+
+func TestWorktreeDirectories(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	worktree := filepath.Join(dir, "worktree")
+	for _, args := range [][]string{
+		{"init", repo},
+		{"-C", repo, "config", "user.name", "Alice"},
+		{"-C", repo, "config", "user.email", "alice@example.com"},
+		{"-C", repo, "commit", "--allow-empty", "-m", "Initial commit"},
+		{"-C", repo, "worktree", "add", "-b", "feature", worktree},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(worktree, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	common, err := git.CommonDir(filepath.Join(worktree, "sub"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := filepath.Join(repo, ".git"); !sameFile(t, common, want) {
+		t.Errorf("Expected the worktree to share %s, got %s", want, common)
+	}
+
+	top, err := git.TopLevel(filepath.Join(worktree, "sub"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !sameFile(t, top, worktree) {
+		t.Errorf("Expected the worktree's own root %s, got %s", worktree, top)
+	}
+
+	if _, err := git.CommonDir(t.TempDir()); !errors.Is(err, git.ErrNotARepo) {
+		t.Errorf("Expected ErrNotARepo outside a repository, got %v", err)
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	dir := t.TempDir()
+	library := filepath.Join(dir, "library")
+	app := filepath.Join(dir, "app")
+	for _, args := range [][]string{
+		{"init", library},
+		{"-C", library, "config", "user.name", "Alice"},
+		{"-C", library, "config", "user.email", "alice@example.com"},
+		{"-C", library, "commit", "--allow-empty", "-m", "Library"},
+		{"init", app},
+		{"-C", app, "config", "user.name", "Alice"},
+		{"-C", app, "config", "user.email", "alice@example.com"},
+		{"-C", app, "-c", "protocol.file.allow=always", "submodule", "add", library, "vendor/library"},
+		{"-C", app, "commit", "-m", "Add the library"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	submodules, err := git.Submodules(app)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(submodules) != 1 || !sameFile(t, submodules[0], filepath.Join(app, "vendor", "library")) {
+		t.Errorf("Expected the library submodule, got %v", submodules)
+	}
+
+	if submodules, err := git.Submodules(library); err != nil || len(submodules) != 0 {
+		t.Errorf("Expected no submodules in the library, got %v, %v", submodules, err)
+	}
+}
+
+func TestGetCommitsMapsIdentitiesThroughMailmap(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", repo},
+		{"-C", repo, "config", "user.name", "alice"},
+		{"-C", repo, "config", "user.email", "alice@old.example.com"},
+		{"-C", repo, "commit", "--allow-empty", "-m", "Pairing\n\nCo-authored-by: bob <bob@old.example.com>\nReviewed-by: bob <bob@old.example.com>"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	mailmap := "Alice Smith <alice@example.com> <alice@old.example.com>\nBob Jones <bob@example.com> <bob@old.example.com>\n"
+	if err := os.WriteFile(filepath.Join(repo, ".mailmap"), []byte(mailmap), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := git.GetCommits(git.LogOptions{Dir: repo})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("Expected 1 commit, got %d", len(commits))
+	}
+	c := commits[0]
+	if c.Author.DisplayName != "Alice Smith" || c.Author.CanonicalEmail() != "alice@example.com" {
+		t.Errorf("Expected the author mapped to Alice Smith <alice@example.com>, got %v", c.Author)
+	}
+	if len(c.CoAuthors) != 1 || c.CoAuthors[0].DisplayName != "Bob Jones" || c.CoAuthors[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected the co-author mapped to Bob Jones <bob@example.com>, got %v", c.CoAuthors)
+	}
+	if len(c.Reviewers) != 1 || c.Reviewers[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected the reviewer mapped to bob@example.com, got %v", c.Reviewers)
+	}
+
+	commits, err = git.GetCommits(git.LogOptions{Dir: repo, NoMailmap: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := commits[0].Author.CanonicalEmail(); got != "alice@old.example.com" {
+		t.Errorf("Expected the author as committed without the mailmap, got %s", got)
+	}
+	if got := commits[0].CoAuthors[0].CanonicalEmail(); got != "bob@old.example.com" {
+		t.Errorf("Expected the co-author as committed without the mailmap, got %s", got)
+	}
+}
+
+// sameFile reports whether two paths name the same file, whatever symlinks they go through
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
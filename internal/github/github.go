@@ -0,0 +1,111 @@
+// Package github reads organization members from the GitHub API so that a draft
+// .team file can be written for them.
+//
+// It is only used by the init-team subcommand; the pairing analysis itself
+// never talks to the network.
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIURL is the base URL of the public GitHub API
+const APIURL = "https://api.github.com"
+
+// membersPerPage is the page size used when listing organization members
+const membersPerPage = 100
+
+// Member is a member of a GitHub organization
+type Member struct {
+	ID    int    `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"` // Only set if the member has a public email
+}
+
+// NoreplyEmail returns the GitHub noreply address the member commits with
+// when committing through GitHub
+func (m Member) NoreplyEmail() string {
+	return fmt.Sprintf("%d+%s@users.noreply.github.com", m.ID, strings.ToLower(m.Login))
+}
+
+// TeamEntry returns the member's line for a .team file, with their public email
+// (if any) followed by their noreply address
+func (m Member) TeamEntry() string {
+	name := m.Name
+	if name == "" {
+		name = m.Login
+	}
+	var emails []string
+	if m.Email != "" {
+		emails = append(emails, "<"+strings.ToLower(m.Email)+">")
+	}
+	emails = append(emails, "<"+m.NoreplyEmail()+">")
+	return name + " " + strings.Join(emails, ",")
+}
+
+// FetchOrgMembers returns the members of a GitHub organization, with their names
+// and public emails. The token may be empty, but without one only public
+// members are listed.
+func FetchOrgMembers(org, token string) ([]Member, error) {
+	return FetchOrgMembersWithURL(APIURL, org, token)
+}
+
+// FetchOrgMembersWithURL returns the members of a GitHub organization using a custom API URL.
+// This is exported to allow testing with mock servers.
+func FetchOrgMembersWithURL(baseURL, org, token string) ([]Member, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var members []Member
+	for page := 1; ; page++ {
+		var pageMembers []Member
+		url := fmt.Sprintf("%s/orgs/%s/members?per_page=%d&page=%d", baseURL, org, membersPerPage, page)
+		if err := getJSON(client, url, token, &pageMembers); err != nil {
+			return nil, err
+		}
+		members = append(members, pageMembers...)
+		if len(pageMembers) < membersPerPage {
+			break
+		}
+	}
+
+	// The members list only has logins, so fetch each profile for names and emails
+	for i, member := range members {
+		var profile Member
+		if err := getJSON(client, fmt.Sprintf("%s/users/%s", baseURL, member.Login), token, &profile); err != nil {
+			return nil, err
+		}
+		members[i].Name = profile.Name
+		members[i].Email = profile.Email
+	}
+
+	return members, nil
+}
+
+// getJSON fetches a GitHub API URL and decodes the JSON response into v
+func getJSON(client *http.Client, url, token string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned %s for %s", resp.Status, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
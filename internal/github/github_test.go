@@ -0,0 +1,94 @@
+package github_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/github"
+)
+
+func TestFetchOrgMembers(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/orgs/acme/members":
+			// Two pages: a full page of 100 members, then one more
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			var members []map[string]any
+			if page == 1 {
+				for i := 0; i < 100; i++ {
+					members = append(members, map[string]any{"id": i + 1, "login": fmt.Sprintf("user%d", i+1)})
+				}
+			} else if page == 2 {
+				members = append(members, map[string]any{"id": 583231, "login": "octocat"})
+			}
+			json.NewEncoder(w).Encode(members)
+		case "/users/octocat":
+			fmt.Fprint(w, `{"id": 583231, "login": "octocat", "name": "The Octocat", "email": "octocat@github.com"}`)
+		default:
+			fmt.Fprint(w, `{"name": null, "email": null}`)
+		}
+	}))
+	defer server.Close()
+
+	members, err := github.FetchOrgMembersWithURL(server.URL, "acme", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(members) != 101 {
+		t.Fatalf("Expected 101 members across both pages, got %d", len(members))
+	}
+	octocat := members[100]
+	if octocat.Login != "octocat" || octocat.Name != "The Octocat" || octocat.Email != "octocat@github.com" {
+		t.Errorf("Unexpected member: %+v", octocat)
+	}
+	for _, header := range authHeaders {
+		if header != "Bearer secret" {
+			t.Fatalf("Expected every request to use the token, got %q", header)
+		}
+	}
+}
+
+func TestFetchOrgMembers_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header without a token")
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := github.FetchOrgMembersWithURL(server.URL, "missing", "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+func TestMemberTeamEntry(t *testing.T) {
+	tests := []struct {
+		member   github.Member
+		expected string
+	}{
+		{
+			member:   github.Member{ID: 583231, Login: "octocat", Name: "The Octocat", Email: "Octocat@GitHub.com"},
+			expected: "The Octocat <octocat@github.com>,<583231+octocat@users.noreply.github.com>",
+		},
+		{
+			member:   github.Member{ID: 42, Login: "Hubot"},
+			expected: "Hubot <42+hubot@users.noreply.github.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		if entry := tt.member.TeamEntry(); entry != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, entry)
+		}
+	}
+}
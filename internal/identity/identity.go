@@ -0,0 +1,334 @@
+// Package identity links the different email addresses a developer commits
+// with, beyond what is listed in the .team file.
+//
+// GitHub noreply addresses such as 12345+username@users.noreply.github.com
+// are used for commits made through the GitHub web interface, so the same
+// person often appears under both their noreply address and a real one.
+package identity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// noreplyDomain is the domain of GitHub's noreply commit email addresses
+const noreplyDomain = "users.noreply.github.com"
+
+// GitHubUsername returns the GitHub username of a noreply email address, in
+// either the "ID+username" or older "username" form
+func GitHubUsername(email string) (string, bool) {
+	local, domain, found := strings.Cut(strings.ToLower(email), "@")
+	if !found || domain != noreplyDomain {
+		return "", false
+	}
+	if _, username, hasID := strings.Cut(local, "+"); hasID {
+		local = username
+	}
+	if local == "" {
+		return "", false
+	}
+	return local, true
+}
+
+// ParseGitHubUsers parses a comma-separated list of "username=email" mappings
+func ParseGitHubUsers(s string) (map[string]string, error) {
+	users := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		username, email, found := strings.Cut(entry, "=")
+		username, email = strings.TrimSpace(username), strings.TrimSpace(email)
+		if !found || username == "" || !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("invalid GitHub user mapping %q (expected username=email)", entry)
+		}
+		users[strings.ToLower(username)] = strings.ToLower(email)
+	}
+	return users, nil
+}
+
+// MergeGitHubNoreply returns the commits with GitHub noreply addresses replaced
+// by the developer's real address, so both count as the same person.
+//
+// The real address for a username is taken from users if it is mapped there.
+// Otherwise, when autoLink is set, it is the one other address in the commits
+// whose local part matches the username; if several match, the noreply address
+// is left alone rather than guessing.
+func MergeGitHubNoreply(commits []git.Commit, users map[string]string, autoLink bool) []git.Commit {
+	resolved := make(map[string]string)
+	for username, email := range users {
+		resolved[username] = email
+	}
+	if autoLink {
+		for username, email := range matchLocalParts(commits) {
+			if _, mapped := resolved[username]; !mapped {
+				resolved[username] = email
+			}
+		}
+	}
+	if len(resolved) == 0 {
+		return commits
+	}
+
+	merged := make([]git.Commit, len(commits))
+	for i, c := range commits {
+		merged[i] = git.Commit{
+			Hash:   c.Hash,
+			Date:   c.Date,
+			Author: resolve(c.Author, resolved),
+		}
+		for _, coAuthor := range c.CoAuthors {
+			merged[i].CoAuthors = append(merged[i].CoAuthors, resolve(coAuthor, resolved))
+		}
+		for _, reviewer := range c.Reviewers {
+			merged[i].Reviewers = append(merged[i].Reviewers, resolve(reviewer, resolved))
+		}
+	}
+	return merged
+}
+
+// matchLocalParts finds, for each noreply username in the commits, the single
+// other address whose local part is the same as the username
+func matchLocalParts(commits []git.Commit) map[string]string {
+	usernames := make(map[string]bool)
+	candidates := make(map[string]map[string]bool)
+	for _, c := range commits {
+		for _, d := range append([]git.Developer{c.Author}, c.CoAuthors...) {
+			email := d.CanonicalEmail()
+			if username, ok := GitHubUsername(email); ok {
+				usernames[username] = true
+				continue
+			}
+			local, _, _ := strings.Cut(email, "@")
+			if candidates[local] == nil {
+				candidates[local] = make(map[string]bool)
+			}
+			candidates[local][email] = true
+		}
+	}
+
+	matches := make(map[string]string)
+	for username := range usernames {
+		if emails := candidates[username]; len(emails) == 1 {
+			for email := range emails {
+				matches[username] = email
+			}
+		}
+	}
+	return matches
+}
+
+// resolve replaces a developer's noreply address with their resolved real address
+func resolve(d git.Developer, resolved map[string]string) git.Developer {
+	username, ok := GitHubUsername(d.CanonicalEmail())
+	if !ok {
+		return d
+	}
+	email, ok := resolved[username]
+	if !ok {
+		return d
+	}
+	return git.Developer{
+		DisplayName:     d.DisplayName,
+		EmailAddresses:  append([]string{email}, d.EmailAddresses...),
+		AbbreviatedName: d.AbbreviatedName,
+	}
+}
+
+// nameParticles are the words in surnames that are usually lowercase, such as
+// the "van" in "Ludwig van Beethoven"
+var nameParticles = map[string]bool{
+	"al": true, "bin": true, "da": true, "das": true, "de": true, "del": true,
+	"della": true, "der": true, "di": true, "do": true, "dos": true, "du": true,
+	"la": true, "le": true, "ten": true, "ter": true, "van": true, "von": true,
+}
+
+// NormalizeNames returns the commits with display names written in a single case,
+// such as "bob jones" or "BOB JONES", title-cased to "Bob Jones". Particles like
+// "de" and "van" are kept lowercase unless they start the name. Names in mixed
+// case, such as "Ann McKay", are left as their owner wrote them.
+func NormalizeNames(commits []git.Commit) []git.Commit {
+	normalized := make([]git.Commit, len(commits))
+	for i, c := range commits {
+		normalized[i] = git.Commit{
+			Hash:   c.Hash,
+			Date:   c.Date,
+			Author: normalizeDeveloper(c.Author),
+		}
+		for _, coAuthor := range c.CoAuthors {
+			normalized[i].CoAuthors = append(normalized[i].CoAuthors, normalizeDeveloper(coAuthor))
+		}
+		for _, reviewer := range c.Reviewers {
+			normalized[i].Reviewers = append(normalized[i].Reviewers, normalizeDeveloper(reviewer))
+		}
+	}
+	return normalized
+}
+
+// normalizeDeveloper title-cases a developer's display name, updating their initials to match
+func normalizeDeveloper(d git.Developer) git.Developer {
+	name := NormalizeName(d.DisplayName)
+	if name == d.DisplayName {
+		return d
+	}
+	return git.Developer{
+		DisplayName:     name,
+		EmailAddresses:  d.EmailAddresses,
+		AbbreviatedName: git.Initials(name),
+	}
+}
+
+// NormalizeName title-cases a name written all in lowercase or all in uppercase,
+// keeping particles such as "de" lowercase after the first word. Hyphenated parts
+// and parts after an apostrophe are capitalized too, as in "Mary-Jane O'Neil".
+func NormalizeName(name string) string {
+	if name != strings.ToLower(name) && name != strings.ToUpper(name) {
+		return name
+	}
+
+	words := strings.Fields(strings.ToLower(name))
+	for i, word := range words {
+		if i > 0 && nameParticles[word] {
+			continue
+		}
+		words[i] = capitalizeParts(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalizeParts capitalizes the start of a word and of each part of it after a
+// hyphen or apostrophe
+func capitalizeParts(word string) string {
+	runes := []rune(word)
+	for i, r := range runes {
+		if i == 0 || runes[i-1] == '-' || runes[i-1] == '\'' {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// SelfPair is a developer who seems to have listed themselves as a co-author
+// under another email address: the author and co-author share a display name.
+// Without a .team entry linking the emails they are counted as two people.
+type SelfPair struct {
+	Name     string
+	Author   string // Author's email
+	CoAuthor string // Co-author's email
+	Commits  int
+}
+
+// SelfPairs finds the commits whose author is also a co-author under a different
+// email, ordered by name then emails. Names are compared ignoring case.
+func SelfPairs(commits []git.Commit) []SelfPair {
+	counts := make(map[SelfPair]int)
+	for _, c := range commits {
+		name := strings.TrimSpace(c.Author.DisplayName)
+		if name == "" {
+			continue
+		}
+		for _, coAuthor := range c.CoAuthors {
+			if !strings.EqualFold(name, strings.TrimSpace(coAuthor.DisplayName)) ||
+				strings.EqualFold(c.Author.CanonicalEmail(), coAuthor.CanonicalEmail()) {
+				continue
+			}
+			counts[SelfPair{Name: name, Author: c.Author.CanonicalEmail(), CoAuthor: coAuthor.CanonicalEmail()}]++
+		}
+	}
+
+	selfPairs := make([]SelfPair, 0, len(counts))
+	for selfPair, count := range counts {
+		selfPair.Commits = count
+		selfPairs = append(selfPairs, selfPair)
+	}
+	sort.Slice(selfPairs, func(i, j int) bool {
+		a, b := selfPairs[i], selfPairs[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Author != b.Author {
+			return a.Author < b.Author
+		}
+		return a.CoAuthor < b.CoAuthor
+	})
+	return selfPairs
+}
+
+// NameCount is a display name and the number of commits it was used in
+type NameCount struct {
+	Name    string
+	Commits int
+}
+
+// NameVariant is an email address that has been committed under more than one
+// display name, with the names used most first
+type NameVariant struct {
+	Email string
+	Names []NameCount
+}
+
+// NameVariants finds the emails that appear under different display names in the
+// commits, as author or co-author, ordered by email. Names differing only in
+// surrounding whitespace are the same name.
+func NameVariants(commits []git.Commit) []NameVariant {
+	var variants []NameVariant
+	for email, names := range nameCounts(commits) {
+		if len(names) > 1 {
+			variants = append(variants, NameVariant{Email: email, Names: names})
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].Email < variants[j].Email
+	})
+	return variants
+}
+
+// MostFrequentNames maps each email in the commits to the display name it was used
+// with most often, taking the first name alphabetically on a tie
+func MostFrequentNames(commits []git.Commit) map[string]string {
+	names := make(map[string]string)
+	for email, counts := range nameCounts(commits) {
+		names[email] = counts[0].Name
+	}
+	return names
+}
+
+// nameCounts counts the commits each email was used in under each display name,
+// with each email's names ordered by most commits, then by name. Empty names
+// aren't counted.
+func nameCounts(commits []git.Commit) map[string][]NameCount {
+	counts := make(map[string]map[string]int)
+	for _, c := range commits {
+		for _, d := range append([]git.Developer{c.Author}, c.CoAuthors...) {
+			name := strings.TrimSpace(d.DisplayName)
+			if name == "" {
+				continue
+			}
+			email := d.CanonicalEmail()
+			if counts[email] == nil {
+				counts[email] = make(map[string]int)
+			}
+			counts[email][name]++
+		}
+	}
+
+	byEmail := make(map[string][]NameCount)
+	for email, names := range counts {
+		for name, n := range names {
+			byEmail[email] = append(byEmail[email], NameCount{Name: name, Commits: n})
+		}
+		sort.Slice(byEmail[email], func(i, j int) bool {
+			a, b := byEmail[email][i], byEmail[email][j]
+			if a.Commits != b.Commits {
+				return a.Commits > b.Commits
+			}
+			return a.Name < b.Name
+		})
+	}
+	return byEmail
+}
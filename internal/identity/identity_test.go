@@ -0,0 +1,333 @@
+package identity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/identity"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+func TestGitHubUsername(t *testing.T) {
+	tests := []struct {
+		email    string
+		username string
+		ok       bool
+	}{
+		{"12345+octocat@users.noreply.github.com", "octocat", true},
+		{"OctoCat@users.noreply.github.com", "octocat", true},
+		{"octocat@example.com", "", false},
+		{"12345+@users.noreply.github.com", "", false},
+	}
+
+	for _, tt := range tests {
+		username, ok := identity.GitHubUsername(tt.email)
+		if username != tt.username || ok != tt.ok {
+			t.Errorf("GitHubUsername(%q): expected (%q, %v), got (%q, %v)", tt.email, tt.username, tt.ok, username, ok)
+		}
+	}
+}
+
+func TestParseGitHubUsers(t *testing.T) {
+	users, err := identity.ParseGitHubUsers("tamj0rd2=Tamara.Jordan@example.com, Octocat=octo@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if users["tamj0rd2"] != "tamara.jordan@example.com" || users["octocat"] != "octo@example.com" {
+		t.Errorf("Unexpected mapping: %v", users)
+	}
+
+	if _, err := identity.ParseGitHubUsers("octocat"); err == nil {
+		t.Error("Expected error for mapping without an email")
+	}
+}
+
+func TestMergeGitHubNoreply(t *testing.T) {
+	date := time.Date(2025, 6, 26, 16, 0, 0, 0, time.UTC)
+	ahmad := git.NewDeveloper("Ahmad Qurbanzada <ahmad@example.com>")
+
+	tests := []struct {
+		name          string
+		commits       []git.Commit
+		users         map[string]string
+		autoLink      bool
+		wantDevs      int
+		wantPairs     int
+		wantCanonical string
+	}{
+		{
+			name: "noreply and real email in the same commit stay apart without linking",
+			commits: []git.Commit{{
+				Date:      date,
+				Author:    git.NewDeveloper("Tamara Jordan <tamara@example.com>"),
+				CoAuthors: []git.Developer{ahmad, git.NewDeveloper("Tamara Jordan <20561445+tamara@users.noreply.github.com>")},
+			}},
+			wantDevs:  3,
+			wantPairs: 3,
+		},
+		{
+			name: "username matching a local part is linked automatically",
+			commits: []git.Commit{{
+				Date:      date,
+				Author:    git.NewDeveloper("Tamara Jordan <tamara@example.com>"),
+				CoAuthors: []git.Developer{ahmad, git.NewDeveloper("Tamara Jordan <20561445+tamara@users.noreply.github.com>")},
+			}},
+			autoLink:      true,
+			wantDevs:      2,
+			wantPairs:     1,
+			wantCanonical: "tamara@example.com",
+		},
+		{
+			name: "mapped username is linked even when the local part differs",
+			commits: []git.Commit{{
+				Date:      date,
+				Author:    git.NewDeveloper("Tamara Jordan <tamara.jordan@example.com>"),
+				CoAuthors: []git.Developer{ahmad, git.NewDeveloper("Tamara Jordan <20561445+tamj0rd2@users.noreply.github.com>")},
+			}},
+			users:         map[string]string{"tamj0rd2": "tamara.jordan@example.com"},
+			wantDevs:      2,
+			wantPairs:     1,
+			wantCanonical: "tamara.jordan@example.com",
+		},
+		{
+			name: "ambiguous local parts are not linked",
+			commits: []git.Commit{
+				{Date: date, Author: git.NewDeveloper("Sam One <sam@one.example.com>"), CoAuthors: []git.Developer{ahmad}},
+				{Date: date, Author: git.NewDeveloper("Sam Two <sam@two.example.com>"), CoAuthors: []git.Developer{git.NewDeveloper("Sam <1+sam@users.noreply.github.com>")}},
+			},
+			autoLink:  true,
+			wantDevs:  4,
+			wantPairs: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits := identity.MergeGitHubNoreply(tt.commits, tt.users, tt.autoLink)
+			matrix, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+
+			if len(developers) != tt.wantDevs {
+				t.Errorf("Expected %d developers, got %d: %v", tt.wantDevs, len(developers), developers)
+			}
+			if matrix.Len() != tt.wantPairs {
+				t.Errorf("Expected %d pairs, got %d", tt.wantPairs, matrix.Len())
+			}
+			if tt.wantCanonical != "" {
+				if count := matrix.Count(ahmad.CanonicalEmail(), tt.wantCanonical); count != 1 {
+					t.Errorf("Expected Ahmad to have paired with %s once, got %d", tt.wantCanonical, count)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeGitHubNoreplyWithTeam(t *testing.T) {
+	// The noreply address isn't in the .team file, but resolves to one that is
+	teamObj := team.NewTeamFromDevelopers([]git.Developer{
+		git.NewDeveloper("Ahmad Qurbanzada <ahmad@example.com>"),
+		git.NewDeveloper("Octo Cat <octocat@example.com>"),
+	})
+	commits := []git.Commit{{
+		Date:      time.Date(2025, 6, 26, 16, 0, 0, 0, time.UTC),
+		Author:    git.NewDeveloper("Octo Cat <583231+octocat@users.noreply.github.com>"),
+		CoAuthors: []git.Developer{git.NewDeveloper("Ahmad Qurbanzada <ahmad@example.com>")},
+	}}
+
+	merged := identity.MergeGitHubNoreply(commits, map[string]string{"octocat": "octocat@example.com"}, false)
+	matrix, _, _ := pairing.BuildPairMatrix(teamObj, merged, true)
+
+	if count := matrix.Count("ahmad@example.com", "octocat@example.com"); count != 1 {
+		t.Errorf("Expected Ahmad and Octo Cat to have paired once, got %d", count)
+	}
+}
+
+func TestSelfPairs(t *testing.T) {
+	date := time.Date(2025, 6, 26, 16, 0, 0, 0, time.UTC)
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	aliceHome := git.NewDeveloper("alice smith <alice@home.example>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+
+	tests := []struct {
+		name    string
+		commits []git.Commit
+		want    []identity.SelfPair
+	}{
+		{
+			name:    "different people",
+			commits: []git.Commit{{Date: date, Author: alice, CoAuthors: []git.Developer{bob}}},
+		},
+		{
+			name:    "same name and email is not a self pair",
+			commits: []git.Commit{{Date: date, Author: alice, CoAuthors: []git.Developer{alice}}},
+		},
+		{
+			name: "same name with a different email, ignoring case",
+			commits: []git.Commit{
+				{Date: date, Author: alice, CoAuthors: []git.Developer{bob, aliceHome}},
+				{Date: date, Author: alice, CoAuthors: []git.Developer{aliceHome}},
+			},
+			want: []identity.SelfPair{{Name: "Alice Smith", Author: "alice@example.com", CoAuthor: "alice@home.example", Commits: 2}},
+		},
+		{
+			name: "each direction is listed separately",
+			commits: []git.Commit{
+				{Date: date, Author: alice, CoAuthors: []git.Developer{aliceHome}},
+				{Date: date, Author: aliceHome, CoAuthors: []git.Developer{alice}},
+			},
+			want: []identity.SelfPair{
+				{Name: "Alice Smith", Author: "alice@example.com", CoAuthor: "alice@home.example", Commits: 1},
+				{Name: "alice smith", Author: "alice@home.example", CoAuthor: "alice@example.com", Commits: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := identity.SelfPairs(tt.commits)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d self pairs, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %+v, got %+v", tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNameVariants(t *testing.T) {
+	date := time.Date(2025, 6, 26, 16, 0, 0, 0, time.UTC)
+	tamara := git.NewDeveloper("Tamara Jordan <tamara@example.com>")
+	tamj := git.NewDeveloper("tamj0rd2 <tamara@example.com>")
+	ahmad := git.NewDeveloper("Ahmad Qurbanzada <ahmad@example.com>")
+
+	commits := []git.Commit{
+		{Date: date, Author: tamj, CoAuthors: []git.Developer{ahmad}},
+		{Date: date, Author: ahmad, CoAuthors: []git.Developer{tamara}},
+		{Date: date, Author: tamara},
+		{Date: date, Author: git.NewDeveloper(" Ahmad Qurbanzada <ahmad@example.com>")},
+	}
+
+	variants := identity.NameVariants(commits)
+	if len(variants) != 1 {
+		t.Fatalf("Expected 1 email with name variants, got %+v", variants)
+	}
+	want := identity.NameVariant{
+		Email: "tamara@example.com",
+		Names: []identity.NameCount{{Name: "Tamara Jordan", Commits: 2}, {Name: "tamj0rd2", Commits: 1}},
+	}
+	if variants[0].Email != want.Email || len(variants[0].Names) != 2 || variants[0].Names[0] != want.Names[0] || variants[0].Names[1] != want.Names[1] {
+		t.Errorf("Expected %+v, got %+v", want, variants[0])
+	}
+
+	names := identity.MostFrequentNames(commits)
+	if names["tamara@example.com"] != "Tamara Jordan" || names["ahmad@example.com"] != "Ahmad Qurbanzada" {
+		t.Errorf("Expected the most frequent names, got %v", names)
+	}
+
+	// Ties go to the first name alphabetically
+	tied := identity.MostFrequentNames([]git.Commit{{Date: date, Author: tamj}, {Date: date, Author: tamara}})
+	if tied["tamara@example.com"] != "Tamara Jordan" {
+		t.Errorf("Expected a tie to go to the first name alphabetically, got %q", tied["tamara@example.com"])
+	}
+}
+
+func TestMostFrequentNamesInTeamMode(t *testing.T) {
+	date := time.Date(2025, 6, 26, 16, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: date, Author: git.NewDeveloper("tamj0rd2 <tamara@example.com>"), CoAuthors: []git.Developer{git.NewDeveloper("Ahmad Qurbanzada <ahmad@example.com>")}},
+		{Date: date, Author: git.NewDeveloper("Tamara Jordan <tamara@example.com>"), CoAuthors: []git.Developer{git.NewDeveloper("Ahmad Q <ahmad@example.com>")}},
+		{Date: date, Author: git.NewDeveloper("Tamara Jordan <tamara@example.com>")},
+	}
+	// Tamara has no name in the team file; Ahmad's name there wins over his commits
+	teamObj, err := team.NewTeam([]string{"<tamara@example.com>", "Ahmad Qurbanzada <ahmad@example.com>"})
+	if err != nil {
+		t.Fatalf("NewTeam failed: %v", err)
+	}
+
+	options := pairing.BuildOptions{Names: identity.MostFrequentNames(commits)}
+	_, _, developers := pairing.BuildPairMatrixWithOptions(teamObj, commits, true, options)
+	names := make(map[string]string)
+	for _, dev := range developers {
+		names[dev.CanonicalEmail()] = dev.DisplayName
+	}
+	if names["tamara@example.com"] != "Tamara Jordan" {
+		t.Errorf("Expected the most frequent name for the unnamed team member, got %q", names["tamara@example.com"])
+	}
+	if names["ahmad@example.com"] != "Ahmad Qurbanzada" {
+		t.Errorf("Expected the team file's name to be kept, got %q", names["ahmad@example.com"])
+	}
+
+	// Without a team, the most frequent name replaces the first one seen
+	_, _, developers = pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, options)
+	for _, dev := range developers {
+		if dev.CanonicalEmail() == "tamara@example.com" && dev.DisplayName != "Tamara Jordan" {
+			t.Errorf("Expected the most frequent name without a team, got %q", dev.DisplayName)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"bob jones", "Bob Jones"},
+		{"BOB JONES", "Bob Jones"},
+		{"Bob Jones", "Bob Jones"},
+		{"Ann McKay", "Ann McKay"},
+		{"ludwig van beethoven", "Ludwig van Beethoven"},
+		{"MARIA DE LA CRUZ", "Maria de la Cruz"},
+		{"van morrison", "Van Morrison"},
+		{"mary-jane o'neil", "Mary-Jane O'Neil"},
+		{"  bob   jones ", "Bob Jones"},
+		{"émile zola", "Émile Zola"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identity.NormalizeName(tt.name); got != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, expected %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeNames(t *testing.T) {
+	date := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: date, Author: git.NewDeveloper("BOB JONES <bob@example.com>"), CoAuthors: []git.Developer{git.NewDeveloper("alice smith <alice@example.com>")}},
+		{Date: date, Author: git.NewDeveloper("bob jones <bob@example.com>")},
+	}
+
+	normalized := identity.NormalizeNames(commits)
+
+	if commits[0].Author.DisplayName != "BOB JONES" {
+		t.Error("Expected the original commits to be unchanged")
+	}
+	if variants := identity.NameVariants(normalized); len(variants) != 0 {
+		t.Errorf("Expected the casings of Bob's name to be consolidated, got %v", variants)
+	}
+	if author := normalized[0].Author; author.DisplayName != "Bob Jones" || author.AbbreviatedName != "BJ" || author.CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected Bob Jones (BJ) <bob@example.com>, got %+v", author)
+	}
+	if coAuthor := normalized[0].CoAuthors[0]; coAuthor.DisplayName != "Alice Smith" {
+		t.Errorf("Expected co-authors to be normalized, got %q", coAuthor.DisplayName)
+	}
+
+	// The team file's names are used as written
+	teamObj, err := team.NewTeam([]string{"BOB JONES <bob@example.com>", "<alice@example.com>"})
+	if err != nil {
+		t.Fatalf("NewTeam failed: %v", err)
+	}
+	_, _, developers := pairing.BuildPairMatrix(teamObj, normalized, true)
+	for _, dev := range developers {
+		if dev.CanonicalEmail() == "bob@example.com" && dev.DisplayName != "BOB JONES" {
+			t.Errorf("Expected the team file's name, got %q", dev.DisplayName)
+		}
+	}
+}
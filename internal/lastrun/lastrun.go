@@ -0,0 +1,70 @@
+// Package lastrun provides persistence of the time pairstair was last run
+// against each repository, allowing later runs to analyze only new activity.
+//
+// Timestamps are kept in a single JSON dotfile keyed by repository path. A
+// missing or unreadable file is treated as "never run" rather than an error.
+package lastrun
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultFileName is the name of the dotfile in the user's home directory
+const DefaultFileName = ".pairstair-last-run.json"
+
+// Store reads and writes last-run timestamps keyed by repository
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store backed by the file at the given path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// NewDefaultStore creates a Store backed by the dotfile in the user's home directory
+func NewDefaultStore() (*Store, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(filepath.Join(home, DefaultFileName)), nil
+}
+
+// Get returns the last-run time recorded for the repository.
+// The boolean is false if there is no usable record, including when the
+// file is missing or corrupt.
+func (s *Store) Get(repo string) (time.Time, bool) {
+	last, ok := s.read()[repo]
+	return last, ok
+}
+
+// Set records the last-run time for the repository, preserving other entries.
+// A corrupt file is replaced.
+func (s *Store) Set(repo string, t time.Time) error {
+	entries := s.read()
+	entries[repo] = t
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// read loads all entries, returning an empty map if the file can't be used
+func (s *Store) read() map[string]time.Time {
+	entries := make(map[string]time.Time)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return make(map[string]time.Time)
+	}
+	return entries
+}
@@ -0,0 +1,80 @@
+package lastrun_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/lastrun"
+)
+
+func TestStoreMissingFile(t *testing.T) {
+	store := lastrun.NewStore(filepath.Join(t.TempDir(), "missing.json"))
+
+	if _, ok := store.Get("/repo"); ok {
+		t.Error("Expected no last run for a missing file")
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-run.json")
+	when := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	if err := lastrun.NewStore(path).Set("/repo/one", when); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// A fresh store reads the persisted value
+	got, ok := lastrun.NewStore(path).Get("/repo/one")
+	if !ok {
+		t.Fatal("Expected a stored last run")
+	}
+	if !got.Equal(when) {
+		t.Errorf("Expected %v, got %v", when, got)
+	}
+}
+
+func TestStoreKeyedPerRepo(t *testing.T) {
+	store := lastrun.NewStore(filepath.Join(t.TempDir(), "last-run.json"))
+	first := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := store.Set("/repo/one", first); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set("/repo/two", second); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if got, _ := store.Get("/repo/one"); !got.Equal(first) {
+		t.Errorf("Expected repo one to keep %v, got %v", first, got)
+	}
+	if got, _ := store.Get("/repo/two"); !got.Equal(second) {
+		t.Errorf("Expected repo two to have %v, got %v", second, got)
+	}
+	if _, ok := store.Get("/repo/three"); ok {
+		t.Error("Expected no last run for an unknown repo")
+	}
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-run.json")
+	if err := os.WriteFile(path, []byte("not json {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := lastrun.NewStore(path)
+
+	if _, ok := store.Get("/repo"); ok {
+		t.Error("Expected no last run for a corrupt file")
+	}
+
+	// Writing replaces the corrupt contents
+	when := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Set("/repo", when); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if got, ok := store.Get("/repo"); !ok || !got.Equal(when) {
+		t.Errorf("Expected %v after overwriting corrupt file, got %v (ok=%t)", when, got, ok)
+	}
+}
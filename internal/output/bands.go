@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MatrixStyle is what the CLI matrix shows for each pair
+type MatrixStyle string
+
+const (
+	// CountStyle shows how many times each pair paired
+	CountStyle MatrixStyle = "count"
+	// RecencyBandsStyle shows a symbol for how recently each pair last paired
+	RecencyBandsStyle MatrixStyle = "recency-bands"
+)
+
+// ParseMatrixStyle converts a matrix style name to a MatrixStyle. The empty
+// string means CountStyle.
+func ParseMatrixStyle(style string) (MatrixStyle, error) {
+	switch MatrixStyle(style) {
+	case CountStyle, "":
+		return CountStyle, nil
+	case RecencyBandsStyle:
+		return RecencyBandsStyle, nil
+	default:
+		return "", fmt.Errorf("invalid matrix style: %s (expected 'count' or 'recency-bands')", style)
+	}
+}
+
+// RecencyBand is how long ago a pair last paired, in broad bands
+type RecencyBand int
+
+const (
+	// NeverPaired is the band of a pair who have never paired
+	NeverPaired RecencyBand = iota
+	// WithinWeek is the band of a pair who paired in the last 7 days
+	WithinWeek
+	// WithinMonth is the band of a pair who paired in the last 30 days
+	WithinMonth
+	// WithinQuarter is the band of a pair who paired in the last 90 days
+	WithinQuarter
+	// LongerAgo is the band of a pair who last paired more than 90 days ago
+	LongerAgo
+)
+
+// recencyBands are the bands in the order they're explained, with the symbols they're
+// drawn with in Unicode and in ASCII and what they mean
+var recencyBands = []struct {
+	band          RecencyBand
+	symbol, ascii string
+	description   string
+}{
+	{WithinWeek, "●", "#", "within a week"},
+	{WithinMonth, "◐", "+", "within a month"},
+	{WithinQuarter, "○", "o", "within a quarter"},
+	{LongerAgo, "·", ".", "longer ago"},
+}
+
+// RecencyBandFor returns the band of a pair who last paired at last, measured back
+// from now, or NeverPaired if they haven't paired
+func RecencyBandFor(last time.Time, paired bool, now time.Time) RecencyBand {
+	if !paired {
+		return NeverPaired
+	}
+	switch days := int(now.Sub(last).Hours() / 24); {
+	case days <= 7:
+		return WithinWeek
+	case days <= 30:
+		return WithinMonth
+	case days <= 90:
+		return WithinQuarter
+	default:
+		return LongerAgo
+	}
+}
+
+// Symbol returns the symbol the band is drawn with in the matrix, or an empty
+// string for NeverPaired
+func (b RecencyBand) Symbol(ascii bool) string {
+	for _, band := range recencyBands {
+		if band.band == b {
+			if ascii {
+				return band.ascii
+			}
+			return band.symbol
+		}
+	}
+	return ""
+}
+
+// recencyBandsKey explains the symbols of the recency bands, e.g.
+// "● within a week, ◐ within a month, ..."
+func recencyBandsKey(ascii bool) string {
+	var parts []string
+	for _, band := range recencyBands {
+		parts = append(parts, band.band.Symbol(ascii)+" "+band.description)
+	}
+	return "Last paired: " + strings.Join(parts, ", ") + ", blank never"
+}
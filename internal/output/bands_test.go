@@ -0,0 +1,67 @@
+package output_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/output"
+)
+
+func TestRecencyBandFor(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		daysAgo  int
+		paired   bool
+		expected output.RecencyBand
+	}{
+		{"today", 0, true, output.WithinWeek},
+		{"a week ago", 7, true, output.WithinWeek},
+		{"eight days ago", 8, true, output.WithinMonth},
+		{"a month ago", 30, true, output.WithinMonth},
+		{"two months ago", 60, true, output.WithinQuarter},
+		{"a quarter ago", 90, true, output.WithinQuarter},
+		{"a year ago", 365, true, output.LongerAgo},
+		{"never", 0, false, output.NeverPaired},
+	}
+
+	for _, tt := range tests {
+		if got := output.RecencyBandFor(now.AddDate(0, 0, -tt.daysAgo), tt.paired, now); got != tt.expected {
+			t.Errorf("%s: expected band %d, got %d", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRecencyBandSymbol(t *testing.T) {
+	tests := []struct {
+		band          output.RecencyBand
+		symbol, ascii string
+	}{
+		{output.WithinWeek, "●", "#"},
+		{output.WithinMonth, "◐", "+"},
+		{output.WithinQuarter, "○", "o"},
+		{output.LongerAgo, "·", "."},
+		{output.NeverPaired, "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.band.Symbol(false); got != tt.symbol {
+			t.Errorf("Band %d: expected %q, got %q", tt.band, tt.symbol, got)
+		}
+		if got := tt.band.Symbol(true); got != tt.ascii {
+			t.Errorf("Band %d in ASCII: expected %q, got %q", tt.band, tt.ascii, got)
+		}
+	}
+}
+
+func TestParseMatrixStyle(t *testing.T) {
+	for name, want := range map[string]output.MatrixStyle{"": output.CountStyle, "count": output.CountStyle, "recency-bands": output.RecencyBandsStyle} {
+		if got, err := output.ParseMatrixStyle(name); err != nil || got != want {
+			t.Errorf("ParseMatrixStyle(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := output.ParseMatrixStyle("dots"); err == nil {
+		t.Error("Expected an error for an unknown matrix style")
+	}
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// PairDelta is the change in how often two developers paired between a baseline and the current run
+type PairDelta struct {
+	A, B          string
+	Before, After int
+}
+
+// BaselineDiff compares the current results with a baseline produced by the JSON renderer
+type BaselineDiff struct {
+	Before, After JSONCoverage
+	Pairs         []PairDelta // Only pairs whose count changed
+}
+
+// CoverageChange returns the change in coverage, in percentage points
+func (d BaselineDiff) CoverageChange() float64 {
+	return (d.After.Ratio - d.Before.Ratio) * 100
+}
+
+// LoadJSONResult reads a document written by the JSON renderer, rejecting
+// documents written with a different schema version
+func LoadJSONResult(r io.Reader) (JSONResult, error) {
+	var result JSONResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return JSONResult{}, fmt.Errorf("reading JSON result: %w", err)
+	}
+	if result.SchemaVersion != JSONSchemaVersion {
+		return JSONResult{}, fmt.Errorf("baseline has schema version %d but this version of pairstair writes version %d; regenerate it with -output json", result.SchemaVersion, JSONSchemaVersion)
+	}
+	return result, nil
+}
+
+// LoadJSONResultFile reads a document written by the JSON renderer from a file
+func LoadJSONResultFile(path string) (JSONResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return JSONResult{}, err
+	}
+	defer file.Close()
+	return LoadJSONResult(file)
+}
+
+// DiffJSONResults compares the current results with a baseline
+func DiffJSONResults(baseline, current JSONResult) BaselineDiff {
+	type key struct{ a, b string }
+	keyFor := func(a, b string) key {
+		if a > b {
+			a, b = b, a
+		}
+		return key{a, b}
+	}
+
+	counts := make(map[key]*PairDelta)
+	deltaFor := func(a, b string) *PairDelta {
+		k := keyFor(a, b)
+		if _, ok := counts[k]; !ok {
+			counts[k] = &PairDelta{A: k.a, B: k.b}
+		}
+		return counts[k]
+	}
+	for _, pair := range baseline.Pairs {
+		deltaFor(pair.A, pair.B).Before = pair.Count
+	}
+	for _, pair := range current.Pairs {
+		deltaFor(pair.A, pair.B).After = pair.Count
+	}
+
+	diff := BaselineDiff{Before: baseline.Coverage, After: current.Coverage}
+	for _, delta := range counts {
+		if delta.Before != delta.After {
+			diff.Pairs = append(diff.Pairs, *delta)
+		}
+	}
+	sort.Slice(diff.Pairs, func(i, j int) bool {
+		if diff.Pairs[i].A != diff.Pairs[j].A {
+			return diff.Pairs[i].A < diff.Pairs[j].A
+		}
+		return diff.Pairs[i].B < diff.Pairs[j].B
+	})
+	return diff
+}
+
+// RenderBaselineDiffToWriter prints the coverage and per-pair changes since the baseline
+func RenderBaselineDiffToWriter(w io.Writer, diff BaselineDiff) error {
+	_, err := fmt.Fprintf(w, "Coverage: %d/%d (%.0f%%) -> %d/%d (%.0f%%), %+.1f points\n",
+		diff.Before.Paired, diff.Before.Possible, diff.Before.Ratio*100,
+		diff.After.Paired, diff.After.Possible, diff.After.Ratio*100,
+		diff.CoverageChange())
+	if err != nil {
+		return err
+	}
+
+	if len(diff.Pairs) == 0 {
+		_, err = fmt.Fprintln(w, "\nNo pair counts changed")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "\nPair changes:"); err != nil {
+		return err
+	}
+	for _, delta := range diff.Pairs {
+		_, err := fmt.Fprintf(w, "  %s <-> %s : %d -> %d (%+d)\n", delta.A, delta.B, delta.Before, delta.After, delta.After-delta.Before)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
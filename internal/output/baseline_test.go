@@ -0,0 +1,70 @@
+package output_test
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/output"
+)
+
+func TestDiffJSONResults(t *testing.T) {
+	baseline, err := output.LoadJSONResultFile(filepath.Join("testdata", "baseline.json"))
+	if err != nil {
+		t.Fatalf("failed to load baseline: %v", err)
+	}
+	current, err := output.LoadJSONResultFile(filepath.Join("testdata", "current.json"))
+	if err != nil {
+		t.Fatalf("failed to load current: %v", err)
+	}
+
+	diff := output.DiffJSONResults(baseline, current)
+
+	expected := []output.PairDelta{
+		{A: "alice@example.com", B: "bob@example.com", Before: 3, After: 5},
+		{A: "alice@example.com", B: "carol@example.com", Before: 1, After: 0},
+	}
+	if len(diff.Pairs) != len(expected) {
+		t.Fatalf("Expected %d changed pairs, got %d: %+v", len(expected), len(diff.Pairs), diff.Pairs)
+	}
+	for i, want := range expected {
+		if diff.Pairs[i] != want {
+			t.Errorf("Pair %d: expected %+v, got %+v", i, want, diff.Pairs[i])
+		}
+	}
+
+	if change := diff.CoverageChange(); math.Abs(change-(-33.33)) > 0.01 {
+		t.Errorf("Expected coverage change of -33.33 points, got %.2f", change)
+	}
+
+	var result strings.Builder
+	if err := output.RenderBaselineDiffToWriter(&result, diff); err != nil {
+		t.Fatalf("RenderBaselineDiffToWriter failed: %v", err)
+	}
+	for _, want := range []string{
+		"Coverage: 3/3 (100%) -> 2/3 (67%), -33.3 points",
+		"alice@example.com <-> bob@example.com : 3 -> 5 (+2)",
+		"alice@example.com <-> carol@example.com : 1 -> 0 (-1)",
+	} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, result.String())
+		}
+	}
+}
+
+func TestLoadJSONResult_SchemaVersionMismatch(t *testing.T) {
+	_, err := output.LoadJSONResult(strings.NewReader(`{"schema_version": 99}`))
+	if err == nil {
+		t.Fatal("Expected error for a different schema version")
+	}
+	if !strings.Contains(err.Error(), "schema version 99") {
+		t.Errorf("Expected error to mention the baseline's schema version, got %q", err.Error())
+	}
+}
+
+func TestLoadJSONResult_InvalidJSON(t *testing.T) {
+	if _, err := output.LoadJSONResult(strings.NewReader("not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
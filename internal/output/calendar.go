@@ -0,0 +1,128 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// CalendarRenderer handles the calendar output: a GitHub-style HTML heatmap for
+// each developer, with a column per week and a row per day of the week, shaded
+// by how many different people they paired with that day
+type CalendarRenderer struct {
+	Participation *pairing.Participation
+	Start, End    time.Time
+	Theme         Theme
+}
+
+// NewCalendarRenderer creates a calendar renderer covering the days from start to end
+func NewCalendarRenderer(participation *pairing.Participation, start, end time.Time) *CalendarRenderer {
+	return &CalendarRenderer{Participation: participation, Start: start, End: end}
+}
+
+// Render outputs the calendar heatmaps as HTML
+func (r *CalendarRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderCalendarToWriterWithTheme(os.Stdout, r.Participation, developers, r.Start, r.End, r.Theme)
+}
+
+// RenderCalendarToWriter renders the calendar heatmaps as HTML to the provided io.Writer
+func RenderCalendarToWriter(w io.Writer, participation *pairing.Participation, developers []git.Developer, start, end time.Time) error {
+	return RenderCalendarToWriterWithTheme(w, participation, developers, start, end, LightTheme)
+}
+
+// RenderCalendarToWriterWithTheme renders the calendar heatmaps as HTML in the given
+// theme to the provided io.Writer
+func RenderCalendarToWriterWithTheme(w io.Writer, participation *pairing.Participation, developers []git.Developer, start, end time.Time, theme Theme) error {
+	_, err := io.WriteString(w, renderCalendar(participation, developers, start, end, theme))
+	return err
+}
+
+// renderCalendar generates the HTML for the calendar heatmaps. Days are shaded by
+// how many distinct partners someone paired with, from none up to three or more.
+func renderCalendar(participation *pairing.Participation, developers []git.Developer, start, end time.Time, theme Theme) string {
+	colors := theme.palette()
+	calendarColors := colors.heatmap
+
+	abbreviations := make(map[string]string)
+	for _, dev := range developers {
+		abbreviations[dev.CanonicalEmail()] = dev.AbbreviatedName
+	}
+
+	// Columns are whole weeks, starting on the Monday on or before the start date
+	start = truncateToDay(start)
+	end = truncateToDay(end)
+	firstMonday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Pair Stair Calendar</title>")
+	fmt.Fprintf(&b, `<style>
+body { font-family: sans-serif; margin: 2em; background: %s; color: %s; }
+table { border-collapse: separate; border-spacing: 3px; margin-bottom: 2em; }
+th { font-weight: normal; font-size: 0.8em; text-align: left; padding-right: 0.5em; }
+td { width: 12px; height: 12px; border-radius: 2px; }
+td.outside { background: none; }
+.key td { display: inline-block; }
+</style></head><body>`, colors.background, colors.text)
+	b.WriteString("<h1>Pair Stair Calendar</h1>")
+	fmt.Fprintf(&b, "<p>Days each developer paired from %s to %s, shaded by the number of different people they paired with.</p>",
+		start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	for _, dev := range developers {
+		fmt.Fprintf(&b, "<h2>%s (%s)</h2><table>", html.EscapeString(dev.DisplayName), html.EscapeString(dev.AbbreviatedName))
+		for weekday := 0; weekday < 7; weekday++ {
+			day := firstMonday.AddDate(0, 0, weekday)
+			fmt.Fprintf(&b, "<tr><th>%s</th>", day.Format("Mon"))
+			for ; !day.After(end); day = day.AddDate(0, 0, 7) {
+				if day.Before(start) {
+					b.WriteString("<td class=\"outside\"></td>")
+					continue
+				}
+				partners := participation.Partners(dev.CanonicalEmail(), day)
+				fmt.Fprintf(&b, "<td style=\"background: %s\" title=\"%s\"></td>",
+					calendarColors[min(len(partners), len(calendarColors)-1)], calendarTitle(day, partners, abbreviations))
+			}
+			b.WriteString("</tr>")
+		}
+		b.WriteString("</table>")
+	}
+
+	b.WriteString("<table class=\"key\"><tr><th>Partners:</th>")
+	for i, color := range calendarColors {
+		label := fmt.Sprintf("%d", i)
+		if i == len(calendarColors)-1 {
+			label += "+"
+		}
+		fmt.Fprintf(&b, "<td style=\"background: %s\" title=\"%s\"></td><th>%s</th>", color, label, label)
+	}
+	b.WriteString("</tr></table>")
+
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+// calendarTitle describes a day in a developer's calendar, for the cell's tooltip
+func calendarTitle(day time.Time, partners []string, abbreviations map[string]string) string {
+	if len(partners) == 0 {
+		return day.Format("Mon 2006-01-02") + ": no pairing"
+	}
+	names := make([]string, len(partners))
+	for i, partner := range partners {
+		names[i] = partner
+		if abbreviation, ok := abbreviations[partner]; ok {
+			names[i] = abbreviation
+		}
+	}
+	return html.EscapeString(fmt.Sprintf("%s: paired with %s", day.Format("Mon 2006-01-02"), strings.Join(names, ", ")))
+}
+
+// truncateToDay returns midnight at the start of the given time's day
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,87 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+func TestRenderCalendarToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	// Wednesday 5th to Wednesday 12th June 2024
+	start := time.Date(2024, 6, 5, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 12, 17, 0, 0, 0, time.UTC)
+
+	participation := pairing.NewParticipation()
+	monday := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	participation.Record(alice.CanonicalEmail(), bob.CanonicalEmail(), monday)
+	participation.Record(alice.CanonicalEmail(), carol.CanonicalEmail(), monday)
+
+	var result strings.Builder
+	if err := output.RenderCalendarToWriter(&result, participation, developers, start, end); err != nil {
+		t.Fatalf("RenderCalendarToWriter failed: %v", err)
+	}
+	html := result.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h2>Alice Smith (AS)</h2>",
+		"<h2>Carol Davis (CD)</h2>",
+		// Alice paired with two people on Monday, Bob with one
+		`<td style="background: #40c463" title="Mon 2024-06-10: paired with BJ, CD">`,
+		`<td style="background: #9be9a8" title="Mon 2024-06-10: paired with AS">`,
+		`title="Wed 2024-06-12: no pairing"`,
+		// The first week starts on Monday 3rd, before the window
+		`<td class="outside">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected calendar to contain %q", want)
+		}
+	}
+
+	if strings.Contains(html, "2024-06-13") {
+		t.Error("Expected no days after the end of the window")
+	}
+
+	// Each developer gets 7 rows, plus the row in the key
+	if rows := strings.Count(html, "<tr>"); rows != 3*7+1 {
+		t.Errorf("Expected %d rows, got %d", 3*7+1, rows)
+	}
+}
+
+func TestRenderCalendarToWriterWithDarkTheme(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	monday := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+
+	participation := pairing.NewParticipation()
+	participation.Record(alice.CanonicalEmail(), bob.CanonicalEmail(), monday)
+
+	var result strings.Builder
+	err := output.RenderCalendarToWriterWithTheme(&result, participation, []git.Developer{alice, bob}, monday, monday.AddDate(0, 0, 1), output.DarkTheme)
+	if err != nil {
+		t.Fatalf("RenderCalendarToWriterWithTheme failed: %v", err)
+	}
+	html := result.String()
+
+	for _, want := range []string{
+		"background: #0d1117",
+		`<td style="background: #0e4429" title="Mon 2024-06-10: paired with BJ">`,
+		`<td style="background: #21262d" title="Tue 2024-06-11: no pairing">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected dark calendar to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "#ebedf0") {
+		t.Error("Expected no light heatmap colors in the dark calendar")
+	}
+}
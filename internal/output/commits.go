@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// JSONCommit describes a commit as parsed, for -dump-commits. Hash is empty for
+// pairings read from -import.
+type JSONCommit struct {
+	Hash      string          `json:"hash,omitempty"`
+	Date      time.Time       `json:"date"`
+	Author    JSONCommitter   `json:"author"`
+	CoAuthors []JSONCommitter `json:"co_authors"`
+}
+
+// JSONCommitter is the author or a co-author of a JSONCommit
+type JSONCommitter struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// RenderCommitsToWriter renders the commits as JSON, one commit per line, to the
+// provided io.Writer
+func RenderCommitsToWriter(w io.Writer, commits []git.Commit) error {
+	encoder := json.NewEncoder(w)
+	for _, c := range commits {
+		commit := JSONCommit{
+			Hash:      c.Hash,
+			Date:      c.Date,
+			Author:    newJSONCommitter(c.Author),
+			CoAuthors: []JSONCommitter{},
+		}
+		for _, coAuthor := range c.CoAuthors {
+			commit.CoAuthors = append(commit.CoAuthors, newJSONCommitter(coAuthor))
+		}
+		if err := encoder.Encode(commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newJSONCommitter describes a developer as they appear in a commit
+func newJSONCommitter(d git.Developer) JSONCommitter {
+	return JSONCommitter{Name: d.DisplayName, Email: d.CanonicalEmail()}
+}
@@ -0,0 +1,57 @@
+package output_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+)
+
+func TestRenderCommitsToWriter(t *testing.T) {
+	mockGitOutput := `abc123
+Alice Smith <alice@example.com>
+2024-01-15T10:30:00-08:00
+Add new feature
+
+Co-authored-by: Bob Jones <bob@example.com>
+==END==
+def456
+Carol Davis <carol@example.com>
+2024-01-16T09:00:00Z
+Fix typo
+==END==`
+
+	var b strings.Builder
+	if err := output.RenderCommitsToWriter(&b, git.ParseGitLogOutput(mockGitOutput)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per commit, got %q", b.String())
+	}
+
+	var first output.JSONCommit
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Expected each line to be a JSON commit: %v", err)
+	}
+	want := output.JSONCommit{
+		Hash:      "abc123",
+		Date:      time.Date(2024, 1, 15, 18, 30, 0, 0, time.UTC),
+		Author:    output.JSONCommitter{Name: "Alice Smith", Email: "alice@example.com"},
+		CoAuthors: []output.JSONCommitter{{Name: "Bob Jones", Email: "bob@example.com"}},
+	}
+	if first.Hash != want.Hash || !first.Date.Equal(want.Date) || first.Author != want.Author || len(first.CoAuthors) != 1 || first.CoAuthors[0] != want.CoAuthors[0] {
+		t.Errorf("Expected %+v, got %+v", want, first)
+	}
+
+	if !strings.Contains(lines[1], `"co_authors":[]`) {
+		t.Errorf("Expected a commit without co-authors to have an empty list, got %s", lines[1])
+	}
+	if !strings.Contains(lines[0], `"date":"2024-01-15T10:30:00-08:00"`) {
+		t.Errorf("Expected the date as committed, with its offset, got %s", lines[0])
+	}
+}
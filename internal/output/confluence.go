@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// ConfluenceRenderer handles Confluence storage format output: XHTML tables for
+// the legend and matrix, and a list of recommendations, to paste into a page
+// with the Confluence source editor
+type ConfluenceRenderer struct {
+	Options Options
+}
+
+// Render outputs the legend, matrix and recommendations in Confluence storage format
+func (r *ConfluenceRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderConfluenceToWriter(os.Stdout, matrix, developers, strategy, recommendations, r.Options)
+}
+
+// RenderConfluenceToWriter renders Confluence storage format output to the provided io.Writer
+func RenderConfluenceToWriter(w io.Writer, matrix *pairing.Matrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) error {
+	_, err := io.WriteString(w, renderConfluence(matrix, developers, strategy, recommendations, options))
+	return err
+}
+
+// renderConfluence builds the storage format XHTML. It is a fragment of a page,
+// not a whole document, and every cell's text is escaped.
+func renderConfluence(matrix *pairing.Matrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) string {
+	var b strings.Builder
+	e := html.EscapeString
+
+	b.WriteString("<h2>Legend</h2>\n<table><tbody>\n<tr><th>Initials</th><th>Name</th><th>Email</th></tr>\n")
+	for _, dev := range developers {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>\n", e(dev.AbbreviatedName), e(dev.DisplayName), e(dev.CanonicalEmail()))
+	}
+	b.WriteString("</tbody></table>\n")
+
+	b.WriteString("<h2>Pair Matrix</h2>\n<table><tbody>\n<tr><th></th>")
+	for _, dev := range developers {
+		fmt.Fprintf(&b, "<th>%s</th>", e(dev.AbbreviatedName))
+	}
+	if options.Totals {
+		b.WriteString("<th>Total</th>")
+	}
+	b.WriteString("</tr>\n")
+	rowTotals, grandTotal := matrixTotals(matrix, developers, matrix.Weighted())
+	for i, dev1 := range developers {
+		fmt.Fprintf(&b, "<tr><th>%s</th>", e(dev1.AbbreviatedName))
+		for _, dev2 := range developers {
+			if dev1.CanonicalEmail() == dev2.CanonicalEmail() {
+				b.WriteString("<td>-</td>")
+				continue
+			}
+			fmt.Fprintf(&b, "<td>%s</td>", formatCell(matrix, dev1, dev2))
+		}
+		if options.Totals {
+			fmt.Fprintf(&b, "<th>%s</th>", formatTotal(matrix, rowTotals[i]))
+		}
+		b.WriteString("</tr>\n")
+	}
+	if options.Totals {
+		b.WriteString("<tr><th>Total</th>")
+		for _, total := range rowTotals {
+			fmt.Fprintf(&b, "<th>%s</th>", formatTotal(matrix, total))
+		}
+		fmt.Fprintf(&b, "<th>%s</th></tr>\n", formatTotal(matrix, grandTotal))
+	}
+	b.WriteString("</tbody></table>\n")
+
+	if len(recommendations) == 0 {
+		fmt.Fprintf(&b, "<h2>Pairing Recommendations</h2>\n<p>%s</p>\n", e(options.skippedMessage()))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<ul>\n", e(recommendationsHeading(strategy, options.Algorithm)))
+	primary := recommend.Strategy(strategy).Primary()
+	for _, rec := range recommendations {
+		a := rec.A.DisplayName + options.subTeamTags(rec.A)
+		switch {
+		case len(rec.B.EmailAddresses) == 0:
+			fmt.Fprintf(&b, "<li>%s (unpaired)</li>\n", e(a))
+		case primary == recommend.LeastRecent || primary == recommend.Coverage:
+			fmt.Fprintf(&b, "<li><strong>%s</strong> and <strong>%s</strong>: %s%s</li>\n",
+				e(a), e(rec.B.DisplayName+options.subTeamTags(rec.B)), e(FormatLastPaired(rec, options)), e(recentTag(rec)))
+		default:
+			fmt.Fprintf(&b, "<li><strong>%s</strong> and <strong>%s</strong>: %d times%s</li>\n",
+				e(a), e(rec.B.DisplayName+options.subTeamTags(rec.B)), rec.Count, e(recentTag(rec)))
+		}
+	}
+	b.WriteString("</ul>\n")
+	return b.String()
+}
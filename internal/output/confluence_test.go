@@ -0,0 +1,81 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestRenderConfluenceToWriter(t *testing.T) {
+	alice := git.Developer{DisplayName: "Alice <Al> & Smith", EmailAddresses: []string{"alice@example.com"}, AbbreviatedName: "AS"}
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(alice, bob)
+
+	recommendations := []recommend.Recommendation{{A: alice, B: bob, Count: 2, HasPaired: true}}
+
+	var result strings.Builder
+	if err := output.RenderConfluenceToWriter(&result, matrix, developers, "least-paired", recommendations, output.Options{}); err != nil {
+		t.Fatalf("RenderConfluenceToWriter failed: %v", err)
+	}
+	storage := result.String()
+
+	for _, want := range []string{
+		"<h2>Legend</h2>\n<table><tbody>\n<tr><th>Initials</th><th>Name</th><th>Email</th></tr>\n",
+		"<td>AS</td><td>Alice &lt;Al&gt; &amp; Smith</td><td>alice@example.com</td>",
+		"<h2>Pair Matrix</h2>\n<table><tbody>\n<tr><th></th><th>AS</th><th>BJ</th></tr>\n",
+		"<tr><th>BJ</th><td>2</td><td>-</td></tr>\n</tbody></table>\n",
+		"<li><strong>Alice &lt;Al&gt; &amp; Smith</strong> and <strong>Bob Jones</strong>: 2 times</li>",
+	} {
+		if !strings.Contains(storage, want) {
+			t.Errorf("Expected Confluence output to contain %q\nGot:\n%s", want, storage)
+		}
+	}
+
+	// Storage format is a page fragment, not a whole HTML document
+	for _, unwanted := range []string{"<html", "<body", "<style", "<Al>"} {
+		if strings.Contains(storage, unwanted) {
+			t.Errorf("Expected Confluence output not to contain %q", unwanted)
+		}
+	}
+}
+
+func TestRenderConfluenceToWriter_EmptyRecommendations(t *testing.T) {
+	developers := []git.Developer{git.NewDeveloper("Alice Smith <alice@example.com>")}
+
+	var result strings.Builder
+	if err := output.RenderConfluenceToWriter(&result, pairing.NewMatrix(), developers, "least-paired", nil, output.Options{}); err != nil {
+		t.Fatalf("RenderConfluenceToWriter failed: %v", err)
+	}
+	if !strings.Contains(result.String(), "<p>Skipping pairing recommendations - too many developers</p>") {
+		t.Errorf("Expected the skipped recommendations message, got:\n%s", result.String())
+	}
+}
+
+func TestRenderConfluenceToWriter_Weighted(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+
+	matrix := pairing.NewMatrix()
+	matrix.AddWeighted(alice.CanonicalEmail(), bob.CanonicalEmail(), 0.25)
+
+	var result strings.Builder
+	if err := output.RenderConfluenceToWriter(&result, matrix, []git.Developer{alice, bob}, "least-paired", nil, output.Options{Totals: true}); err != nil {
+		t.Fatalf("RenderConfluenceToWriter failed: %v", err)
+	}
+	for _, want := range []string{
+		"<tr><th>BJ</th><td>0.25</td><td>-</td><th>0.25</th></tr>\n",
+		"<tr><th>Total</th><th>0.25</th><th>0.25</th><th>0.50</th></tr>\n",
+	} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected weighted Confluence output to contain %q\nGot:\n%s", want, result.String())
+		}
+	}
+}
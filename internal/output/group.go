@@ -0,0 +1,84 @@
+package output
+
+import (
+	"sort"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// DeveloperGroup is the developers shown together when the matrix is grouped by sub-team
+type DeveloperGroup struct {
+	// SubTeam is the group's sub-team, or empty for developers in no sub-team
+	SubTeam    string
+	Developers []git.Developer
+}
+
+// GroupBySubTeam groups the developers by the sub-teams they're in, given as a map
+// from canonical email to sub-team names. A developer in several sub-teams is only
+// shown once, in the first sub-team listed for them (the first they appear in, in
+// the team file). Groups are ordered by sub-team name, with developers in no
+// sub-team last, and developers keep their order within a group.
+func GroupBySubTeam(developers []git.Developer, subTeams map[string][]string) []DeveloperGroup {
+	var groups []DeveloperGroup
+	var ungrouped []git.Developer
+	index := make(map[string]int)
+	for _, dev := range developers {
+		names := subTeams[dev.CanonicalEmail()]
+		if len(names) == 0 {
+			ungrouped = append(ungrouped, dev)
+			continue
+		}
+		i, ok := index[names[0]]
+		if !ok {
+			i = len(groups)
+			index[names[0]] = i
+			groups = append(groups, DeveloperGroup{SubTeam: names[0]})
+		}
+		groups[i].Developers = append(groups[i].Developers, dev)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].SubTeam < groups[j].SubTeam
+	})
+	if len(ungrouped) > 0 {
+		groups = append(groups, DeveloperGroup{Developers: ungrouped})
+	}
+	return groups
+}
+
+// label names the group, e.g. in the matrix legend
+func (g DeveloperGroup) label() string {
+	if g.SubTeam == "" {
+		return "(no sub-team)"
+	}
+	return g.SubTeam
+}
+
+// grouped reports whether the matrix is grouped by sub-team: GroupBySubTeam is
+// set, and there are sub-teams to group by
+func (o Options) grouped() bool {
+	return o.GroupBySubTeam && len(o.SubTeams) > 0
+}
+
+// matrixGroups returns the groups the matrix is shown in: by sub-team when
+// grouped, or otherwise a single group of all the developers
+func (o Options) matrixGroups(developers []git.Developer) []DeveloperGroup {
+	if !o.grouped() {
+		return []DeveloperGroup{{Developers: developers}}
+	}
+	return GroupBySubTeam(developers, o.SubTeams)
+}
+
+// flatten returns the developers of all the groups in order, and the positions of
+// the developers who start a group after the first
+func flatten(groups []DeveloperGroup) ([]git.Developer, map[int]bool) {
+	var developers []git.Developer
+	groupStarts := make(map[int]bool)
+	for i, group := range groups {
+		if i > 0 {
+			groupStarts[len(developers)] = true
+		}
+		developers = append(developers, group.Developers...)
+	}
+	return developers, groupStarts
+}
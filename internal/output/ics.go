@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// maxICSLineLength is the longest a content line may be, in octets, before it must be folded
+const maxICSLineLength = 75
+
+// ICSRenderer handles iCalendar output for pairing plans, with an all-day event
+// for each planned pair that people can import into their calendars
+type ICSRenderer struct {
+	// Stamp is the time the calendar was created, recorded in every event
+	Stamp time.Time
+}
+
+// RenderPlan outputs the plan as an iCalendar file
+func (r *ICSRenderer) RenderPlan(plan []recommend.PlanDay) error {
+	return RenderPlanICSToWriter(os.Stdout, plan, r.Stamp)
+}
+
+// RenderPlanICSToWriter renders the plan as an iCalendar file to the provided io.Writer.
+// Unpaired developers have no event.
+func RenderPlanICSToWriter(w io.Writer, plan []recommend.PlanDay, stamp time.Time) error {
+	var lines []string
+	lines = append(lines,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//pairstair//Pairing Plan//EN",
+		"CALSCALE:GREGORIAN",
+	)
+
+	for _, day := range plan {
+		for _, rec := range day.Recommendations {
+			if len(rec.B.EmailAddresses) == 0 {
+				continue // Unpaired developer
+			}
+			lines = append(lines,
+				"BEGIN:VEVENT",
+				fmt.Sprintf("UID:%s-%s-%s@pairstair", day.Date.Format("20060102"), rec.A.CanonicalEmail(), rec.B.CanonicalEmail()),
+				"DTSTAMP:"+stamp.UTC().Format("20060102T150405Z"),
+				"DTSTART;VALUE=DATE:"+day.Date.Format("20060102"),
+				"DTEND;VALUE=DATE:"+day.Date.AddDate(0, 0, 1).Format("20060102"),
+				"SUMMARY:"+icsEscape(fmt.Sprintf("Pair: %s & %s", rec.A.DisplayName, rec.B.DisplayName)),
+				icsAttendee(rec.A),
+				icsAttendee(rec.B),
+				"TRANSP:TRANSPARENT",
+				"END:VEVENT",
+			)
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsAttendee formats a developer as an event attendee
+func icsAttendee(dev git.Developer) string {
+	return fmt.Sprintf("ATTENDEE;CN=%q:mailto:%s", strings.ReplaceAll(dev.DisplayName, `"`, "'"), dev.CanonicalEmail())
+}
+
+// icsEscape escapes the characters that have special meaning in iCalendar text values
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold terminates a content line with CRLF, folding it onto continuation lines
+// (which start with a space) so no line is longer than maxICSLineLength octets.
+// Lines are only broken between UTF-8 characters.
+func icsFold(line string) string {
+	var b strings.Builder
+	limit := maxICSLineLength
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > limit {
+			b.WriteString("\r\n ")
+			limit = maxICSLineLength - 1 // Allow for the leading space
+			length = 0
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestRenderPlanICSToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis-Montgomery-Fitzgerald, Jr. <carol.davis.montgomery.fitzgerald@example.com>")
+
+	plan := []recommend.PlanDay{
+		{
+			Date: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+			Recommendations: []recommend.Recommendation{
+				{A: alice, B: bob},
+				{A: carol, B: git.Developer{}},
+			},
+		},
+		{
+			Date: time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC),
+			Recommendations: []recommend.Recommendation{
+				{A: alice, B: carol},
+				{A: bob, B: git.Developer{}},
+			},
+		},
+	}
+	stamp := time.Date(2024, 6, 7, 17, 30, 0, 0, time.UTC)
+
+	var result strings.Builder
+	if err := output.RenderPlanICSToWriter(&result, plan, stamp); err != nil {
+		t.Fatalf("RenderPlanICSToWriter failed: %v", err)
+	}
+	ics := result.String()
+
+	assertWellFormedICS(t, ics)
+
+	// Unfold continuation lines to check the content
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	for _, want := range []string{
+		"DTSTART;VALUE=DATE:20240610\r\nDTEND;VALUE=DATE:20240611\r\nSUMMARY:Pair: Alice Smith & Bob Jones\r\n",
+		"ATTENDEE;CN=\"Alice Smith\":mailto:alice@example.com\r\n",
+		"ATTENDEE;CN=\"Bob Jones\":mailto:bob@example.com\r\n",
+		"DTSTART;VALUE=DATE:20240611\r\n",
+		"SUMMARY:Pair: Alice Smith & Carol Davis-Montgomery-Fitzgerald\\, Jr.\r\n",
+		"DTSTAMP:20240607T173000Z\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", want, ics)
+		}
+	}
+
+	if events := strings.Count(ics, "BEGIN:VEVENT"); events != 2 {
+		t.Errorf("Expected 2 events (none for unpaired developers), got %d", events)
+	}
+}
+
+// assertWellFormedICS checks the structural rules of RFC 5545 that the renderer relies on
+func assertWellFormedICS(t *testing.T, ics string) {
+	t.Helper()
+
+	if !strings.HasSuffix(ics, "\r\n") {
+		t.Error("Expected calendar to end with CRLF")
+	}
+	lines := strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n")
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("Expected calendar to be wrapped in VCALENDAR, got first %q and last %q", lines[0], lines[len(lines)-1])
+	}
+
+	var open []string
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("Line contains a bare LF: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			continue // Continuation of a folded line
+		}
+		if !strings.Contains(line, ":") {
+			t.Errorf("Content line without a value: %q", line)
+		}
+		switch {
+		case strings.HasPrefix(line, "BEGIN:"):
+			open = append(open, strings.TrimPrefix(line, "BEGIN:"))
+		case strings.HasPrefix(line, "END:"):
+			if len(open) == 0 || open[len(open)-1] != strings.TrimPrefix(line, "END:") {
+				t.Fatalf("Unbalanced %q", line)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) != 0 {
+		t.Errorf("Unclosed components: %v", open)
+	}
+
+	for _, required := range []string{"VERSION:2.0", "PRODID:"} {
+		if !strings.Contains(ics, "\r\n"+required) {
+			t.Errorf("Expected calendar to contain %s", required)
+		}
+	}
+	for _, event := range strings.Split(ics, "BEGIN:VEVENT")[1:] {
+		for _, required := range []string{"UID:", "DTSTAMP:", "DTSTART"} {
+			if !strings.Contains(event, "\r\n"+required) {
+				t.Errorf("Expected every event to contain %s", required)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
+)
+
+// JSONSchemaVersion identifies the layout of the JSON output.
+// It must be incremented whenever a change would break existing consumers.
+const JSONSchemaVersion = 1
+
+// JSONResult is the document produced by the JSON renderer
+type JSONResult struct {
+	SchemaVersion   int                  `json:"schema_version"`
+	Strategy        string               `json:"strategy"`
+	Developers      []JSONDeveloper      `json:"developers"`
+	Pairs           []JSONPair           `json:"pairs"`
+	Coverage        JSONCoverage         `json:"coverage"`
+	Recommendations []JSONRecommendation `json:"recommendations"`
+	CommitSizes     *JSONCommitSizes     `json:"commit_sizes,omitempty"`
+}
+
+// JSONDeveloper describes a developer in the JSON output
+type JSONDeveloper struct {
+	Abbreviation string   `json:"abbreviation"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	Emails       []string `json:"emails"`
+}
+
+// JSONPair describes how often and how recently two developers paired.
+// Weight equals Count unless mob commits are split (see -mob-weight).
+// Only pairs who have paired at least once are included.
+type JSONPair struct {
+	A          string    `json:"a"`
+	B          string    `json:"b"`
+	Count      int       `json:"count"`
+	Weight     float64   `json:"weight"`
+	LastPaired time.Time `json:"last_paired"`
+}
+
+// JSONCoverage describes how many of the possible pairs have paired
+type JSONCoverage struct {
+	Paired   int     `json:"paired"`
+	Possible int     `json:"possible"`
+	Ratio    float64 `json:"ratio"`
+}
+
+// JSONCommitSizes counts the commits worked on by one developer, by two, and by
+// three or more, with each as a fraction of the total
+type JSONCommitSizes struct {
+	Solo       int     `json:"solo"`
+	Pairs      int     `json:"pairs"`
+	Mobs       int     `json:"mobs"`
+	SoloShare  float64 `json:"solo_share"`
+	PairsShare float64 `json:"pairs_share"`
+	MobsShare  float64 `json:"mobs_share"`
+}
+
+// JSONRecommendation describes a recommended pair; B is empty for an unpaired developer
+type JSONRecommendation struct {
+	A          string     `json:"a"`
+	B          string     `json:"b,omitempty"`
+	Count      int        `json:"count"`
+	HasPaired  bool       `json:"has_paired"`
+	LastPaired *time.Time `json:"last_paired,omitempty"`
+	DaysSince  *int       `json:"days_since,omitempty"`
+}
+
+// JSONRenderer handles machine-readable JSON output
+type JSONRenderer struct {
+	Options Options
+}
+
+// Render outputs the matrix and recommendations as JSON
+func (r *JSONRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderJSONToWriterWithOptions(os.Stdout, matrix, recencyMatrix, developers, strategy, recommendations, r.Options)
+}
+
+// RenderJSONToWriter renders JSON output to the provided io.Writer
+func RenderJSONToWriter(w io.Writer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderJSONToWriterWithOptions(w, matrix, recencyMatrix, developers, strategy, recommendations, Options{})
+}
+
+// RenderJSONToWriterWithOptions renders JSON output with the given options to the provided io.Writer
+func RenderJSONToWriterWithOptions(w io.Writer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONResultWithOptions(matrix, recencyMatrix, developers, strategy, recommendations, options))
+}
+
+// NewJSONResult builds the JSON document for the analysis results
+func NewJSONResult(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) JSONResult {
+	return NewJSONResultWithOptions(matrix, recencyMatrix, developers, strategy, recommendations, Options{})
+}
+
+// NewJSONResultWithOptions builds the JSON document for the analysis results, with
+// the commit sizes if the options have them
+func NewJSONResultWithOptions(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) JSONResult {
+	coverage := stats.CalculateCoverage(developers, matrix)
+	result := JSONResult{
+		SchemaVersion: JSONSchemaVersion,
+		Strategy:      strategy,
+		Developers:    jsonDevelopers(developers),
+		Pairs:         jsonPairs(matrix, recencyMatrix, developers),
+		Coverage: JSONCoverage{
+			Paired:   coverage.Paired,
+			Possible: coverage.Possible,
+			Ratio:    coverage.Ratio(),
+		},
+		Recommendations: jsonRecommendations(recommendations),
+	}
+	if sizes := options.CommitSizes; sizes != nil {
+		result.CommitSizes = &JSONCommitSizes{
+			Solo:       sizes.Solo,
+			Pairs:      sizes.Pairs,
+			Mobs:       sizes.Mobs,
+			SoloShare:  sizes.Share(sizes.Solo),
+			PairsShare: sizes.Share(sizes.Pairs),
+			MobsShare:  sizes.Share(sizes.Mobs),
+		}
+	}
+	return result
+}
+
+// jsonDevelopers converts developers to their JSON representation
+func jsonDevelopers(developers []git.Developer) []JSONDeveloper {
+	result := make([]JSONDeveloper, 0, len(developers))
+	for _, dev := range developers {
+		result = append(result, JSONDeveloper{
+			Abbreviation: dev.AbbreviatedName,
+			Name:         dev.DisplayName,
+			Email:        dev.CanonicalEmail(),
+			Emails:       dev.EmailAddresses,
+		})
+	}
+	return result
+}
+
+// jsonPairs lists every pair of developers who have paired, in developer order
+func jsonPairs(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer) []JSONPair {
+	result := []JSONPair{}
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			count := matrix.CountByDeveloper(developers[i], developers[j])
+			if count == 0 {
+				continue
+			}
+			lastPaired, _ := recencyMatrix.LastPairedByDeveloper(developers[i], developers[j])
+			result = append(result, JSONPair{
+				A:          developers[i].CanonicalEmail(),
+				B:          developers[j].CanonicalEmail(),
+				Count:      count,
+				Weight:     matrix.WeightByDeveloper(developers[i], developers[j]),
+				LastPaired: lastPaired,
+			})
+		}
+	}
+	return result
+}
+
+// jsonRecommendations converts recommendations to their JSON representation
+func jsonRecommendations(recommendations []recommend.Recommendation) []JSONRecommendation {
+	result := make([]JSONRecommendation, 0, len(recommendations))
+	for _, rec := range recommendations {
+		jsonRec := JSONRecommendation{
+			A:         rec.A.CanonicalEmail(),
+			B:         rec.B.CanonicalEmail(),
+			Count:     rec.Count,
+			HasPaired: rec.HasPaired,
+		}
+		if rec.HasPaired {
+			lastPaired, daysSince := rec.LastPaired, rec.DaysSince
+			jsonRec.LastPaired = &lastPaired
+			jsonRec.DaysSince = &daysSince
+		}
+		result = append(result, jsonRec)
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package output_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestRenderJSONToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	lastPaired := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(alice, bob)
+	recencyMatrix.RecordByDeveloper(alice, bob, lastPaired)
+
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: carol, DaysSince: -1},
+		{A: bob, B: git.Developer{}},
+	}
+
+	var result strings.Builder
+	err := output.RenderJSONToWriter(&result, matrix, recencyMatrix, developers, "least-recent", recommendations)
+	if err != nil {
+		t.Fatalf("RenderJSONToWriter failed: %v", err)
+	}
+
+	var decoded output.JSONResult
+	if err := json.Unmarshal([]byte(result.String()), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, result.String())
+	}
+
+	if decoded.SchemaVersion != output.JSONSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", output.JSONSchemaVersion, decoded.SchemaVersion)
+	}
+	if decoded.Strategy != "least-recent" {
+		t.Errorf("Expected strategy least-recent, got %q", decoded.Strategy)
+	}
+	if len(decoded.Developers) != 3 || decoded.Developers[0].Email != "alice@example.com" {
+		t.Errorf("Unexpected developers: %+v", decoded.Developers)
+	}
+	if len(decoded.Pairs) != 1 {
+		t.Fatalf("Expected only the one pair that paired, got %+v", decoded.Pairs)
+	}
+	if pair := decoded.Pairs[0]; pair.A != "alice@example.com" || pair.B != "bob@example.com" || pair.Count != 2 || !pair.LastPaired.Equal(lastPaired) {
+		t.Errorf("Unexpected pair: %+v", pair)
+	}
+	if decoded.Coverage.Paired != 1 || decoded.Coverage.Possible != 3 {
+		t.Errorf("Unexpected coverage: %+v", decoded.Coverage)
+	}
+	if len(decoded.Recommendations) != 2 || decoded.Recommendations[1].B != "" {
+		t.Errorf("Unexpected recommendations: %+v", decoded.Recommendations)
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+	recommendations := []recommend.Recommendation{{A: alice, B: bob}}
+
+	t.Run("slack wraps the message text", func(t *testing.T) {
+		payload, err := output.WebhookPayload("slack", pairing.NewMatrix(), pairing.NewRecencyMatrix(), developers, "least-paired", recommendations, output.Options{})
+		if err != nil {
+			t.Fatalf("WebhookPayload failed: %v", err)
+		}
+		var message struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(payload, &message); err != nil {
+			t.Fatalf("Payload is not valid JSON: %v", err)
+		}
+		if !strings.Contains(message.Text, "*Alice Smith* and *Bob Jones*") {
+			t.Errorf("Expected Slack text in payload, got %q", message.Text)
+		}
+	})
+
+	t.Run("json posts the result document", func(t *testing.T) {
+		payload, err := output.WebhookPayload("json", pairing.NewMatrix(), pairing.NewRecencyMatrix(), developers, "least-paired", recommendations, output.Options{})
+		if err != nil {
+			t.Fatalf("WebhookPayload failed: %v", err)
+		}
+		var decoded output.JSONResult
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("Payload is not valid JSON: %v", err)
+		}
+		if len(decoded.Recommendations) != 1 {
+			t.Errorf("Expected 1 recommendation in payload, got %d", len(decoded.Recommendations))
+		}
+	})
+
+	t.Run("json includes the commit sizes", func(t *testing.T) {
+		sizes := pairing.CommitSizes{Solo: 1, Pairs: 3}
+		payload, err := output.WebhookPayload("json", pairing.NewMatrix(), pairing.NewRecencyMatrix(), developers, "least-paired", recommendations, output.Options{CommitSizes: &sizes})
+		if err != nil {
+			t.Fatalf("WebhookPayload failed: %v", err)
+		}
+		var decoded output.JSONResult
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("Payload is not valid JSON: %v", err)
+		}
+		if want := (output.JSONCommitSizes{Solo: 1, Pairs: 3, SoloShare: 0.25, PairsShare: 0.75}); decoded.CommitSizes == nil || *decoded.CommitSizes != want {
+			t.Errorf("Expected commit sizes %+v, got %+v", want, decoded.CommitSizes)
+		}
+	})
+
+	t.Run("other formats are rejected", func(t *testing.T) {
+		if _, err := output.WebhookPayload("html", pairing.NewMatrix(), pairing.NewRecencyMatrix(), developers, "least-paired", recommendations, output.Options{}); err == nil {
+			t.Error("Expected error for html output")
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
+)
+
+// The phrases the narrative is built from. Each is a format string filled in with
+// names and counts that have already been put into words.
+const (
+	narrativeOpening       = "Over %s, %s paired across %s."
+	narrativeNoPairing     = "Over %s, none of the %s paired."
+	narrativeMostPaired    = "%s paired most (%s)."
+	narrativeCoverage      = "%d of the %d possible pairs have worked together."
+	narrativeNeverPaired   = "Never worked together (%s): %s."
+	narrativeAllPaired     = "Every pair has worked together."
+	narrativeLoners        = "%s paired with nobody."
+	narrativeSuggestions   = "Next, we suggest %s."
+	narrativeDefaultPeriod = "the period analyzed"
+)
+
+// maxNarrativeNames is the most pairs or developers a sentence lists by name
+// before summing up the rest
+const maxNarrativeNames = 5
+
+// NarrativeRenderer handles the "report" output: a short prose summary of the
+// pairing for readers who would rather not read a grid.
+//
+// A session is a day a pair worked together, so a pair who paired on three days
+// counts for three sessions.
+type NarrativeRenderer struct {
+	// Period describes the time analyzed, e.g. "the last 2 weeks"
+	Period string
+}
+
+// Render outputs the narrative to the console
+func (r *NarrativeRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderNarrativeToWriter(os.Stdout, r.Period, matrix, developers, recommendations)
+}
+
+// RenderNarrativeToWriter renders the narrative, one sentence per line, to the
+// provided io.Writer
+func RenderNarrativeToWriter(w io.Writer, period string, matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation) error {
+	_, err := io.WriteString(w, strings.Join(narrativeSentences(period, matrix, developers, recommendations), "\n")+"\n")
+	return err
+}
+
+// narrativeSentences builds the sentences of the narrative in order
+func narrativeSentences(period string, matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation) []string {
+	if period == "" {
+		period = narrativeDefaultPeriod
+	}
+
+	sessions, most := 0, 0
+	var mostPaired, neverPaired []string
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			pair := developers[i].DisplayName + " and " + developers[j].DisplayName
+			count := matrix.CountByDeveloper(developers[i], developers[j])
+			sessions += count
+			switch {
+			case count == 0:
+				neverPaired = append(neverPaired, pair)
+			case count > most:
+				most, mostPaired = count, []string{pair}
+			case count == most:
+				mostPaired = append(mostPaired, pair)
+			}
+		}
+	}
+
+	var paired, loners []string
+	for _, dev := range developers {
+		if hasPairedWithin(dev, developers, matrix) {
+			paired = append(paired, dev.DisplayName)
+		} else {
+			loners = append(loners, dev.DisplayName)
+		}
+	}
+
+	if sessions == 0 {
+		return []string{fmt.Sprintf(narrativeNoPairing, period, countOf(len(developers), "developer", "developers"))}
+	}
+
+	sentences := []string{fmt.Sprintf(narrativeOpening, period, countOf(len(paired), "developer", "developers"), countOf(sessions, "session", "sessions"))}
+
+	times := timesPhrase(most)
+	if len(mostPaired) > 1 {
+		times += " each"
+	}
+	sentences = append(sentences, fmt.Sprintf(narrativeMostPaired, englishList(mostPaired, ", and "), times))
+
+	coverage := stats.CalculateCoverage(developers, matrix)
+	sentences = append(sentences, fmt.Sprintf(narrativeCoverage, coverage.Paired, coverage.Possible))
+	if len(neverPaired) == 0 {
+		sentences = append(sentences, narrativeAllPaired)
+	} else {
+		sentences = append(sentences, fmt.Sprintf(narrativeNeverPaired, countOf(len(neverPaired), "pair", "pairs"), englishList(summarized(neverPaired, "pairs"), ", and ")))
+	}
+	if len(loners) > 0 {
+		sentences = append(sentences, fmt.Sprintf(narrativeLoners, englishList(summarized(loners, "developers"), " and ")))
+	}
+
+	if suggestions := suggestionPhrases(recommendations); len(suggestions) > 0 {
+		sentences = append(sentences, fmt.Sprintf(narrativeSuggestions, englishList(suggestions, ", and ")))
+	}
+	return sentences
+}
+
+// suggestionPhrases puts each recommendation into words, pairs first
+func suggestionPhrases(recommendations []recommend.Recommendation) []string {
+	var pairs, unpaired []string
+	for _, rec := range recommendations {
+		if len(rec.B.EmailAddresses) == 0 {
+			unpaired = append(unpaired, rec.A.DisplayName+" works alone")
+			continue
+		}
+		pairs = append(pairs, rec.A.DisplayName+" pairs with "+rec.B.DisplayName)
+	}
+	sort.Strings(unpaired)
+	return append(pairs, unpaired...)
+}
+
+// hasPairedWithin reports whether the developer has paired with any of the developers
+func hasPairedWithin(dev git.Developer, developers []git.Developer, matrix *pairing.Matrix) bool {
+	for _, other := range developers {
+		if other.CanonicalEmail() != dev.CanonicalEmail() && matrix.CountByDeveloper(dev, other) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// summarized keeps the first maxNarrativeNames items, replacing the rest with a
+// count such as "3 more pairs". A single item is never replaced.
+func summarized(items []string, plural string) []string {
+	if len(items) <= maxNarrativeNames+1 {
+		return items
+	}
+	return append(items[:maxNarrativeNames:maxNarrativeNames], fmt.Sprintf("%d more %s", len(items)-maxNarrativeNames, plural))
+}
+
+// englishList joins items as in "a, b and c", with the given separator before the
+// last item. Items that themselves contain "and" read better with ", and ".
+func englishList(items []string, last string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + last + items[len(items)-1]
+}
+
+// countOf formats a count with the singular or plural noun, e.g. "1 pair" or "3 pairs"
+func countOf(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// timesPhrase formats how many times something happened, e.g. "once" or "3 times"
+func timesPhrase(n int) string {
+	switch n {
+	case 1:
+		return "once"
+	case 2:
+		return "twice"
+	default:
+		return fmt.Sprintf("%d times", n)
+	}
+}
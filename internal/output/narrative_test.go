@@ -0,0 +1,98 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestRenderNarrativeToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	erin := git.NewDeveloper("Erin White <erin@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave, erin}
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pair := func(a, b git.Developer, count int) {
+		for i := 0; i < count; i++ {
+			matrix.AddByDeveloper(a, b)
+		}
+		recencyMatrix.RecordByDeveloper(a, b, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC))
+	}
+	pair(alice, bob, 5)
+	pair(carol, dave, 5)
+	pair(alice, carol, 2)
+	pair(bob, dave, 1)
+
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: dave},
+		{A: bob, B: carol},
+		{A: erin},
+	}
+
+	var result strings.Builder
+	if err := output.RenderNarrativeToWriter(&result, "the last 2 weeks", matrix, developers, recommendations); err != nil {
+		t.Fatalf("RenderNarrativeToWriter failed: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "narrative.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(result.String()), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if result.String() != string(expected) {
+		t.Errorf("Narrative does not match %s (run with -update to regenerate)\nExpected:\n%s\nGot:\n%s", golden, expected, result.String())
+	}
+}
+
+func TestRenderNarrativeToWriter_NoPairing(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+
+	var result strings.Builder
+	if err := output.RenderNarrativeToWriter(&result, "", pairing.NewMatrix(), developers, nil); err != nil {
+		t.Fatalf("RenderNarrativeToWriter failed: %v", err)
+	}
+	if want := "Over the period analyzed, none of the 2 developers paired.\n"; result.String() != want {
+		t.Errorf("Expected %q, got %q", want, result.String())
+	}
+}
+
+func TestRenderNarrativeToWriter_ManyNeverPaired(t *testing.T) {
+	var developers []git.Developer
+	for _, name := range []string{"Ann", "Ben", "Cat", "Dan", "Eve"} {
+		developers = append(developers, git.NewDeveloper(name+" <"+strings.ToLower(name)+"@example.com>"))
+	}
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(developers[0], developers[1])
+
+	var result strings.Builder
+	if err := output.RenderNarrativeToWriter(&result, "the last week", matrix, developers, nil); err != nil {
+		t.Fatalf("RenderNarrativeToWriter failed: %v", err)
+	}
+	want := "Never worked together (9 pairs): Ann and Cat, Ann and Dan, Ann and Eve, Ben and Cat, Ben and Dan, and 4 more pairs.\n"
+	if !strings.Contains(result.String(), want) {
+		t.Errorf("Expected the never-paired list to be cut short, got:\n%s", result.String())
+	}
+	if !strings.Contains(result.String(), "Cat, Dan and Eve paired with nobody.") {
+		t.Errorf("Expected the developers who haven't paired to be named, got:\n%s", result.String())
+	}
+}
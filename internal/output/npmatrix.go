@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// NPMatrixRenderer handles the "npmatrix" output: the bare matrix of counts as
+// whitespace-delimited text, for numpy.loadtxt and similar. Rows and columns are
+// the developers in order of email, which RenderNPLabelsToWriter writes out.
+type NPMatrixRenderer struct{}
+
+// Render outputs the matrix as whitespace-delimited text
+func (r *NPMatrixRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderNPMatrixToWriter(os.Stdout, matrix, developers)
+}
+
+// RenderNPMatrixToWriter renders a square matrix of integer counts, one row per
+// line with values separated by spaces, to the provided io.Writer. There are no
+// headers and the diagonal is 0.
+func RenderNPMatrixToWriter(w io.Writer, matrix *pairing.Matrix, developers []git.Developer) error {
+	var b strings.Builder
+	developers = sortedByEmail(developers)
+	for _, dev1 := range developers {
+		row := make([]string, 0, len(developers))
+		for _, dev2 := range developers {
+			count := 0
+			if dev1.CanonicalEmail() != dev2.CanonicalEmail() {
+				count = matrix.CountByDeveloper(dev1, dev2)
+			}
+			row = append(row, fmt.Sprint(count))
+		}
+		b.WriteString(strings.Join(row, " ") + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderNPLabelsToWriter renders the labels for RenderNPMatrixToWriter's rows and
+// columns, one line per row of the index and the developer's email separated by a
+// tab, to the provided io.Writer
+func RenderNPLabelsToWriter(w io.Writer, developers []git.Developer) error {
+	var b strings.Builder
+	for i, dev := range sortedByEmail(developers) {
+		fmt.Fprintf(&b, "%d\t%s\n", i, dev.CanonicalEmail())
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sortedByEmail returns a copy of the developers ordered by canonical email
+func sortedByEmail(developers []git.Developer) []git.Developer {
+	return slices.SortedFunc(slices.Values(developers), func(a, b git.Developer) int {
+		return strings.Compare(a.CanonicalEmail(), b.CanonicalEmail())
+	})
+}
@@ -0,0 +1,62 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+func TestRenderNPMatrixToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	// Out of order, to check rows and columns are sorted by email
+	developers := []git.Developer{carol, alice, bob}
+
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(bob, carol)
+
+	var grid strings.Builder
+	if err := output.RenderNPMatrixToWriter(&grid, matrix, developers); err != nil {
+		t.Fatalf("RenderNPMatrixToWriter failed: %v", err)
+	}
+	expected := "0 2 0\n" +
+		"2 0 1\n" +
+		"0 1 0\n"
+	if grid.String() != expected {
+		t.Errorf("Expected matrix:\n%q\nGot:\n%q", expected, grid.String())
+	}
+
+	var labels strings.Builder
+	if err := output.RenderNPLabelsToWriter(&labels, developers); err != nil {
+		t.Fatalf("RenderNPLabelsToWriter failed: %v", err)
+	}
+	expected = "0\talice@example.com\n" +
+		"1\tbob@example.com\n" +
+		"2\tcarol@example.com\n"
+	if labels.String() != expected {
+		t.Errorf("Expected labels:\n%q\nGot:\n%q", expected, labels.String())
+	}
+}
+
+func TestRenderNPMatrixToWriter_Weighted(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+
+	// Counts stay whole numbers when mob commits are weighted
+	matrix := pairing.NewMatrix()
+	matrix.AddWeighted(alice.CanonicalEmail(), bob.CanonicalEmail(), 0.5)
+
+	var grid strings.Builder
+	if err := output.RenderNPMatrixToWriter(&grid, matrix, []git.Developer{alice, bob}); err != nil {
+		t.Fatalf("RenderNPMatrixToWriter failed: %v", err)
+	}
+	if expected := "0 1\n1 0\n"; grid.String() != expected {
+		t.Errorf("Expected integer counts %q, got %q", expected, grid.String())
+	}
+}
@@ -8,15 +8,19 @@ package output
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/identity"
 	"github.com/gypsydave5/pairstair/internal/pairing"
 	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
 )
 
 // OutputRenderer provides a unified interface for different output formats
@@ -24,27 +28,157 @@ type OutputRenderer interface {
 	Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error
 }
 
+// RecencyUnit controls how the time since a pair last worked together is displayed
+type RecencyUnit string
+
+const (
+	Days  RecencyUnit = "days"
+	Weeks RecencyUnit = "weeks"
+)
+
+// DateStyle controls whether the time a pair last worked together is shown as a
+// time ago or as the date
+type DateStyle string
+
+const (
+	RelativeDates DateStyle = "relative"
+	AbsoluteDates DateStyle = "absolute"
+)
+
+// Options holds presentation settings shared by the renderers
+type Options struct {
+	RecencyUnit RecencyUnit
+	// DateStyle is how the last pairing is shown. The zero value means RelativeDates.
+	DateStyle DateStyle
+	// SubTeams maps developers' canonical emails to the sub-teams they belong to,
+	// shown as tags next to them in recommendations
+	SubTeams map[string][]string
+	// Theme is the color scheme for HTML output. The zero value means LightTheme.
+	Theme Theme
+	// Print optimizes HTML output for printing, in black and white with the matrix
+	// scaled to fit the page. It overrides the theme.
+	Print bool
+	// Totals adds a row and column to the matrix with each developer's total
+	// pairings, and the grand total in the corner
+	Totals bool
+	// RecencyCap is the most days since a pair last paired that is shown; longer
+	// is shown as the cap with a "+", e.g. "last paired 365+ days ago". Zero means
+	// no cap.
+	RecencyCap int
+	// GroupBySubTeam orders the matrix by sub-team (see GroupBySubTeam), with a
+	// gap or a thicker border between the sub-teams
+	GroupBySubTeam bool
+	// Roles maps developers' canonical emails to their roles on the team, shown in
+	// a column of the legend. Without any roles there's no column.
+	Roles map[string]string
+	// MatrixStyle is what the CLI matrix shows for each pair. The zero value means
+	// CountStyle. RecencyBandsStyle reads when each pair last paired from Recency,
+	// measured back from Now.
+	MatrixStyle MatrixStyle
+	Recency     *pairing.RecencyMatrix
+	Now         time.Time
+	// ASCII draws the recency bands with plain ASCII characters rather than Unicode
+	ASCII bool
+	// SkippedBecause is why there are no recommendations, e.g. "not enough active
+	// developers". Without a reason, it's because there are too many developers.
+	SkippedBecause string
+	// Reviews counts the days each pair worked together through Reviewed-by
+	// trailers. If set, it's shown in the CLI as a separate matrix after the pairing.
+	Reviews *pairing.Matrix
+	// Transpose swaps the roles of the rows and columns of the matrix, so each cell
+	// is the column developer's with the row developer rather than the other way round
+	Transpose bool
+	// CommitSizes counts the commits worked on solo, in pairs and in mobs. If set,
+	// the shares of each are shown in a stats section after the matrix.
+	CommitSizes *pairing.CommitSizes
+	// Strategy is the strategy the recommendations were made with, for the HTML
+	// output, whose functions aren't given it. Render and RenderToWriter fill it
+	// in. The zero value means least-paired.
+	Strategy string
+	// Algorithm is how the recommendations were matched, shown in their heading.
+	// The zero value leaves it out.
+	Algorithm recommend.Algorithm
+}
+
+// cell returns the developers a matrix cell is for, as the row and column developers
+// or swapped if the matrix is transposed
+func (o Options) cell(row, column git.Developer) (git.Developer, git.Developer) {
+	if o.Transpose {
+		return column, row
+	}
+	return row, column
+}
+
+// skippedMessage explains why there are no recommendations
+func (o Options) skippedMessage() string {
+	if o.SkippedBecause != "" {
+		return "Skipping pairing recommendations - " + o.SkippedBecause
+	}
+	return "Skipping pairing recommendations - too many developers"
+}
+
+// commitSizesSummary describes the shares of commits worked on solo, in pairs
+// and in mobs, e.g. "25% solo, 60% pairs, 15% mobs (of 20 commits)"
+func commitSizesSummary(sizes pairing.CommitSizes) string {
+	return fmt.Sprintf("%.0f%% solo, %.0f%% pairs, %.0f%% mobs (of %d commits)",
+		100*sizes.Share(sizes.Solo), 100*sizes.Share(sizes.Pairs), 100*sizes.Share(sizes.Mobs), sizes.Total())
+}
+
+// subTeamTags returns the developer's sub-team tags, e.g. " [frontend] [backend]",
+// or an empty string if they aren't in any sub-team
+func (o Options) subTeamTags(dev git.Developer) string {
+	var tags strings.Builder
+	for _, subTeam := range o.SubTeams[dev.CanonicalEmail()] {
+		tags.WriteString(" [" + subTeam + "]")
+	}
+	return tags.String()
+}
+
+// hasRoles reports whether any developer has a role to show in the legend
+func (o Options) hasRoles() bool {
+	for _, role := range o.Roles {
+		if role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// roleTag returns the developer's role in brackets, e.g. "[Lead]", or an empty
+// string if they don't have one
+func (o Options) roleTag(dev git.Developer) string {
+	if role := o.Roles[dev.CanonicalEmail()]; role != "" {
+		return "[" + role + "]"
+	}
+	return ""
+}
+
 // CLIRenderer handles console output
-type CLIRenderer struct{}
+type CLIRenderer struct {
+	Options Options
+}
 
 // HTMLRenderer handles HTML output
 type HTMLRenderer struct {
 	OpenInBrowser bool
+	Options       Options
 }
 
 // Render outputs the matrix and recommendations to the console
 func (r *CLIRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
-	PrintMatrixCLI(matrix, developers)
-	PrintRecommendationsCLI(recommendations, strategy)
+	PrintMatrixCLIWithOptions(matrix, developers, r.Options)
+	PrintRecommendationsCLIWithOptions(recommendations, strategy, r.Options)
 	return nil
 }
 
 // Render outputs the matrix and recommendations as HTML
 func (r *HTMLRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	options := r.Options
+	options.Strategy = strategy
 	if r.OpenInBrowser {
-		return RenderHTMLAndOpen(matrix, developers, recommendations)
+		return RenderHTMLAndOpenWithOptions(matrix, developers, recommendations, options)
 	} else {
-		return RenderHTMLToWriter(os.Stdout, matrix, developers, recommendations)
+		return RenderHTMLToWriterWithOptions(os.Stdout, matrix, developers, recommendations, options)
 	}
 }
 
@@ -56,87 +190,564 @@ func NewRenderer(outputFormat string) OutputRenderer {
 
 // NewRendererWithOpen creates the appropriate renderer based on output format and open behavior
 func NewRendererWithOpen(outputFormat string, openInBrowser bool) OutputRenderer {
+	return NewRendererWithOptions(outputFormat, openInBrowser, Options{})
+}
+
+// NewRendererWithOptions creates the appropriate renderer based on output format,
+// open behavior and presentation options
+func NewRendererWithOptions(outputFormat string, openInBrowser bool, options Options) OutputRenderer {
 	switch outputFormat {
 	case "html":
-		return &HTMLRenderer{OpenInBrowser: openInBrowser}
+		return &HTMLRenderer{OpenInBrowser: openInBrowser, Options: options}
+	case "slack":
+		return &SlackRenderer{Options: options}
+	case "json":
+		return &JSONRenderer{Options: options}
+	case "stair":
+		return &StairRenderer{Options: options}
+	case "confluence":
+		return &ConfluenceRenderer{Options: options}
+	case "tsv":
+		return &TSVRenderer{}
+	case "report":
+		return &NarrativeRenderer{}
+	case "npmatrix":
+		return &NPMatrixRenderer{}
 	default:
-		return &CLIRenderer{}
+		return &CLIRenderer{Options: options}
+	}
+}
+
+// FileFormats are the output formats RenderToWriter can write, e.g. to a file
+var FileFormats = []string{"html", "json", "slack", "confluence", "tsv", "npmatrix"}
+
+// RenderToWriter renders one of the FileFormats to the provided io.Writer, so that
+// several formats can be rendered from the same results
+func RenderToWriter(w io.Writer, outputFormat string, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) error {
+	switch outputFormat {
+	case "html":
+		options.Strategy = strategy
+		return RenderHTMLToWriterWithOptions(w, matrix, developers, recommendations, options)
+	case "json":
+		return RenderJSONToWriterWithOptions(w, matrix, recencyMatrix, developers, strategy, recommendations, options)
+	case "slack":
+		return RenderSlackToWriter(w, matrix, developers, strategy, recommendations, options)
+	case "confluence":
+		return RenderConfluenceToWriter(w, matrix, developers, strategy, recommendations, options)
+	case "tsv":
+		return RenderTSVToWriter(w, matrix, developers)
+	case "npmatrix":
+		return RenderNPMatrixToWriter(w, matrix, developers)
+	default:
+		return fmt.Errorf("output %s can't be written to a file (expected one of %s)", outputFormat, strings.Join(FileFormats, ", "))
+	}
+}
+
+// ParseRecencyUnit converts a recency unit name to a RecencyUnit
+func ParseRecencyUnit(unit string) (RecencyUnit, error) {
+	switch RecencyUnit(unit) {
+	case Days, Weeks:
+		return RecencyUnit(unit), nil
+	default:
+		return "", fmt.Errorf("invalid recency unit: %s (expected 'days' or 'weeks')", unit)
+	}
+}
+
+// ParseDateStyle converts a date style name to a DateStyle
+func ParseDateStyle(style string) (DateStyle, error) {
+	switch DateStyle(style) {
+	case RelativeDates, AbsoluteDates:
+		return DateStyle(style), nil
+	default:
+		return "", fmt.Errorf("invalid date style: %s (expected 'relative' or 'absolute')", style)
 	}
 }
 
 // PrintMatrixCLI prints the matrix and legend to the CLI
 func PrintMatrixCLI(matrix *pairing.Matrix, developers []git.Developer) {
-	fmt.Println("Legend:")
+	PrintMatrixCLIWithOptions(matrix, developers, Options{})
+}
+
+// PrintMatrixCLIWithOptions prints the matrix and legend to the CLI using the given presentation options
+func PrintMatrixCLIWithOptions(matrix *pairing.Matrix, developers []git.Developer, options Options) {
+	groups := options.matrixGroups(developers)
+	developers, groupStarts := flatten(groups)
+
+	// The role column is as wide as the longest role, or absent if there are none
+	roleWidth := 0
 	for _, dev := range developers {
-		fmt.Printf("  %-6s = %-20s %s\n", dev.AbbreviatedName, dev.DisplayName, dev.CanonicalEmail())
+		roleWidth = max(roleWidth, len([]rune(options.roleTag(dev))))
+	}
+
+	fmt.Println("Legend:")
+	for _, group := range groups {
+		if options.grouped() {
+			fmt.Printf("  %s:\n", group.label())
+		}
+		for _, dev := range group.Developers {
+			if roleWidth > 0 {
+				fmt.Printf("  %-6s = %-20s %-*s %s\n", dev.AbbreviatedName, dev.DisplayName, roleWidth, options.roleTag(dev), dev.CanonicalEmail())
+				continue
+			}
+			fmt.Printf("  %-6s = %-20s %s\n", dev.AbbreviatedName, dev.DisplayName, dev.CanonicalEmail())
+		}
 	}
 	fmt.Println()
+	bands := options.MatrixStyle == RecencyBandsStyle
+	if bands {
+		fmt.Println(recencyBandsKey(options.ASCII))
+		fmt.Println()
+	}
 
-	fmt.Printf("%-8s", "")
+	// Columns are wide enough for the longest label
+	width := 8
 	for _, dev := range developers {
-		fmt.Printf("%-8s", dev.AbbreviatedName)
+		width = max(width, len([]rune(dev.AbbreviatedName))+2)
+	}
+
+	printTotal := func(total float64) {
+		if matrix.Weighted() {
+			fmt.Printf("%-*.2f", width, total)
+		} else {
+			fmt.Printf("%-*d", width, int(total))
+		}
+	}
+	rowTotals, grandTotal := matrixTotals(matrix, developers, matrix.Weighted())
+
+	// Sub-teams are separated by a blank column and row
+	gap := func(i int) {
+		if groupStarts[i] {
+			fmt.Print("  ")
+		}
+	}
+
+	fmt.Printf("%-*s", width, "")
+	for i, dev := range developers {
+		gap(i)
+		fmt.Printf("%-*s", width, dev.AbbreviatedName)
+	}
+	if options.Totals {
+		fmt.Printf("%-*s", width, "Total")
 	}
 	fmt.Println()
-	for _, dev1 := range developers {
-		fmt.Printf("%-8s", dev1.AbbreviatedName)
-		for _, dev2 := range developers {
-			if dev1.CanonicalEmail() == dev2.CanonicalEmail() {
-				fmt.Printf("%-8s", "-")
+	for i, dev1 := range developers {
+		if groupStarts[i] {
+			fmt.Println()
+		}
+		fmt.Printf("%-*s", width, dev1.AbbreviatedName)
+		for j, column := range developers {
+			gap(j)
+			if dev1.CanonicalEmail() == column.CanonicalEmail() {
+				fmt.Printf("%-*s", width, "-")
+				continue
+			}
+			first, second := options.cell(dev1, column)
+			if bands {
+				last, paired := options.Recency.LastPairedByDeveloper(first, second)
+				fmt.Printf("%-*s", width, RecencyBandFor(last, paired, options.Now).Symbol(options.ASCII))
 				continue
 			}
-			fmt.Printf("%-8d", matrix.Count(dev1.CanonicalEmail(), dev2.CanonicalEmail()))
+			if matrix.Weighted() {
+				fmt.Printf("%-*.2f", width, matrix.WeightByDeveloper(first, second))
+				continue
+			}
+			fmt.Printf("%-*d", width, matrix.CountByDeveloper(first, second))
+		}
+		if options.Totals {
+			printTotal(rowTotals[i])
+		}
+		fmt.Println()
+	}
+	if options.Totals {
+		// The matrix is symmetric, so the column totals are the row totals
+		fmt.Printf("%-*s", width, "Total")
+		for i, total := range rowTotals {
+			gap(i)
+			printTotal(total)
+		}
+		printTotal(grandTotal)
+		fmt.Println()
+	}
+
+	if options.Reviews != nil {
+		fmt.Println()
+		fmt.Println("Reviews (Reviewed-by trailers, not counted as pairing):")
+		fmt.Printf("%-*s", width, "")
+		for _, dev := range developers {
+			fmt.Printf("%-*s", width, dev.AbbreviatedName)
 		}
 		fmt.Println()
+		for _, dev1 := range developers {
+			fmt.Printf("%-*s", width, dev1.AbbreviatedName)
+			for _, column := range developers {
+				if dev1.CanonicalEmail() == column.CanonicalEmail() {
+					fmt.Printf("%-*s", width, "-")
+					continue
+				}
+				fmt.Printf("%-*d", width, options.Reviews.CountByDeveloper(options.cell(dev1, column)))
+			}
+			fmt.Println()
+		}
 	}
+
+	if options.CommitSizes != nil {
+		fmt.Println()
+		fmt.Println("Commit sizes: " + commitSizesSummary(*options.CommitSizes))
+	}
+}
+
+// formatCell returns a pair's cell in the matrix: the count, or the weight to two
+// decimal places if the matrix is weighted (see -mob-weight)
+func formatCell(matrix *pairing.Matrix, a, b git.Developer) string {
+	if matrix.Weighted() {
+		return fmt.Sprintf("%.2f", matrix.WeightByDeveloper(a, b))
+	}
+	return fmt.Sprint(matrix.CountByDeveloper(a, b))
+}
+
+// formatTotal returns a total from matrixTotals in the same way as formatCell
+func formatTotal(matrix *pairing.Matrix, total float64) string {
+	if matrix.Weighted() {
+		return fmt.Sprintf("%.2f", total)
+	}
+	return fmt.Sprint(int(total))
+}
+
+// matrixTotals sums each developer's row of the matrix, using the weights rather
+// than the counts if weighted is set, and the total of all the rows. Each pair
+// appears in two cells, so the grand total is twice the pairings of all the pairs.
+func matrixTotals(matrix *pairing.Matrix, developers []git.Developer, weighted bool) ([]float64, float64) {
+	rowTotals := make([]float64, len(developers))
+	var grandTotal float64
+	for i, dev1 := range developers {
+		for _, dev2 := range developers {
+			if weighted {
+				rowTotals[i] += matrix.Weight(dev1.CanonicalEmail(), dev2.CanonicalEmail())
+			} else {
+				rowTotals[i] += float64(matrix.Count(dev1.CanonicalEmail(), dev2.CanonicalEmail()))
+			}
+		}
+		grandTotal += rowTotals[i]
+	}
+	return rowTotals, grandTotal
 }
 
 // PrintRecommendationsCLI prints recommendations to the CLI
 func PrintRecommendationsCLI(recommendations []recommend.Recommendation, strategy string) {
+	PrintRecommendationsCLIWithOptions(recommendations, strategy, Options{})
+}
+
+// PrintRecommendationsCLIWithOptions prints recommendations to the CLI using the given presentation options
+func PrintRecommendationsCLIWithOptions(recommendations []recommend.Recommendation, strategy string, options Options) {
 	fmt.Println()
 	if len(recommendations) == 0 {
-		fmt.Println("Skipping pairing recommendations - too many developers (> 20)")
+		fmt.Println(options.skippedMessage())
 		return
 	}
 
-	switch strategy {
-	case "least-recent":
-		fmt.Println("Pairing Recommendations (least recent collaborations first):")
-	default: // least-paired
-		fmt.Println("Pairing Recommendations (least-paired overall, optimal matching):")
-	}
+	fmt.Println(recommendationsHeading(strategy, options.Algorithm) + ":")
 
+	primary := recommend.Strategy(strategy).Primary()
 	for _, rec := range recommendations {
+		a := rec.A.AbbreviatedName + options.subTeamTags(rec.A)
 		if len(rec.B.EmailAddresses) == 0 {
-			fmt.Printf("  %-6s (unpaired)\n", rec.A.AbbreviatedName)
+			fmt.Printf("  %-6s (unpaired)\n", a)
 		} else {
-			if strategy == "least-recent" {
-				if rec.HasPaired {
-					if rec.DaysSince == 0 {
-						fmt.Printf("  %-6s <-> %-6s : last paired today\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName)
-					} else if rec.DaysSince == 1 {
-						fmt.Printf("  %-6s <-> %-6s : last paired 1 day ago\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName)
-					} else {
-						fmt.Printf("  %-6s <-> %-6s : last paired %d days ago\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName, rec.DaysSince)
-					}
-				} else {
-					fmt.Printf("  %-6s <-> %-6s : never paired\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName)
-				}
+			b := rec.B.AbbreviatedName + options.subTeamTags(rec.B)
+			if primary == recommend.LeastRecent || primary == recommend.Coverage {
+				fmt.Printf("  %-6s <-> %-6s : %s%s\n", a, b, FormatLastPaired(rec, options), recentTag(rec))
+			} else {
+				fmt.Printf("  %-6s <-> %-6s : %d times%s\n", a, b, rec.Count, recentTag(rec))
+			}
+		}
+	}
+}
+
+// recentTag flags a recommendation for a pair who paired recently, so that it can be skipped
+func recentTag(rec recommend.Recommendation) string {
+	if rec.Recent {
+		return " (recently paired)"
+	}
+	return ""
+}
+
+// FormatLastPaired describes when the recommended pair last worked together, e.g.
+// "last paired 3 days ago", or "last paired 2024-05-12" with AbsoluteDates
+func FormatLastPaired(rec recommend.Recommendation, options Options) string {
+	switch {
+	case !rec.HasPaired:
+		return "never paired"
+	case options.DateStyle == AbsoluteDates:
+		return "last paired " + rec.LastPaired.Format("2006-01-02")
+	default:
+		return "last paired " + FormatCappedRecency(rec.DaysSince, options.RecencyUnit, options.RecencyCap)
+	}
+}
+
+// FormatCappedRecency is FormatRecency with the days clamped to the cap, adding a
+// "+" when clamped, e.g. "365+ days ago". A cap of zero or less means no cap. In
+// weeks, the cap is rounded down to whole weeks, and is at least one week.
+func FormatCappedRecency(daysSince int, unit RecencyUnit, capDays int) string {
+	if capDays <= 0 {
+		return FormatRecency(daysSince, unit)
+	}
+	if unit == Weeks {
+		capWeeks := max(capDays/7, 1)
+		if daysSince/7 <= capWeeks {
+			return FormatRecency(daysSince, unit)
+		}
+		return fmt.Sprintf("%d+ weeks ago", capWeeks)
+	}
+	if daysSince <= capDays {
+		return FormatRecency(daysSince, unit)
+	}
+	return fmt.Sprintf("%d+ days ago", capDays)
+}
+
+// FormatRecency describes how long ago a pair last worked together, e.g. "3 days ago".
+// With the Weeks unit the days are shown as whole weeks, rounding down, so anything
+// under seven days is "this week".
+func FormatRecency(daysSince int, unit RecencyUnit) string {
+	if unit == Weeks {
+		return formatWeeksSince(daysSince / 7)
+	}
+	switch daysSince {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", daysSince)
+	}
+}
+
+// formatWeeksSince describes a number of whole weeks ago
+func formatWeeksSince(weeks int) string {
+	switch weeks {
+	case 0:
+		return "this week"
+	case 1:
+		return "1 week ago"
+	default:
+		return fmt.Sprintf("%d weeks ago", weeks)
+	}
+}
+
+// PrintPlanCLI prints a day-by-day pairing plan to the CLI
+func PrintPlanCLI(plan []recommend.PlanDay, strategy string) {
+	fmt.Printf("Pairing Plan (next %d working days, %s):\n", len(plan), strategy)
+	for _, day := range plan {
+		fmt.Println()
+		fmt.Println(day.Date.Format("Mon 2006-01-02"))
+		for _, rec := range day.Recommendations {
+			if len(rec.B.EmailAddresses) == 0 {
+				fmt.Printf("  %-6s (unpaired)\n", rec.A.AbbreviatedName)
 			} else {
-				fmt.Printf("  %-6s <-> %-6s : %d times\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName, rec.Count)
+				fmt.Printf("  %-6s <-> %-6s\n", rec.A.AbbreviatedName, rec.B.AbbreviatedName)
 			}
 		}
 	}
 }
 
+// PrintLoneWolvesCLI prints developers who committed alone but never paired
+func PrintLoneWolvesCLI(loneWolves []stats.LoneWolf) {
+	fmt.Println("Lone Wolves (solo commits but no pairing):")
+	if len(loneWolves) == 0 {
+		fmt.Println("  None - everyone who committed also paired")
+		return
+	}
+	for _, lw := range loneWolves {
+		fmt.Printf("  %-6s %-20s %d solo commits\n", lw.Developer.AbbreviatedName, lw.Developer.DisplayName, lw.SoloCommits)
+	}
+}
+
+// PrintLastPairingsCLI prints when each developer last paired with anyone
+func PrintLastPairingsCLI(lastPairings []stats.LastPairing) {
+	fmt.Println("Last Paired:")
+	for _, lp := range lastPairings {
+		if !lp.HasPaired {
+			fmt.Printf("  %-6s %-20s never\n", lp.Developer.AbbreviatedName, lp.Developer.DisplayName)
+			continue
+		}
+		fmt.Printf("  %-6s %-20s %s with %s\n", lp.Developer.AbbreviatedName, lp.Developer.DisplayName, lp.Date.Format("2006-01-02"), lp.Partner.AbbreviatedName)
+	}
+}
+
+// PrintNameVariantsCLI prints the emails committed under more than one display name
+func PrintNameVariantsCLI(variants []identity.NameVariant) {
+	fmt.Println("Name Variants (emails committed under more than one name, most used first):")
+	if len(variants) == 0 {
+		fmt.Println("  Every email is committed under a single name")
+	}
+	for _, v := range variants {
+		names := make([]string, len(v.Names))
+		for i, n := range v.Names {
+			names[i] = fmt.Sprintf("%q (%d)", n.Name, n.Commits)
+		}
+		fmt.Printf("  %s: %s\n", v.Email, strings.Join(names, ", "))
+	}
+}
+
+// PrintAttributionsCLI prints how each pair's commits were attributed, most one-sided first
+func PrintAttributionsCLI(attributions []stats.Attribution) {
+	fmt.Println("Attribution (commits authored with the other as co-author, most one-sided first):")
+	if len(attributions) == 0 {
+		fmt.Println("  No co-authored commits in this window")
+	}
+	for _, a := range attributions {
+		fmt.Printf("  %s -> %s: %d, %s -> %s: %d  (%.0f%% authored by %s)\n",
+			a.A.AbbreviatedName, a.B.AbbreviatedName, a.AAuthored,
+			a.B.AbbreviatedName, a.A.AbbreviatedName, a.BAuthored,
+			a.AuthorShare()*100, a.A.AbbreviatedName)
+	}
+}
+
+// PrintAttributionMatrixCLI prints how many commits each developer authored with
+// each other as co-author, with the authors down the side, or across the top if
+// transposed
+func PrintAttributionMatrixCLI(matrix *pairing.Matrix, developers []git.Developer, transpose bool) {
+	if transpose {
+		fmt.Println("Attribution Matrix (columns authored with rows as co-author):")
+	} else {
+		fmt.Println("Attribution Matrix (rows authored with columns as co-author):")
+	}
+	options := Options{Transpose: transpose}
+
+	width := 8
+	for _, dev := range developers {
+		width = max(width, len([]rune(dev.AbbreviatedName))+2)
+	}
+	fmt.Printf("%-*s", width, "")
+	for _, dev := range developers {
+		fmt.Printf("%-*s", width, dev.AbbreviatedName)
+	}
+	fmt.Println()
+	for _, row := range developers {
+		fmt.Printf("%-*s", width, row.AbbreviatedName)
+		for _, column := range developers {
+			if row.CanonicalEmail() == column.CanonicalEmail() {
+				fmt.Printf("%-*s", width, "-")
+				continue
+			}
+			fmt.Printf("%-*d", width, matrix.AttributionsByDeveloper(options.cell(row, column)))
+		}
+		fmt.Println()
+	}
+}
+
+// PrintMissingTrailersCLI prints how many of each author's commits had no
+// co-authors, with the fraction across all of them
+func PrintMissingTrailersCLI(missingTrailers []stats.MissingTrailer) {
+	fmt.Println("Missing Trailers (commits with no co-authors, highest share first):")
+	if len(missingTrailers) == 0 {
+		fmt.Println("  No commits in this window")
+		return
+	}
+	var commits, missing int
+	for _, m := range missingTrailers {
+		fmt.Printf("  %-6s %-20s %d of %d commits (%.0f%%)\n", m.Developer.AbbreviatedName, m.Developer.DisplayName, m.Missing, m.Commits, m.Fraction()*100)
+		commits += m.Commits
+		missing += m.Missing
+	}
+	fmt.Printf("Overall: %d of %d commits (%.0f%%) have no co-authors\n", missing, commits, float64(missing)/float64(commits)*100)
+}
+
+// PrintNewcomersCLI prints the developers who paired for the first time in the window
+func PrintNewcomersCLI(newcomers []stats.Newcomer) {
+	fmt.Println("Newcomers (first paired in this window):")
+	if len(newcomers) == 0 {
+		fmt.Println("  None - everyone who paired had paired before")
+		return
+	}
+	for _, n := range newcomers {
+		fmt.Printf("  %-6s %-20s first paired %s\n", n.Developer.AbbreviatedName, n.Developer.DisplayName, n.FirstPaired.Format("2006-01-02"))
+	}
+}
+
+// PrintStaleDevelopersCLI prints how long it has been since each developer last
+// paired with anyone, most overdue first, marking those over the threshold with a "!"
+func PrintStaleDevelopersCLI(staleDevelopers []stats.StaleDeveloper, threshold int) {
+	fmt.Printf("Stale Developers (days since last pairing, ! over %d days):\n", threshold)
+	for _, sd := range staleDevelopers {
+		flag := " "
+		if sd.Stale {
+			flag = "!"
+		}
+		if !sd.HasPaired {
+			fmt.Printf("  %s %-6s %-20s never paired\n", flag, sd.Developer.AbbreviatedName, sd.Developer.DisplayName)
+			continue
+		}
+		fmt.Printf("  %s %-6s %-20s %s (%s with %s)\n", flag, sd.Developer.AbbreviatedName, sd.Developer.DisplayName, FormatRecency(sd.DaysSince, Days), sd.Date.Format("2006-01-02"), sd.Partner.AbbreviatedName)
+	}
+}
+
+// PrintPairingDebtsCLI prints each developer's pairing debt, highest first
+func PrintPairingDebtsCLI(debts []stats.PairingDebt) {
+	fmt.Println("Pairing Debt (highest first):")
+	for _, debt := range debts {
+		fmt.Printf("  %-6s %-20s %5.2f  (never paired with %d)\n", debt.Developer.AbbreviatedName, debt.Developer.DisplayName, debt.Score, debt.NeverPaired)
+	}
+}
+
+// PrintSuggestionsPerPersonCLI prints each developer's best partner, whatever the
+// other developers' suggestions
+func PrintSuggestionsPerPersonCLI(suggestions []recommend.Recommendation) {
+	fmt.Println("Suggestions Per Person (each developer's best next partner):")
+	for _, s := range suggestions {
+		switch {
+		case len(s.B.EmailAddresses) == 0:
+			fmt.Printf("  %-6s %-20s no partner available\n", s.A.AbbreviatedName, s.A.DisplayName)
+		case !s.HasPaired:
+			fmt.Printf("  %-6s %-20s -> %s (never paired)\n", s.A.AbbreviatedName, s.A.DisplayName, s.B.DisplayName)
+		default:
+			fmt.Printf("  %-6s %-20s -> %s (paired %d times, last %s)\n", s.A.AbbreviatedName, s.A.DisplayName, s.B.DisplayName, s.Count, FormatRecency(s.DaysSince, Days))
+		}
+	}
+}
+
+// recommendationsHeading describes the strategy used to generate recommendations,
+// and the matching algorithm if it's known
+func recommendationsHeading(strategy string, algorithm recommend.Algorithm) string {
+	components := recommend.Strategy(strategy).Components()
+
+	var heading string
+	switch recommend.Strategy(strategy).Primary() {
+	case recommend.LeastRecent:
+		heading = "Pairing Recommendations (least recent collaborations first"
+	case recommend.Coverage:
+		heading = "Pairing Recommendations (most new pairs"
+	default: // least-paired
+		heading = "Pairing Recommendations (least-paired overall"
+	}
+	if algorithm == recommend.AlgorithmGreedy || algorithm == recommend.AlgorithmOptimal {
+		heading += ", " + string(algorithm) + " matching"
+	}
+
+	if len(components) > 1 {
+		var tieBreakers []string
+		for _, c := range components[1:] {
+			tieBreakers = append(tieBreakers, string(c))
+		}
+		heading += ", ties broken by " + strings.Join(tieBreakers, " then ")
+	}
+	return heading + ")"
+}
+
 // RenderHTMLAndOpen renders HTML output and opens it in the default browser
 func RenderHTMLAndOpen(matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation) error {
+	return RenderHTMLAndOpenWithOptions(matrix, developers, recommendations, Options{})
+}
+
+// RenderHTMLAndOpenWithOptions renders HTML output with the given options and opens it
+// in the default browser
+func RenderHTMLAndOpenWithOptions(matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation, options Options) error {
 	tmpfile, err := os.CreateTemp("", "pairstair-*.html")
 	if err != nil {
 		return err
 	}
 	defer tmpfile.Close()
 
-	err = RenderHTMLToWriter(tmpfile, matrix, developers, recommendations)
+	err = RenderHTMLToWriterWithOptions(tmpfile, matrix, developers, recommendations, options)
 	if err != nil {
 		return err
 	}
@@ -148,63 +759,130 @@ func RenderHTMLAndOpen(matrix *pairing.Matrix, developers []git.Developer, recom
 // RenderHTMLToWriter renders HTML output to the provided io.Writer
 // This is the testable version of HTML rendering that can write to any Writer
 func RenderHTMLToWriter(w io.Writer, matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation) error {
-	html := renderHTML(matrix, developers, recommendations)
+	return RenderHTMLToWriterWithOptions(w, matrix, developers, recommendations, Options{})
+}
+
+// RenderHTMLToWriterWithOptions renders HTML output with the given options to the provided io.Writer
+func RenderHTMLToWriterWithOptions(w io.Writer, matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation, options Options) error {
+	html := renderHTML(matrix, developers, recommendations, options)
 	_, err := w.Write([]byte(html))
 	return err
 }
 
-// renderHTML generates HTML output for the matrix and recommendations
-func renderHTML(matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation) string {
+// renderHTML generates HTML output for the matrix and recommendations.
+// The page is static, with no scripts, so it can be saved or printed as is.
+func renderHTML(matrix *pairing.Matrix, developers []git.Developer, recommendations []recommend.Recommendation, options Options) string {
+	theme := options.Theme
+	if options.Print {
+		theme = LightTheme
+	}
+	colors := theme.palette()
+	groups := options.matrixGroups(developers)
+	developers, groupStarts := flatten(groups)
 	var b strings.Builder
 	b.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Pair Stair</title>")
-	b.WriteString(`<style>
-body { font-family: sans-serif; margin: 2em; }
+	fmt.Fprintf(&b, `<style>
+body { font-family: sans-serif; margin: 2em; background: %s; color: %s; }
 table { border-collapse: collapse; }
-th, td { border: 1px solid #ccc; padding: 0.5em 1em; text-align: center; }
-th { background: #eee; }
+th, td { border: 1px solid %s; padding: 0.5em 1em; text-align: center; }
+th { background: %s; }
 .legend-table { margin-bottom: 2em; }
 .recommend { margin-top: 2em; }
-</style></head><body>`)
+td.group-start, th.group-start { border-left: 3px solid %s; }
+tr.group-start > * { border-top: 3px solid %s; }
+</style>`, colors.background, colors.text, colors.border, colors.header, colors.text, colors.text)
+	if options.Print {
+		b.WriteString(printCSS(len(developers)))
+	}
+	b.WriteString("</head><body>")
 	b.WriteString("<h1>Pair Stair Matrix</h1>")
 
 	// Legend
-	b.WriteString("<h2>Legend</h2><table class=\"legend-table\"><tr><th>Initials</th><th>Name</th><th>Email</th></tr>")
-	for _, dev := range developers {
-		b.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td></tr>", dev.AbbreviatedName, dev.DisplayName, dev.CanonicalEmail()))
+	roles := options.hasRoles()
+	columns := 3
+	b.WriteString("<h2>Legend</h2><table class=\"legend-table\"><tr><th>Initials</th><th>Name</th>")
+	if roles {
+		columns++
+		b.WriteString("<th>Role</th>")
+	}
+	b.WriteString("<th>Email</th></tr>")
+	for _, group := range groups {
+		if options.grouped() {
+			b.WriteString(fmt.Sprintf("<tr><th colspan=\"%d\">%s</th></tr>", columns, html.EscapeString(group.label())))
+		}
+		for _, dev := range group.Developers {
+			b.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td>", dev.AbbreviatedName, dev.DisplayName))
+			if roles {
+				b.WriteString(fmt.Sprintf("<td>%s</td>", html.EscapeString(options.Roles[dev.CanonicalEmail()])))
+			}
+			b.WriteString(fmt.Sprintf("<td>%s</td></tr>", dev.CanonicalEmail()))
+		}
 	}
 	b.WriteString("</table>")
 
-	// Matrix
+	// Matrix, with a thicker border before each sub-team when grouped
+	class := func(i int) string {
+		if groupStarts[i] {
+			return ` class="group-start"`
+		}
+		return ""
+	}
 	b.WriteString("<h2>Pair Matrix</h2><table><tr><th></th>")
-	for _, dev := range developers {
-		b.WriteString(fmt.Sprintf("<th>%s</th>", dev.AbbreviatedName))
+	for i, dev := range developers {
+		b.WriteString(fmt.Sprintf("<th%s>%s</th>", class(i), dev.AbbreviatedName))
+	}
+	if options.Totals {
+		b.WriteString("<th>Total</th>")
 	}
 	b.WriteString("</tr>")
-	for _, dev1 := range developers {
-		b.WriteString(fmt.Sprintf("<tr><th>%s</th>", dev1.AbbreviatedName))
-		for _, dev2 := range developers {
+	rowTotals, grandTotal := matrixTotals(matrix, developers, matrix.Weighted())
+	for i, dev1 := range developers {
+		b.WriteString(fmt.Sprintf("<tr%s><th>%s</th>", class(i), dev1.AbbreviatedName))
+		for j, dev2 := range developers {
 			if dev1.CanonicalEmail() == dev2.CanonicalEmail() {
-				b.WriteString("<td>-</td>")
+				b.WriteString(fmt.Sprintf("<td%s>-</td>", class(j)))
 				continue
 			}
-			b.WriteString(fmt.Sprintf("<td>%d</td>", matrix.Count(dev1.CanonicalEmail(), dev2.CanonicalEmail())))
+			first, second := options.cell(dev1, dev2)
+			b.WriteString(fmt.Sprintf("<td%s>%s</td>", class(j), formatCell(matrix, first, second)))
+		}
+		if options.Totals {
+			b.WriteString(fmt.Sprintf("<th>%s</th>", formatTotal(matrix, rowTotals[i])))
 		}
 		b.WriteString("</tr>")
 	}
+	if options.Totals {
+		b.WriteString("<tr><th>Total</th>")
+		for i, total := range rowTotals {
+			b.WriteString(fmt.Sprintf("<th%s>%s</th>", class(i), formatTotal(matrix, total)))
+		}
+		b.WriteString(fmt.Sprintf("<th>%s</th></tr>", formatTotal(matrix, grandTotal)))
+	}
 	b.WriteString("</table>")
 
+	if options.CommitSizes != nil {
+		b.WriteString("<h2>Stats</h2><p>Commit sizes: " + commitSizesSummary(*options.CommitSizes) + "</p>")
+	}
+
 	// Recommendations
 	b.WriteString("<div class=\"recommend\">")
 	if len(recommendations) == 0 {
 		b.WriteString("<h2>Pairing Recommendations</h2>")
-		b.WriteString("<p>Skipping pairing recommendations - too many developers (> 20)</p>")
+		b.WriteString("<p>" + options.skippedMessage() + "</p>")
 	} else {
-		b.WriteString("<h2>Pairing Recommendations (least-paired overall, optimal matching)</h2><ul>")
+		b.WriteString("<h2>" + recommendationsHeading(options.Strategy, options.Algorithm) + "</h2><ul>")
+		primary := recommend.Strategy(options.Strategy).Primary()
 		for _, rec := range recommendations {
-			if len(rec.B.EmailAddresses) == 0 {
-				b.WriteString(fmt.Sprintf("<li><b>%s</b> (unpaired)</li>", rec.A.AbbreviatedName))
-			} else {
-				b.WriteString(fmt.Sprintf("<li><b>%s</b> &lt;-&gt; <b>%s</b> : %d times</li>", rec.A.AbbreviatedName, rec.B.AbbreviatedName, rec.Count))
+			// Sub-team names come from the team file, so they're escaped
+			nameA := html.EscapeString(rec.A.AbbreviatedName + options.subTeamTags(rec.A))
+			nameB := html.EscapeString(rec.B.AbbreviatedName + options.subTeamTags(rec.B))
+			switch {
+			case len(rec.B.EmailAddresses) == 0:
+				b.WriteString(fmt.Sprintf("<li><b>%s</b> (unpaired)</li>", nameA))
+			case primary == recommend.LeastRecent || primary == recommend.Coverage:
+				b.WriteString(fmt.Sprintf("<li><b>%s</b> &lt;-&gt; <b>%s</b> : %s%s</li>", nameA, nameB, FormatLastPaired(rec, options), recentTag(rec)))
+			default:
+				b.WriteString(fmt.Sprintf("<li><b>%s</b> &lt;-&gt; <b>%s</b> : %d times%s</li>", nameA, nameB, rec.Count, recentTag(rec)))
 			}
 		}
 		b.WriteString("</ul>")
@@ -215,6 +893,30 @@ th { background: #eee; }
 	return b.String()
 }
 
+// printablePageWidth is the width, in points, that a printed matrix must fit in:
+// A4 or US Letter with 15mm margins
+const printablePageWidth = 510
+
+// printCSS returns the stylesheet for printing: high-contrast black on white, with
+// page breaks kept out of tables and the matrix font scaled so that a matrix with
+// the given number of developers fits the width of the page
+func printCSS(developers int) string {
+	// Each column is about two characters plus padding, roughly 2.5em
+	fontSize := float64(printablePageWidth) / (float64(developers+1) * 2.5)
+	fontSize = max(5, min(11, fontSize))
+	return fmt.Sprintf(`<style>
+@media print {
+@page { margin: 15mm; }
+body { margin: 0; background: #fff; color: #000; }
+th, td { border: 1px solid #000; padding: 0.2em 0.4em; }
+th { background: none; font-weight: bold; }
+table { font-size: %.1fpt; break-inside: avoid; page-break-inside: avoid; }
+h2 { break-after: avoid; page-break-after: avoid; }
+.recommend { break-inside: avoid; page-break-inside: avoid; }
+}
+</style>`, fontSize)
+}
+
 // openBrowser opens the given file path in the default web browser
 func openBrowser(path string) error {
 	url := path
@@ -33,6 +33,21 @@ func TestNewRenderer(t *testing.T) {
 			outputFormat: "html",
 			expectedType: "*output.HTMLRenderer",
 		},
+		{
+			name:         "Slack renderer for slack format",
+			outputFormat: "slack",
+			expectedType: "*output.SlackRenderer",
+		},
+		{
+			name:         "JSON renderer for json format",
+			outputFormat: "json",
+			expectedType: "*output.JSONRenderer",
+		},
+		{
+			name:         "Stair renderer for stair format",
+			outputFormat: "stair",
+			expectedType: "*output.StairRenderer",
+		},
 		{
 			name:         "CLI renderer for unknown format",
 			outputFormat: "unknown",
@@ -56,6 +71,32 @@ func TestNewRenderer(t *testing.T) {
 	}
 }
 
+func TestRenderToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	recencyMatrix := pairing.NewRecencyMatrix()
+
+	for _, format := range output.FileFormats {
+		t.Run(format, func(t *testing.T) {
+			var b strings.Builder
+			if err := output.RenderToWriter(&b, format, matrix, recencyMatrix, developers, "least-paired", nil, output.Options{}); err != nil {
+				t.Fatalf("RenderToWriter failed: %v", err)
+			}
+			if b.Len() == 0 {
+				t.Error("Expected output to be written")
+			}
+		})
+	}
+
+	var b strings.Builder
+	if err := output.RenderToWriter(&b, "cli", matrix, recencyMatrix, developers, "least-paired", nil, output.Options{}); err == nil {
+		t.Error("Expected an error for a format that can't be written to a file")
+	}
+}
+
 func TestNewRendererWithOpenFlag(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -284,7 +325,583 @@ func TestRenderHTMLToWriter_EmptyRecommendations(t *testing.T) {
 	}
 }
 
+func TestRenderHTMLToWriter_SkippedRecommendations(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	options := output.Options{SkippedBecause: "not enough active developers to recommend (need 2, have 1)"}
+
+	var result strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), []git.Developer{alice}, nil, options); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+
+	htmlOutput := result.String()
+	if !strings.Contains(htmlOutput, "<p>Skipping pairing recommendations - not enough active developers to recommend (need 2, have 1)</p>") {
+		t.Errorf("Expected the reason recommendations were skipped, got:\n%s", htmlOutput)
+	}
+	if strings.Contains(htmlOutput, "too many developers") {
+		t.Error("Expected no mention of too many developers")
+	}
+}
+
 // Helper function to get type name for testing
 func getTypeName(v interface{}) string {
 	return fmt.Sprintf("%T", v)
 }
+
+func TestFormatRecency(t *testing.T) {
+	tests := []struct {
+		daysSince int
+		unit      output.RecencyUnit
+		expected  string
+	}{
+		{0, output.Days, "today"},
+		{1, output.Days, "1 day ago"},
+		{15, output.Days, "15 days ago"},
+		{0, output.Weeks, "this week"},
+		{6, output.Weeks, "this week"},
+		{7, output.Weeks, "1 week ago"},
+		{13, output.Weeks, "1 week ago"},
+		{14, output.Weeks, "2 weeks ago"},
+		{45, output.Weeks, "6 weeks ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.daysSince, tt.unit), func(t *testing.T) {
+			if got := output.FormatRecency(tt.daysSince, tt.unit); got != tt.expected {
+				t.Errorf("FormatRecency(%d, %s) = %q, expected %q", tt.daysSince, tt.unit, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatCappedRecency(t *testing.T) {
+	tests := []struct {
+		daysSince int
+		unit      output.RecencyUnit
+		capDays   int
+		expected  string
+	}{
+		{700, output.Days, 0, "700 days ago"},
+		{29, output.Days, 30, "29 days ago"},
+		{30, output.Days, 30, "30 days ago"},
+		{31, output.Days, 30, "30+ days ago"},
+		{700, output.Days, 365, "365+ days ago"},
+		{0, output.Days, 1, "today"},
+		{2, output.Days, 1, "1+ days ago"},
+		{27, output.Weeks, 28, "3 weeks ago"},
+		{34, output.Weeks, 28, "4 weeks ago"},
+		{35, output.Weeks, 28, "4+ weeks ago"},
+		{13, output.Weeks, 3, "1 week ago"},
+		{14, output.Weeks, 3, "1+ weeks ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s capped at %d", tt.daysSince, tt.unit, tt.capDays), func(t *testing.T) {
+			if got := output.FormatCappedRecency(tt.daysSince, tt.unit, tt.capDays); got != tt.expected {
+				t.Errorf("FormatCappedRecency(%d, %s, %d) = %q, expected %q", tt.daysSince, tt.unit, tt.capDays, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatLastPaired(t *testing.T) {
+	paired := recommend.Recommendation{
+		LastPaired: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC),
+		DaysSince:  45,
+		HasPaired:  true,
+	}
+	neverPaired := recommend.Recommendation{}
+
+	tests := []struct {
+		name     string
+		rec      recommend.Recommendation
+		options  output.Options
+		expected string
+	}{
+		{"relative by default", paired, output.Options{}, "last paired 45 days ago"},
+		{"relative", paired, output.Options{DateStyle: output.RelativeDates}, "last paired 45 days ago"},
+		{"relative in weeks", paired, output.Options{DateStyle: output.RelativeDates, RecencyUnit: output.Weeks}, "last paired 6 weeks ago"},
+		{"absolute", paired, output.Options{DateStyle: output.AbsoluteDates}, "last paired 2024-05-12"},
+		{"absolute ignores the recency unit", paired, output.Options{DateStyle: output.AbsoluteDates, RecencyUnit: output.Weeks}, "last paired 2024-05-12"},
+		{"never paired relative", neverPaired, output.Options{DateStyle: output.RelativeDates}, "never paired"},
+		{"never paired absolute", neverPaired, output.Options{DateStyle: output.AbsoluteDates}, "never paired"},
+		{"capped", paired, output.Options{RecencyCap: 30}, "last paired 30+ days ago"},
+		{"absolute ignores the cap", paired, output.Options{DateStyle: output.AbsoluteDates, RecencyCap: 30}, "last paired 2024-05-12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := output.FormatLastPaired(tt.rec, tt.options); got != tt.expected {
+				t.Errorf("FormatLastPaired() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDateStyle(t *testing.T) {
+	for _, style := range []string{"relative", "absolute"} {
+		if _, err := output.ParseDateStyle(style); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", style, err)
+		}
+	}
+	if _, err := output.ParseDateStyle("iso"); err == nil {
+		t.Error("Expected error for invalid date style")
+	}
+}
+
+func TestParseRecencyUnit(t *testing.T) {
+	for _, unit := range []string{"days", "weeks"} {
+		if _, err := output.ParseRecencyUnit(unit); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", unit, err)
+		}
+	}
+	if _, err := output.ParseRecencyUnit("fortnights"); err == nil {
+		t.Error("Expected error for invalid recency unit")
+	}
+}
+
+func TestRenderHTMLToWriterWithTheme(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	developers := []git.Developer{alice}
+	matrix := pairing.NewMatrix()
+
+	tests := []struct {
+		theme      output.Theme
+		wantColors []string
+		notColors  []string
+	}{
+		{"", []string{"background: #fff", "background: #eee"}, []string{"#0d1117"}},
+		{output.LightTheme, []string{"background: #fff", "background: #eee"}, []string{"#0d1117"}},
+		{output.DarkTheme, []string{"background: #0d1117", "color: #c9d1d9", "background: #161b22"}, []string{"#fff", "#eee"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.theme), func(t *testing.T) {
+			var result strings.Builder
+			err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{Theme: tt.theme})
+			if err != nil {
+				t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+			}
+			for _, want := range tt.wantColors {
+				if !strings.Contains(result.String(), want) {
+					t.Errorf("Expected HTML to contain %q", want)
+				}
+			}
+			for _, notWant := range tt.notColors {
+				if strings.Contains(result.String(), notWant) {
+					t.Errorf("Expected HTML not to contain %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	for _, valid := range []string{"light", "dark"} {
+		if theme, err := output.ParseTheme(valid); err != nil || string(theme) != valid {
+			t.Errorf("Expected %q to parse, got %q, %v", valid, theme, err)
+		}
+	}
+	if _, err := output.ParseTheme("solarized"); err == nil {
+		t.Error("Expected error for invalid theme")
+	}
+}
+
+func TestRenderHTMLToWriterWithTotals(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	matrix := pairing.NewMatrix()
+	matrix.Add(alice.CanonicalEmail(), bob.CanonicalEmail())
+	matrix.Add(alice.CanonicalEmail(), bob.CanonicalEmail())
+	matrix.Add(alice.CanonicalEmail(), carol.CanonicalEmail())
+
+	var result strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{Totals: true}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := result.String()
+
+	for _, want := range []string{
+		"<th>CD</th><th>Total</th></tr>",
+		// Each developer's row ends with their total
+		"<td>2</td><td>1</td><th>3</th></tr>",
+		"<td>2</td><td>-</td><td>0</td><th>2</th></tr>",
+		"<td>1</td><td>0</td><td>-</td><th>1</th></tr>",
+		// The grand total counts each of the 3 pairings twice
+		"<tr><th>Total</th><th>3</th><th>2</th><th>1</th><th>6</th></tr>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML with totals to contain %q", want)
+		}
+	}
+
+	result.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if strings.Contains(result.String(), "Total") {
+		t.Error("Expected no totals unless asked for")
+	}
+}
+
+func TestRenderHTMLToWriterRecommendationsHeading(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+	recommendations := []recommend.Recommendation{{A: alice, B: bob, Count: 2, HasPaired: true, DaysSince: 3}}
+
+	tests := []struct {
+		name    string
+		options output.Options
+		want    []string
+	}{
+		{
+			name:    "least-paired with greedy matching",
+			options: output.Options{Algorithm: recommend.AlgorithmGreedy},
+			want:    []string{"<h2>Pairing Recommendations (least-paired overall, greedy matching)</h2>", "<b>AS</b> &lt;-&gt; <b>BJ</b> : 2 times"},
+		},
+		{
+			name:    "coverage with optimal matching",
+			options: output.Options{Strategy: "coverage", Algorithm: recommend.AlgorithmOptimal},
+			want:    []string{"<h2>Pairing Recommendations (most new pairs, optimal matching)</h2>", "<b>AS</b> &lt;-&gt; <b>BJ</b> : last paired 3 days ago"},
+		},
+		{
+			name:    "least-recent without a known algorithm",
+			options: output.Options{Strategy: "least-recent"},
+			want:    []string{"<h2>Pairing Recommendations (least recent collaborations first)</h2>", "last paired 3 days ago"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result strings.Builder
+			if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), developers, recommendations, tt.options); err != nil {
+				t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.String(), want) {
+					t.Errorf("Expected HTML to contain %q, got %s", want, result.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHTMLToWriterWithDateStyle(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+	lastPaired := time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: bob, HasPaired: true, LastPaired: lastPaired, DaysSince: 45},
+		{A: carol, B: bob, DaysSince: -1},
+	}
+
+	tests := []struct {
+		dateStyle output.DateStyle
+		want      string
+	}{
+		{output.RelativeDates, "<b>AS</b> &lt;-&gt; <b>BJ</b> : last paired 45 days ago"},
+		{output.AbsoluteDates, "<b>AS</b> &lt;-&gt; <b>BJ</b> : last paired 2024-05-12"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dateStyle), func(t *testing.T) {
+			var result strings.Builder
+			options := output.Options{Strategy: "least-recent", DateStyle: tt.dateStyle}
+			if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), developers, recommendations, options); err != nil {
+				t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+			}
+			for _, want := range []string{tt.want, "<b>CD</b> &lt;-&gt; <b>BJ</b> : never paired"} {
+				if !strings.Contains(result.String(), want) {
+					t.Errorf("Expected HTML to contain %q, got %s", want, result.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHTMLToWriterMarksRecentPairs(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: bob, Count: 1, HasPaired: true, DaysSince: 2, Recent: true},
+		{A: carol, B: dave, Count: 1, HasPaired: true, DaysSince: 30},
+	}
+
+	for _, tt := range []struct {
+		strategy string
+		want     []string
+	}{
+		{"least-paired", []string{"<b>AS</b> &lt;-&gt; <b>BJ</b> : 1 times (recently paired)</li>", "<b>CD</b> &lt;-&gt; <b>DB</b> : 1 times</li>"}},
+		{"least-recent", []string{"<b>AS</b> &lt;-&gt; <b>BJ</b> : last paired 2 days ago (recently paired)</li>", "<b>CD</b> &lt;-&gt; <b>DB</b> : last paired 30 days ago</li>"}},
+	} {
+		t.Run(tt.strategy, func(t *testing.T) {
+			var result strings.Builder
+			if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), developers, recommendations, output.Options{Strategy: tt.strategy}); err != nil {
+				t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.String(), want) {
+					t.Errorf("Expected HTML to contain %q, got %s", want, result.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHTMLToWriterWithCommitSizes(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+
+	sizes := pairing.CommitSizes{Solo: 1, Pairs: 2, Mobs: 1}
+	var result strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), developers, nil, output.Options{CommitSizes: &sizes}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if want := "<h2>Stats</h2><p>Commit sizes: 25% solo, 50% pairs, 25% mobs (of 4 commits)</p>"; !strings.Contains(result.String(), want) {
+		t.Errorf("Expected HTML to contain %q, got %s", want, result.String())
+	}
+
+	result.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&result, pairing.NewMatrix(), developers, nil, output.Options{}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if strings.Contains(result.String(), "Commit sizes") {
+		t.Error("Expected no commit sizes without any to show")
+	}
+}
+
+func TestRenderHTMLToWriterWeighted(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+
+	// As in the CLI, a weighted matrix shows the weights rather than the counts
+	matrix := pairing.NewMatrix()
+	matrix.AddWeighted(alice.CanonicalEmail(), bob.CanonicalEmail(), 0.5)
+
+	var result strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{Totals: true}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	for _, want := range []string{
+		"<tr><th>AS</th><td>-</td><td>0.50</td><th>0.50</th></tr>",
+		"<tr><th>Total</th><th>0.50</th><th>0.50</th><th>1.00</th></tr>",
+	} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected weighted HTML to contain %q, got:\n%s", want, result.String())
+		}
+	}
+}
+
+func TestRenderHTMLToWriterForPrint(t *testing.T) {
+	var developers []git.Developer
+	for _, name := range []string{"Alice Smith", "Bob Jones", "Carol Davis"} {
+		developers = append(developers, git.NewDeveloper(name+" <"+strings.ToLower(strings.Fields(name)[0])+"@example.com>"))
+	}
+	matrix := pairing.NewMatrix()
+
+	var result strings.Builder
+	err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{Print: true, Theme: output.DarkTheme})
+	if err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := result.String()
+
+	for _, want := range []string{
+		"@media print",
+		"break-inside: avoid",
+		"font-size: 11.0pt",
+		"background: #fff",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected printable HTML to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "#0d1117") {
+		t.Error("Expected printing to force the light theme")
+	}
+	if strings.Contains(html, "<script") {
+		t.Error("Expected printable HTML to have no scripts")
+	}
+
+	// A large team gets a smaller font so the matrix fits the page
+	for i := 0; i < 30; i++ {
+		developers = append(developers, git.NewDeveloper(fmt.Sprintf("Dev %d <dev%d@example.com>", i, i)))
+	}
+	result.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{Print: true}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result.String(), "font-size: 6.0pt") {
+		t.Errorf("Expected a 6pt font for 33 developers")
+	}
+
+	result.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&result, matrix, developers, nil, output.Options{}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if strings.Contains(result.String(), "@media print") {
+		t.Error("Expected no print CSS unless printing")
+	}
+}
+
+func TestGroupBySubTeam(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	eve := git.NewDeveloper("Eve Adams <eve@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave, eve}
+	subTeams := map[string][]string{
+		"alice@example.com": {"frontend"},
+		"bob@example.com":   {"backend"},
+		// Carol is shown in the first sub-team listed for her
+		"carol@example.com": {"frontend", "backend"},
+		"eve@example.com":   {"backend"},
+	}
+
+	groups := output.GroupBySubTeam(developers, subTeams)
+
+	var got []string
+	for _, group := range groups {
+		var initials []string
+		for _, dev := range group.Developers {
+			initials = append(initials, dev.AbbreviatedName)
+		}
+		got = append(got, group.SubTeam+":"+strings.Join(initials, ","))
+	}
+	expected := "backend:BJ,EA frontend:AS,CD :DB"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("Expected groups %q, got %q", expected, strings.Join(got, " "))
+	}
+}
+
+func TestRenderHTMLGroupedBySubTeam(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, carol)
+
+	options := output.Options{
+		GroupBySubTeam: true,
+		SubTeams:       map[string][]string{"alice@example.com": {"frontend"}, "bob@example.com": {"backend"}, "carol@example.com": {"frontend"}},
+	}
+	var b strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&b, matrix, developers, nil, options); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := b.String()
+
+	// Bob's backend comes first, then Alice and Carol's frontend with a border before it
+	if !strings.Contains(html, `<tr><th></th><th>BJ</th><th class="group-start">AS</th><th>CD</th></tr>`) {
+		t.Errorf("Expected the columns grouped by sub-team, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<tr class="group-start"><th>AS</th><td>0</td><td class="group-start">-</td>`) {
+		t.Errorf("Expected a border before the frontend row and column, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<tr><th colspan="3">frontend</th></tr>`) {
+		t.Errorf("Expected the sub-team names in the legend, got:\n%s", html)
+	}
+}
+
+func TestRenderHTMLGroupedBySubTeamEscapesNames(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+
+	// Sub-team names come from the team file, so they're escaped
+	options := output.Options{
+		GroupBySubTeam: true,
+		SubTeams:       map[string][]string{"alice@example.com": {"<script>"}, "bob@example.com": {"R&D"}},
+	}
+	var b strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&b, pairing.NewMatrix(), developers, nil, options); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := b.String()
+
+	for _, want := range []string{`<tr><th colspan="3">&lt;script&gt;</th></tr>`, `<tr><th colspan="3">R&amp;D</th></tr>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the legend to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Errorf("Expected no unescaped sub-team names, got:\n%s", html)
+	}
+}
+
+func TestRenderHTMLRecommendationsWithSubTeamTags(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+	recommendations := []recommend.Recommendation{{A: alice, B: bob}, {A: carol}}
+
+	options := output.Options{
+		SubTeams: map[string][]string{"alice@example.com": {"frontend", "<platform>"}, "bob@example.com": {"R&D"}},
+	}
+	var b strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&b, pairing.NewMatrix(), developers, recommendations, options); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := b.String()
+
+	for _, want := range []string{
+		"<li><b>AS [frontend] [&lt;platform&gt;]</b> &lt;-&gt; <b>BJ [R&amp;D]</b> : 0 times</li>",
+		"<li><b>CD</b> (unpaired)</li>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+}
+
+func TestRenderHTMLLegendWithRoles(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+	matrix := pairing.NewMatrix()
+
+	var b strings.Builder
+	if err := output.RenderHTMLToWriterWithOptions(&b, matrix, developers, nil, output.Options{Roles: map[string]string{"alice@example.com": "Lead"}}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	html := b.String()
+	if !strings.Contains(html, "<tr><th>Initials</th><th>Name</th><th>Role</th><th>Email</th></tr>") {
+		t.Errorf("Expected a role column in the legend, got:\n%s", html)
+	}
+	if !strings.Contains(html, "<tr><td>AS</td><td>Alice Smith</td><td>Lead</td><td>alice@example.com</td></tr>") {
+		t.Errorf("Expected Alice's role in the legend, got:\n%s", html)
+	}
+	if !strings.Contains(html, "<tr><td>BJ</td><td>Bob Jones</td><td></td><td>bob@example.com</td></tr>") {
+		t.Errorf("Expected a blank role for Bob, got:\n%s", html)
+	}
+
+	// Roles come from the team file, so they're escaped
+	b.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&b, matrix, developers, nil, output.Options{Roles: map[string]string{"bob@example.com": "<b>QA & Ops</b>"}}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "<td>&lt;b&gt;QA &amp; Ops&lt;/b&gt;</td>") {
+		t.Errorf("Expected Bob's role escaped, got:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := output.RenderHTMLToWriterWithOptions(&b, matrix, developers, nil, output.Options{}); err != nil {
+		t.Fatalf("RenderHTMLToWriterWithOptions failed: %v", err)
+	}
+	if strings.Contains(b.String(), "<th>Role</th>") {
+		t.Errorf("Expected no role column without roles, got:\n%s", b.String())
+	}
+}
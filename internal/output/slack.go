@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
+)
+
+// maxSlackLength keeps messages within the length Slack renders in a single text block
+const maxSlackLength = 3000
+
+// SlackRenderer handles Slack mrkdwn output, suitable for posting via a webhook.
+// The full matrix renders poorly in Slack, so only recommendations and a
+// coverage summary are included.
+type SlackRenderer struct {
+	Options Options
+}
+
+// Render outputs the recommendations and coverage summary as Slack mrkdwn
+func (r *SlackRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderSlackToWriter(os.Stdout, matrix, developers, strategy, recommendations, r.Options)
+}
+
+// RenderSlackToWriter renders Slack mrkdwn output to the provided io.Writer
+func RenderSlackToWriter(w io.Writer, matrix *pairing.Matrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) error {
+	_, err := io.WriteString(w, renderSlack(matrix, developers, strategy, recommendations, options))
+	return err
+}
+
+// renderSlack builds the Slack message, dropping recommendations that would make it too long
+func renderSlack(matrix *pairing.Matrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) string {
+	heading := "*" + recommendationsHeading(strategy, options.Algorithm) + "*\n"
+	footer := slackCoverageLine(stats.CalculateCoverage(developers, matrix), len(developers))
+
+	if len(recommendations) == 0 {
+		return heading + "_" + options.skippedMessage() + "_\n" + footer
+	}
+
+	lines := make([]string, 0, len(recommendations))
+	for _, rec := range recommendations {
+		lines = append(lines, slackRecommendationLine(rec, strategy, options))
+	}
+
+	var b strings.Builder
+	b.WriteString(heading)
+	for i, line := range lines {
+		remaining := len(lines) - i
+		more := fmt.Sprintf("• _…and %d more_\n", remaining)
+		if b.Len()+len(line)+len(more)+len(footer) > maxSlackLength {
+			b.WriteString(more)
+			break
+		}
+		b.WriteString(line)
+	}
+	b.WriteString(footer)
+	return b.String()
+}
+
+// slackRecommendationLine formats a single recommendation as a mrkdwn bullet
+func slackRecommendationLine(rec recommend.Recommendation, strategy string, options Options) string {
+	if len(rec.B.EmailAddresses) == 0 {
+		return fmt.Sprintf("• %s%s _(unpaired)_\n", slackEscape(rec.A.DisplayName), slackEscape(options.subTeamTags(rec.A)))
+	}
+
+	pair := fmt.Sprintf("*%s*%s and *%s*%s",
+		slackEscape(rec.A.DisplayName), slackEscape(options.subTeamTags(rec.A)),
+		slackEscape(rec.B.DisplayName), slackEscape(options.subTeamTags(rec.B)))
+	switch primary := recommend.Strategy(strategy).Primary(); {
+	case primary == recommend.LeastRecent || primary == recommend.Coverage:
+		return fmt.Sprintf("• %s — %s%s\n", pair, FormatLastPaired(rec, options), recentTag(rec))
+	default:
+		return fmt.Sprintf("• %s — %d times%s\n", pair, rec.Count, recentTag(rec))
+	}
+}
+
+// slackCoverageLine summarises pairing coverage in one line
+func slackCoverageLine(coverage stats.Coverage, developerCount int) string {
+	return fmt.Sprintf("_Coverage: %d/%d pairs (%.0f%%) across %d developers_\n",
+		coverage.Paired, coverage.Possible, coverage.Ratio()*100, developerCount)
+}
+
+// slackEscape escapes the characters Slack treats as control sequences
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
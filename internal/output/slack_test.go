@@ -0,0 +1,126 @@
+package output_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestRenderSlackToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol & Co <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, carol)
+
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: bob, Count: 0},
+		{A: carol, B: git.Developer{}},
+	}
+
+	var result strings.Builder
+	err := output.RenderSlackToWriter(&result, matrix, developers, "least-paired", recommendations, output.Options{Algorithm: recommend.AlgorithmGreedy})
+	if err != nil {
+		t.Fatalf("RenderSlackToWriter failed: %v", err)
+	}
+
+	expected := "*Pairing Recommendations (least-paired overall, greedy matching)*\n" +
+		"• *Alice Smith* and *Bob Jones* — 0 times\n" +
+		"• Carol &amp; Co _(unpaired)_\n" +
+		"_Coverage: 1/3 pairs (33%) across 3 developers_\n"
+	if result.String() != expected {
+		t.Errorf("Unexpected Slack output.\nExpected:\n%s\nGot:\n%s", expected, result.String())
+	}
+}
+
+func TestRenderSlackToWriter_SubTeamTags(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: bob, Count: 0},
+		{A: carol, B: git.Developer{}},
+	}
+	options := output.Options{SubTeams: map[string][]string{
+		"alice@example.com": {"frontend", "backend"},
+		"carol@example.com": {"devops"},
+	}}
+
+	var result strings.Builder
+	err := output.RenderSlackToWriter(&result, pairing.NewMatrix(), developers, "least-paired", recommendations, options)
+	if err != nil {
+		t.Fatalf("RenderSlackToWriter failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"• *Alice Smith* [frontend] [backend] and *Bob Jones* — 0 times\n",
+		"• Carol Davis [devops] _(unpaired)_\n",
+	} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected Slack output to contain %q, got:\n%s", want, result.String())
+		}
+	}
+}
+
+func TestRenderSlackToWriter_LeastRecent(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	recommendations := []recommend.Recommendation{
+		{A: alice, B: bob, HasPaired: false, DaysSince: -1},
+		{A: carol, B: dave, HasPaired: true, DaysSince: 3},
+	}
+
+	var result strings.Builder
+	err := output.RenderSlackToWriter(&result, pairing.NewMatrix(), []git.Developer{alice, bob, carol, dave}, "least-recent", recommendations, output.Options{})
+	if err != nil {
+		t.Fatalf("RenderSlackToWriter failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"• *Alice Smith* and *Bob Jones* — never paired\n",
+		"• *Carol Davis* and *Dave Brown* — last paired 3 days ago\n",
+	} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected Slack output to contain %q, got:\n%s", want, result.String())
+		}
+	}
+}
+
+func TestRenderSlackToWriter_TrimsLongMessages(t *testing.T) {
+	var developers []git.Developer
+	var recommendations []recommend.Recommendation
+	for i := 0; i < 200; i += 2 {
+		a := git.NewDeveloper(fmt.Sprintf("Developer Number %d <dev%d@example.com>", i, i))
+		b := git.NewDeveloper(fmt.Sprintf("Developer Number %d <dev%d@example.com>", i+1, i+1))
+		developers = append(developers, a, b)
+		recommendations = append(recommendations, recommend.Recommendation{A: a, B: b})
+	}
+
+	var result strings.Builder
+	err := output.RenderSlackToWriter(&result, pairing.NewMatrix(), developers, "least-paired", recommendations, output.Options{})
+	if err != nil {
+		t.Fatalf("RenderSlackToWriter failed: %v", err)
+	}
+
+	if len(result.String()) > 3000 {
+		t.Errorf("Expected message to be trimmed to 3000 characters, got %d", len(result.String()))
+	}
+	if !strings.Contains(result.String(), "more_") {
+		t.Errorf("Expected trimmed message to say how many recommendations were left out")
+	}
+	if !strings.Contains(result.String(), "_Coverage:") {
+		t.Errorf("Expected trimmed message to keep the coverage line")
+	}
+}
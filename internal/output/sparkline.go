@@ -0,0 +1,130 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// sparkLevels are the characters a sparkline is drawn with, from no pairing that
+// week to the busiest week; asciiSparkLevels are used instead with ASCII
+var (
+	sparkLevels      = []rune(" ▁▂▃▄▅▆▇█")
+	asciiSparkLevels = []rune(" .,:-=+*#")
+)
+
+// DefaultSparklinePairs is how many pairs the sparklines output shows by default
+const DefaultSparklinePairs = 10
+
+// SparklineRenderer handles the "sparklines" output: a line per pair, for the
+// pairs who have paired the most, showing how many days they paired in each week
+// of the window.
+//
+// All the sparklines share a scale, so the tallest mark is the busiest week of any
+// pair shown. A week with no pairing is blank, and bars mark where the window
+// starts and ends.
+type SparklineRenderer struct {
+	Participation *pairing.Participation
+	Start, End    time.Time
+	// Pairs is how many pairs are shown, most paired first
+	Pairs int
+	// ASCII draws the sparklines with plain ASCII characters rather than Unicode blocks
+	ASCII bool
+}
+
+// Render outputs the sparklines to the console
+func (r *SparklineRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderSparklinesToWriter(os.Stdout, r.Participation, matrix, developers, r.Start, r.End, r.Pairs, r.ASCII)
+}
+
+// sparklinePair is a pair of developers and how many days they paired each week
+type sparklinePair struct {
+	label string
+	count int
+	weeks []int
+}
+
+// RenderSparklinesToWriter renders a sparkline for each of the most paired pairs,
+// up to the given number, to the provided io.Writer
+func RenderSparklinesToWriter(w io.Writer, participation *pairing.Participation, matrix *pairing.Matrix, developers []git.Developer, start, end time.Time, pairs int, ascii bool) error {
+	var shown []sparklinePair
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			count := matrix.CountByDeveloper(developers[i], developers[j])
+			if count == 0 {
+				continue
+			}
+			shown = append(shown, sparklinePair{
+				label: developers[i].AbbreviatedName + "-" + developers[j].AbbreviatedName,
+				count: count,
+				weeks: participation.WeeklyPairDays(developers[i].CanonicalEmail(), developers[j].CanonicalEmail(), start, end),
+			})
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		if shown[i].count != shown[j].count {
+			return shown[i].count > shown[j].count
+		}
+		return shown[i].label < shown[j].label
+	})
+	if pairs > 0 && len(shown) > pairs {
+		shown = shown[:pairs]
+	}
+
+	maxWeek, labelWidth := 0, 0
+	for _, pair := range shown {
+		labelWidth = max(labelWidth, len(pair.label))
+		for _, days := range pair.weeks {
+			maxWeek = max(maxWeek, days)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Weekly Pairing (days paired each week, %s to %s):\n", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if len(shown) == 0 {
+		b.WriteString("  No pairing in this window\n")
+	}
+	for _, pair := range shown {
+		fmt.Fprintf(&b, "  %-*s  |%s|  %d\n", labelWidth, pair.label, sparklineScaled(pair.weeks, maxWeek, ascii), pair.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Sparkline draws a series as a sparkline scaled to its own largest value, one
+// character per value, with Unicode blocks or, if ascii is set, ASCII characters
+func Sparkline(series []int, ascii bool) string {
+	largest := 0
+	for _, value := range series {
+		largest = max(largest, value)
+	}
+	return sparklineScaled(series, largest, ascii)
+}
+
+// sparklineScaled draws a series as a sparkline with largest as the tallest mark.
+// Zero is blank, and any other value is at least the shortest mark.
+func sparklineScaled(series []int, largest int, ascii bool) string {
+	levels := sparkLevels
+	if ascii {
+		levels = asciiSparkLevels
+	}
+	top := len(levels) - 1
+
+	var b strings.Builder
+	for _, value := range series {
+		level := 0
+		if value > 0 && largest > 0 {
+			level = min((value*top+largest-1)/largest, top)
+		}
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
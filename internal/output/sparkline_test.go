@@ -0,0 +1,69 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+func TestSparkline(t *testing.T) {
+	series := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	if got, want := output.Sparkline(series, false), " ▁▂▃▄▅▆▇█"; got != want {
+		t.Errorf("Sparkline(%v) = %q, want %q", series, got, want)
+	}
+	if got, want := output.Sparkline(series, true), " .,:-=+*#"; got != want {
+		t.Errorf("Sparkline(%v, ascii) = %q, want %q", series, got, want)
+	}
+
+	// Values are scaled to the largest, rounding up so no pairing is lost
+	if got, want := output.Sparkline([]int{1, 0, 5, 10}, false), "▁ ▄█"; got != want {
+		t.Errorf("Expected a scaled sparkline %q, got %q", want, got)
+	}
+	if got := output.Sparkline([]int{0, 0}, false); got != "  " {
+		t.Errorf("Expected a blank sparkline with no pairing, got %q", got)
+	}
+}
+
+func TestRenderSparklinesToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC) // A Monday
+	end := start.AddDate(0, 0, 27)
+
+	matrix := pairing.NewMatrix()
+	participation := pairing.NewParticipation()
+	pair := func(a, b git.Developer, days ...int) {
+		for _, day := range days {
+			matrix.AddByDeveloper(a, b)
+			participation.Record(a.CanonicalEmail(), b.CanonicalEmail(), start.AddDate(0, 0, day))
+		}
+	}
+	pair(alice, bob, 0, 1, 2, 3, 14, 21)
+	pair(bob, carol, 7, 8)
+
+	var result strings.Builder
+	if err := output.RenderSparklinesToWriter(&result, participation, matrix, developers, start, end, 10, false); err != nil {
+		t.Fatalf("RenderSparklinesToWriter failed: %v", err)
+	}
+	expected := "Weekly Pairing (days paired each week, 2024-06-03 to 2024-06-30):\n" +
+		"  AS-BJ  |█ ▂▂|  6\n" +
+		"  BJ-CD  | ▄  |  2\n"
+	if result.String() != expected {
+		t.Errorf("Expected sparklines:\n%s\nGot:\n%s", expected, result.String())
+	}
+
+	// Only the most paired pairs are shown
+	result.Reset()
+	if err := output.RenderSparklinesToWriter(&result, participation, matrix, developers, start, end, 1, true); err != nil {
+		t.Fatalf("RenderSparklinesToWriter failed: %v", err)
+	}
+	if strings.Contains(result.String(), "BJ-CD") || !strings.Contains(result.String(), "AS-BJ  |# ,,|  6") {
+		t.Errorf("Expected only Alice and Bob in ASCII, got:\n%s", result.String())
+	}
+}
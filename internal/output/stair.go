@@ -0,0 +1,108 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// maxStairWidth is the length of the longest step, in characters
+const maxStairWidth = 40
+
+// StairRenderer handles the "stair" output: a step chart of how often each pair
+// has worked together, in place of the grid.
+//
+// Each pair that has paired is one step. Steps are ordered by the number of days
+// the pair worked together, most first, then by most recently paired, then by
+// label, so the chart descends like a staircase. A step's width is proportional
+// to its count, with the largest count drawn maxStairWidth characters wide and
+// every non-zero count at least one character wide. Pairs that have never
+// worked together have no step and are listed on a single line underneath.
+type StairRenderer struct {
+	Options Options
+}
+
+// Render outputs the stair chart and recommendations to the console
+func (r *StairRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	if err := RenderStairToWriter(os.Stdout, matrix, recencyMatrix, developers); err != nil {
+		return err
+	}
+	PrintRecommendationsCLIWithOptions(recommendations, strategy, r.Options)
+	return nil
+}
+
+// stairStep is a single pair in the stair chart
+type stairStep struct {
+	label      string
+	count      int
+	lastPaired time.Time
+}
+
+// RenderStairToWriter renders the legend and stair chart to the provided io.Writer
+func RenderStairToWriter(w io.Writer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer) error {
+	var b strings.Builder
+
+	b.WriteString("Legend:\n")
+	for _, dev := range developers {
+		fmt.Fprintf(&b, "  %-6s = %-20s %s\n", dev.AbbreviatedName, dev.DisplayName, dev.CanonicalEmail())
+	}
+	b.WriteString("\n")
+
+	var steps []stairStep
+	var neverPaired []string
+	labelWidth := 0
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			label := developers[i].AbbreviatedName + "-" + developers[j].AbbreviatedName
+			count := matrix.CountByDeveloper(developers[i], developers[j])
+			if count == 0 {
+				neverPaired = append(neverPaired, label)
+				continue
+			}
+			lastPaired, _ := recencyMatrix.LastPairedByDeveloper(developers[i], developers[j])
+			steps = append(steps, stairStep{label: label, count: count, lastPaired: lastPaired})
+			labelWidth = max(labelWidth, len(label))
+		}
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].count != steps[j].count {
+			return steps[i].count > steps[j].count
+		}
+		if !steps[i].lastPaired.Equal(steps[j].lastPaired) {
+			return steps[i].lastPaired.After(steps[j].lastPaired)
+		}
+		return steps[i].label < steps[j].label
+	})
+
+	b.WriteString("Pair Stair (days paired together, most first):\n")
+	if len(steps) == 0 {
+		b.WriteString("  No pairing in this window\n")
+	}
+	for _, step := range steps {
+		bar := strings.Repeat("#", stairWidth(step.count, steps[0].count))
+		fmt.Fprintf(&b, "  %-*s  %-*s  %d", labelWidth, step.label, maxStairWidth, bar, step.count)
+		if !step.lastPaired.IsZero() {
+			fmt.Fprintf(&b, "  (last %s)", step.lastPaired.Format("2006-01-02"))
+		}
+		b.WriteString("\n")
+	}
+	if len(neverPaired) > 0 {
+		fmt.Fprintf(&b, "  Never paired: %s\n", strings.Join(neverPaired, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// stairWidth scales a count to a step width, rounding up so no step disappears
+func stairWidth(count, maxCount int) int {
+	return (count*maxStairWidth + maxCount - 1) / maxCount
+}
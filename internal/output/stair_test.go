@@ -0,0 +1,75 @@
+package output_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestRenderStairToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pair := func(a, b git.Developer, count int, last time.Time) {
+		for i := 0; i < count; i++ {
+			matrix.AddByDeveloper(a, b)
+		}
+		recencyMatrix.RecordByDeveloper(a, b, last)
+	}
+	pair(alice, bob, 6, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC))
+	pair(carol, dave, 3, time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC))
+	pair(alice, carol, 1, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+	pair(bob, dave, 1, time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC))
+
+	var result strings.Builder
+	if err := output.RenderStairToWriter(&result, matrix, recencyMatrix, developers); err != nil {
+		t.Fatalf("RenderStairToWriter failed: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "stair.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(result.String()), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if result.String() != string(expected) {
+		t.Errorf("Stair output does not match %s (run with -update to regenerate)\nExpected:\n%s\nGot:\n%s", golden, expected, result.String())
+	}
+}
+
+func TestRenderStairToWriter_NoPairing(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+
+	var result strings.Builder
+	if err := output.RenderStairToWriter(&result, pairing.NewMatrix(), pairing.NewRecencyMatrix(), developers); err != nil {
+		t.Fatalf("RenderStairToWriter failed: %v", err)
+	}
+
+	for _, want := range []string{"No pairing in this window", "Never paired: AS-BJ"} {
+		if !strings.Contains(result.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, result.String())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import "fmt"
+
+// Theme is the color scheme used by the HTML outputs
+type Theme string
+
+const (
+	LightTheme Theme = "light"
+	DarkTheme  Theme = "dark"
+)
+
+// palette is the set of colors a theme uses in the inline CSS
+type palette struct {
+	background string
+	text       string
+	border     string
+	header     string
+	// heatmap shades days from no pairing up to the most pairing, and must stay
+	// legible against the background
+	heatmap []string
+}
+
+var palettes = map[Theme]palette{
+	LightTheme: {
+		background: "#fff",
+		text:       "#000",
+		border:     "#ccc",
+		header:     "#eee",
+		heatmap:    []string{"#ebedf0", "#9be9a8", "#40c463", "#216e39"},
+	},
+	DarkTheme: {
+		background: "#0d1117",
+		text:       "#c9d1d9",
+		border:     "#30363d",
+		header:     "#161b22",
+		heatmap:    []string{"#21262d", "#0e4429", "#006d32", "#39d353"},
+	},
+}
+
+// palette returns the theme's colors, falling back to the light theme
+func (t Theme) palette() palette {
+	if p, ok := palettes[t]; ok {
+		return p
+	}
+	return palettes[LightTheme]
+}
+
+// ParseTheme converts a theme name to a Theme
+func ParseTheme(theme string) (Theme, error) {
+	if _, ok := palettes[Theme(theme)]; !ok {
+		return "", fmt.Errorf("invalid theme: %s (expected 'light' or 'dark')", theme)
+	}
+	return Theme(theme), nil
+}
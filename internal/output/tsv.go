@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// TSVRenderer handles tab-separated output of the matrix alone, for cut, awk and
+// other shell tools. There's no quoting, as labels never contain tabs.
+type TSVRenderer struct{}
+
+// Render outputs the matrix as tab-separated values
+func (r *TSVRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderTSVToWriter(os.Stdout, matrix, developers)
+}
+
+// RenderTSVToWriter renders the matrix as tab-separated values to the provided io.Writer.
+// The first row is the developers' labels after an empty cell, and each following
+// row starts with a developer's label. A developer's cell with themselves is empty.
+func RenderTSVToWriter(w io.Writer, matrix *pairing.Matrix, developers []git.Developer) error {
+	var b strings.Builder
+
+	header := []string{""}
+	for _, dev := range developers {
+		header = append(header, dev.AbbreviatedName)
+	}
+	b.WriteString(strings.Join(header, "\t") + "\n")
+
+	for _, dev1 := range developers {
+		row := []string{dev1.AbbreviatedName}
+		for _, dev2 := range developers {
+			switch {
+			case dev1.CanonicalEmail() == dev2.CanonicalEmail():
+				row = append(row, "")
+			case matrix.Weighted():
+				row = append(row, fmt.Sprintf("%.2f", matrix.Weight(dev1.CanonicalEmail(), dev2.CanonicalEmail())))
+			default:
+				row = append(row, fmt.Sprint(matrix.Count(dev1.CanonicalEmail(), dev2.CanonicalEmail())))
+			}
+		}
+		b.WriteString(strings.Join(row, "\t") + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
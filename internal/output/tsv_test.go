@@ -0,0 +1,78 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+func TestRenderTSVToWriter(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(bob, carol)
+
+	var result strings.Builder
+	if err := output.RenderTSVToWriter(&result, matrix, developers); err != nil {
+		t.Fatalf("RenderTSVToWriter failed: %v", err)
+	}
+
+	expected := "\tAS\tBJ\tCD\n" +
+		"AS\t\t2\t0\n" +
+		"BJ\t2\t\t1\n" +
+		"CD\t0\t1\t\n"
+	if result.String() != expected {
+		t.Errorf("Expected TSV:\n%q\nGot:\n%q", expected, result.String())
+	}
+
+	// Every row has a cell per developer plus the label column
+	for i, line := range strings.Split(strings.TrimSuffix(result.String(), "\n"), "\n") {
+		if cells := strings.Split(line, "\t"); len(cells) != len(developers)+1 {
+			t.Errorf("Expected row %d to have %d cells, got %d", i, len(developers)+1, len(cells))
+		}
+	}
+	if strings.ContainsAny(result.String(), `",`) {
+		t.Error("Expected no quoting or commas in TSV output")
+	}
+}
+
+func TestRenderTSVToWriterIsItsOwnTranspose(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	// Alice always authors with Bob as co-author: the attribution is one-sided, but
+	// the count in the matrix isn't, so rows and columns read the same
+	matrix := pairing.NewMatrix()
+	for i := 0; i < 3; i++ {
+		matrix.AddByDeveloper(alice, bob)
+		matrix.AddAttribution(alice.CanonicalEmail(), bob.CanonicalEmail())
+	}
+	matrix.AddByDeveloper(carol, alice)
+
+	var result strings.Builder
+	if err := output.RenderTSVToWriter(&result, matrix, developers); err != nil {
+		t.Fatalf("RenderTSVToWriter failed: %v", err)
+	}
+
+	var grid [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(result.String(), "\n"), "\n") {
+		grid = append(grid, strings.Split(line, "\t"))
+	}
+	for i := range grid {
+		for j := range grid[i] {
+			if grid[i][j] != grid[j][i] {
+				t.Errorf("Expected cell %d,%d to equal cell %d,%d, got %q and %q", i, j, j, i, grid[i][j], grid[j][i])
+			}
+		}
+	}
+}
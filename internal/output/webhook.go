@@ -0,0 +1,26 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+// WebhookPayload renders the results as a JSON body for posting to a webhook.
+// Slack output is wrapped in a Slack message; JSON output is posted as-is.
+func WebhookPayload(outputFormat string, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options Options) ([]byte, error) {
+	switch outputFormat {
+	case "slack":
+		message := struct {
+			Text string `json:"text"`
+		}{Text: renderSlack(matrix, developers, strategy, recommendations, options)}
+		return json.Marshal(message)
+	case "json":
+		return json.Marshal(NewJSONResultWithOptions(matrix, recencyMatrix, developers, strategy, recommendations, options))
+	default:
+		return nil, fmt.Errorf("posting to a webhook requires -output slack or json, got %q", outputFormat)
+	}
+}
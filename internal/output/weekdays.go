@@ -0,0 +1,53 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
+)
+
+// WeekdaysRenderer handles the "weekdays" output: a bar chart of how many days
+// pairs worked together on each day of the week, to show whether pairing
+// clusters on particular days
+type WeekdaysRenderer struct {
+	Participation *pairing.Participation
+}
+
+// NewWeekdaysRenderer creates a weekdays renderer for the given participation
+func NewWeekdaysRenderer(participation *pairing.Participation) *WeekdaysRenderer {
+	return &WeekdaysRenderer{Participation: participation}
+}
+
+// Render outputs the day-of-week chart to the console
+func (r *WeekdaysRenderer) Render(matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation) error {
+	return RenderWeekdaysToWriter(os.Stdout, stats.PairingByWeekday(r.Participation))
+}
+
+// RenderWeekdaysToWriter renders the day-of-week chart to the provided io.Writer.
+// Bars are scaled like the stair chart, with the busiest day maxStairWidth wide.
+func RenderWeekdaysToWriter(w io.Writer, byWeekday []stats.WeekdayPairing) error {
+	var b strings.Builder
+
+	maxPairDays := 0
+	for _, day := range byWeekday {
+		maxPairDays = max(maxPairDays, day.PairDays)
+	}
+
+	b.WriteString("Pairing by Day of Week (days paired together):\n")
+	for _, day := range byWeekday {
+		width := 0
+		if maxPairDays > 0 {
+			width = stairWidth(day.PairDays, maxPairDays)
+		}
+		fmt.Fprintf(&b, "  %s  %-*s  %d\n", day.Weekday.String()[:3], maxStairWidth, strings.Repeat("#", width), day.PairDays)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,61 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/output"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/stats"
+)
+
+func TestRenderWeekdaysToWriter(t *testing.T) {
+	byWeekday := []stats.WeekdayPairing{
+		{Weekday: time.Monday, PairDays: 4},
+		{Weekday: time.Tuesday, PairDays: 1},
+		{Weekday: time.Wednesday},
+		{Weekday: time.Thursday},
+		{Weekday: time.Friday, PairDays: 2},
+		{Weekday: time.Saturday},
+		{Weekday: time.Sunday},
+	}
+
+	var result strings.Builder
+	if err := output.RenderWeekdaysToWriter(&result, byWeekday); err != nil {
+		t.Fatalf("RenderWeekdaysToWriter failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(result.String(), "\n"), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected a heading and 7 days, got:\n%s", result.String())
+	}
+	tests := []struct {
+		line     string
+		day      string
+		barWidth int
+	}{
+		{lines[1], "Mon", 40},
+		{lines[2], "Tue", 10},
+		{lines[3], "Wed", 0},
+		{lines[5], "Fri", 20},
+	}
+	for _, tt := range tests {
+		if !strings.HasPrefix(tt.line, "  "+tt.day) {
+			t.Errorf("Expected line for %s, got %q", tt.day, tt.line)
+		}
+		if got := strings.Count(tt.line, "#"); got != tt.barWidth {
+			t.Errorf("Expected %s bar %d wide, got %d in %q", tt.day, tt.barWidth, got, tt.line)
+		}
+	}
+}
+
+func TestRenderWeekdaysToWriterWithNoPairing(t *testing.T) {
+	var result strings.Builder
+	if err := output.RenderWeekdaysToWriter(&result, stats.PairingByWeekday(pairing.NewParticipation())); err != nil {
+		t.Fatalf("RenderWeekdaysToWriter failed: %v", err)
+	}
+	if strings.Contains(result.String(), "#") {
+		t.Errorf("Expected no bars without pairing, got:\n%s", result.String())
+	}
+}
@@ -0,0 +1,28 @@
+package pairing
+
+import (
+	"slices"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// ActiveDevelopers returns the developers who authored or co-authored any of the
+// commits, under any of their emails, in the order given. In team mode the matrix
+// lists every team member, so this is how to tell who actually committed.
+func ActiveDevelopers(developers []git.Developer, commits []git.Commit) []git.Developer {
+	committed := make(map[string]bool)
+	for _, c := range commits {
+		committed[c.Author.CanonicalEmail()] = true
+		for _, coAuthor := range c.CoAuthors {
+			committed[coAuthor.CanonicalEmail()] = true
+		}
+	}
+
+	var active []git.Developer
+	for _, dev := range developers {
+		if slices.ContainsFunc(dev.EmailAddresses, func(email string) bool { return committed[email] }) {
+			active = append(active, dev)
+		}
+	}
+	return active
+}
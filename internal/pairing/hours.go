@@ -0,0 +1,54 @@
+package pairing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Hours is a daily range of working hours, from Start up to but not including
+// End, as times since midnight. A range whose end is before its start runs over
+// midnight. The zero value means all hours.
+type Hours struct {
+	Start, End time.Duration
+}
+
+// ParseHours converts a range such as "09:00-18:00" to Hours. An empty string is
+// all hours.
+func ParseHours(s string) (Hours, error) {
+	if s == "" {
+		return Hours{}, nil
+	}
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return Hours{}, fmt.Errorf("invalid hours: %s (expected a range such as '09:00-18:00')", s)
+	}
+	startTime, startErr := time.Parse("15:04", strings.TrimSpace(start))
+	endTime, endErr := time.Parse("15:04", strings.TrimSpace(end))
+	if startErr != nil || endErr != nil || startTime.Equal(endTime) {
+		return Hours{}, fmt.Errorf("invalid hours: %s (expected a range such as '09:00-18:00')", s)
+	}
+	return Hours{Start: sinceMidnight(startTime), End: sinceMidnight(endTime)}, nil
+}
+
+// IsZero reports whether the hours are all hours
+func (h Hours) IsZero() bool {
+	return h == Hours{}
+}
+
+// Contains reports whether the time of day of t, in its own location, is within the hours
+func (h Hours) Contains(t time.Time) bool {
+	if h.IsZero() {
+		return true
+	}
+	clock := sinceMidnight(t)
+	if h.End < h.Start {
+		return clock >= h.Start || clock < h.End
+	}
+	return clock >= h.Start && clock < h.End
+}
+
+// sinceMidnight returns the time of day of t as a duration since midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
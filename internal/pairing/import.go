@@ -0,0 +1,74 @@
+package pairing
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// ReadImportFile reads the pairings recorded outside git from the CSV file at
+// path, as ReadImport does
+func ReadImportFile(path string) ([]git.Commit, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadImport(f)
+}
+
+// ReadImport reads pairings recorded outside git, such as in a ticket tracker,
+// from CSV with a "date,emailA,emailB" line for each pairing. Dates are
+// YYYY-MM-DD in local time, and a header line is skipped.
+//
+// Each pairing becomes a commit by the first developer with the second as a
+// co-author, so it is counted alongside the pairing in git: a pair who paired on
+// the same day in a commit and in the import counts once. The commits are made at
+// noon, so they fall within any -hours that include the middle of the day.
+func ReadImport(r io.Reader) ([]git.Commit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var commits []git.Commit
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return commits, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(record[0]), time.Local)
+		if err != nil {
+			if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid date %q (expected YYYY-MM-DD)", line, record[0])
+		}
+		a, b := strings.ToLower(strings.TrimSpace(record[1])), strings.ToLower(strings.TrimSpace(record[2]))
+		for _, email := range []string{a, b} {
+			if !git.ValidEmail(email) {
+				return nil, fmt.Errorf("line %d: invalid email %q", line, email)
+			}
+		}
+		if a == b {
+			return nil, fmt.Errorf("line %d: %s can't pair with themselves", line, a)
+		}
+
+		commits = append(commits, git.Commit{
+			Date:      time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.Local),
+			Author:    git.NewDeveloper("<" + a + ">"),
+			CoAuthors: []git.Developer{git.NewDeveloper("<" + b + ">")},
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package pairing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// LabelStyle is how developers' abbreviated names, the labels used in the
+// matrix and recommendations, are made
+type LabelStyle string
+
+const (
+	// InitialsLabels are the initials of the developer's name, e.g. "JD"
+	InitialsLabels LabelStyle = "initials"
+	// FirstNameLabels are the first word of the developer's name, e.g. "Jane"
+	FirstNameLabels LabelStyle = "firstname"
+	// EmailPrefixLabels are the local part of the developer's email, e.g. "jane.doe"
+	EmailPrefixLabels LabelStyle = "emailprefix"
+)
+
+// ParseLabelStyle converts a label style name, or a number of characters to take
+// from the start of the name, to a LabelStyle
+func ParseLabelStyle(s string) (LabelStyle, error) {
+	switch LabelStyle(s) {
+	case InitialsLabels, FirstNameLabels, EmailPrefixLabels:
+		return LabelStyle(s), nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return LabelStyle(s), nil
+	}
+	return "", fmt.Errorf("invalid labels: %s (expected 'initials', 'firstname', 'emailprefix' or a number of characters)", s)
+}
+
+// label makes a developer's label in this style, before any clashes are resolved.
+// Developers without a usable display name are labelled from their email instead.
+func (s LabelStyle) label(dev git.Developer) string {
+	name := strings.TrimSpace(dev.DisplayName)
+	local, _, _ := strings.Cut(dev.CanonicalEmail(), "@")
+	if hasBareEmailName(dev) {
+		name = strings.Join(emailWords(local), " ")
+	}
+
+	switch s {
+	case "", InitialsLabels:
+		if hasBareEmailName(dev) {
+			return initialsFromEmail(dev.CanonicalEmail())
+		}
+		return git.Initials(name)
+	case FirstNameLabels:
+		first := strings.Fields(name)
+		if len(first) == 0 {
+			return "??"
+		}
+		return capitalize(first[0])
+	case EmailPrefixLabels:
+		return local
+	}
+
+	// A number of characters from the start of the name, ignoring spaces
+	n, _ := strconv.Atoi(string(s))
+	runes := []rune(strings.Join(strings.Fields(name), ""))
+	if len(runes) == 0 {
+		return "??"
+	}
+	return capitalize(string(runes[:min(n, len(runes))]))
+}
+
+// AssignLabels sets each developer's abbreviated name in the given style. Labels
+// that clash with another developer's get a number appended ("JD", "JD2", ...).
+// Developers with a display name have first claim on a label, in the order given,
+// ahead of those labelled from their email address.
+func AssignLabels(devs []git.Developer, style LabelStyle) {
+	var named, bare []int
+	for i, dev := range devs {
+		if hasBareEmailName(dev) {
+			bare = append(bare, i)
+		} else {
+			named = append(named, i)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, i := range append(named, bare...) {
+		label := style.label(devs[i])
+		unique := label
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s%d", label, n)
+		}
+		used[unique] = true
+		devs[i].AbbreviatedName = unique
+	}
+}
+
+// hasBareEmailName reports whether a developer has no display name beyond their email
+func hasBareEmailName(dev git.Developer) bool {
+	name := strings.TrimSpace(dev.DisplayName)
+	return name == "" || strings.EqualFold(name, dev.CanonicalEmail())
+}
+
+// emailWords splits the local part of an email address into words, treating
+// '.', '_', '-' and '+' as word breaks
+func emailWords(local string) []string {
+	return strings.FieldsFunc(local, func(r rune) bool {
+		return r == '.' || r == '_' || r == '-' || r == '+'
+	})
+}
+
+// initialsFromEmail derives initials from the local part of an email address,
+// so "jane.doe@example.com" gives "JD". A local part with a single word gives
+// its first two letters.
+func initialsFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	words := emailWords(local)
+
+	switch len(words) {
+	case 0:
+		return "??"
+	case 1:
+		runes := []rune(words[0])
+		return strings.ToUpper(string(runes[:min(2, len(runes))]))
+	}
+
+	var initials strings.Builder
+	for _, word := range words {
+		initials.WriteString(strings.ToUpper(string([]rune(word)[0])))
+	}
+	return initials.String()
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -6,6 +6,8 @@
 package pairing
 
 import (
+	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,9 +21,15 @@ type Pair struct {
 	A, B string
 }
 
-// Matrix tracks how many times each pair of developers has worked together
+// Matrix tracks how many times each pair of developers has worked together.
+// Alongside the count of days it keeps a weight, which is the same as the count
+// unless pairings have been added with a smaller weight (see MobWeightSplit), and
+// a directed tally of the commits one of the pair authored with the other as co-author.
 type Matrix struct {
-	data map[Pair]int
+	data         map[Pair]int
+	weights      map[Pair]float64
+	weighted     bool
+	attributions map[Pair]int // A is the author, B the co-author
 }
 
 // RecencyMatrix tracks when each pair of developers last worked together
@@ -31,7 +39,7 @@ type RecencyMatrix struct {
 
 // NewMatrix creates a new empty pairing matrix
 func NewMatrix() *Matrix {
-	return &Matrix{data: make(map[Pair]int)}
+	return &Matrix{data: make(map[Pair]int), weights: make(map[Pair]float64), attributions: make(map[Pair]int)}
 }
 
 // NewRecencyMatrix creates a new empty recency matrix
@@ -58,8 +66,27 @@ func (m *Matrix) CountByDeveloper(a, b git.Developer) int {
 	return m.Count(a.CanonicalEmail(), b.CanonicalEmail())
 }
 
+// HasPaired reports whether a pair has ever worked together. A developer has never
+// paired with themselves.
+func (m *Matrix) HasPaired(a, b string) bool {
+	return m.Count(a, b) > 0
+}
+
 // Add increments the count for a pair of developers
 func (m *Matrix) Add(a, b string) {
+	m.add(a, b, 1)
+}
+
+// AddWeighted increments the count for a pair of developers, adding the given
+// weight rather than a whole pairing
+func (m *Matrix) AddWeighted(a, b string, weight float64) {
+	if a != b {
+		m.weighted = true
+	}
+	m.add(a, b, weight)
+}
+
+func (m *Matrix) add(a, b string, weight float64) {
 	if a == b {
 		return // Skip self-pairs
 	}
@@ -70,6 +97,50 @@ func (m *Matrix) Add(a, b string) {
 	}
 
 	m.data[Pair{A: a, B: b}]++
+	m.weights[Pair{A: a, B: b}] += weight
+}
+
+// Weight returns the weighted amount a pair has worked together
+func (m *Matrix) Weight(a, b string) float64 {
+	if a == b {
+		return 0
+	}
+
+	// Ensure consistent ordering
+	if a > b {
+		a, b = b, a
+	}
+
+	return m.weights[Pair{A: a, B: b}]
+}
+
+// WeightByDeveloper returns the weighted amount a pair of developers has worked together
+func (m *Matrix) WeightByDeveloper(a, b git.Developer) float64 {
+	return m.Weight(a.CanonicalEmail(), b.CanonicalEmail())
+}
+
+// Weighted reports whether any pairing was added with AddWeighted, meaning the
+// weights may differ from the counts
+func (m *Matrix) Weighted() bool {
+	return m.weighted
+}
+
+// AddAttribution records a commit made by the author with the co-author. Unlike the
+// pairing counts, attributions are directed and counted per commit.
+func (m *Matrix) AddAttribution(author, coAuthor string) {
+	if author != coAuthor {
+		m.attributions[Pair{A: author, B: coAuthor}]++
+	}
+}
+
+// Attributions returns the number of commits the author made with the co-author
+func (m *Matrix) Attributions(author, coAuthor string) int {
+	return m.attributions[Pair{A: author, B: coAuthor}]
+}
+
+// AttributionsByDeveloper returns the number of commits the author made with the co-author
+func (m *Matrix) AttributionsByDeveloper(author, coAuthor git.Developer) int {
+	return m.Attributions(author.CanonicalEmail(), coAuthor.CanonicalEmail())
 }
 
 // AddByDeveloper increments the count for a pair of developers
@@ -124,8 +195,205 @@ func (m *Matrix) Len() int {
 	return len(m.data)
 }
 
+// Clone returns an independent copy of the matrix
+func (m *Matrix) Clone() *Matrix {
+	clone := NewMatrix()
+	for p, count := range m.data {
+		clone.data[p] = count
+	}
+	for p, weight := range m.weights {
+		clone.weights[p] = weight
+	}
+	clone.weighted = m.weighted
+	for p, count := range m.attributions {
+		clone.attributions[p] = count
+	}
+	return clone
+}
+
+// Subset returns a new matrix with only the pairs where both developers are among
+// the given emails, for example to look at a sub-team without reading the commits
+// again. The matrix itself is unchanged.
+func (m *Matrix) Subset(emails []string) *Matrix {
+	included := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		included[email] = true
+	}
+	within := func(p Pair) bool {
+		return included[p.A] && included[p.B]
+	}
+
+	subset := NewMatrix()
+	for p, count := range m.data {
+		if within(p) {
+			subset.data[p] = count
+		}
+	}
+	for p, weight := range m.weights {
+		if within(p) {
+			subset.weights[p] = weight
+		}
+	}
+	subset.weighted = m.weighted
+	for p, count := range m.attributions {
+		if within(p) {
+			subset.attributions[p] = count
+		}
+	}
+	return subset
+}
+
+// Merge adds the other matrix's counts, weights and attributions to this one.
+//
+// The merge is naive: a pair who worked together on the same day in two sources
+// (say, two repositories) is counted twice, where building one matrix from all the
+// commits counts that day once. Deduplicate the commits and build a single matrix
+// when that matters.
+func (m *Matrix) Merge(other *Matrix) {
+	for p, count := range other.data {
+		m.data[p] += count
+	}
+	for p, weight := range other.weights {
+		m.weights[p] += weight
+	}
+	m.weighted = m.weighted || other.weighted
+	for p, count := range other.attributions {
+		m.attributions[p] += count
+	}
+}
+
+// Merge records the other recency matrix's pairings in this one, keeping the
+// later date for pairs in both
+func (r *RecencyMatrix) Merge(other *RecencyMatrix) {
+	for p, date := range other.data {
+		r.Record(p.A, p.B, date)
+	}
+}
+
+// Clone returns an independent copy of the recency matrix
+func (r *RecencyMatrix) Clone() *RecencyMatrix {
+	clone := NewRecencyMatrix()
+	for p, date := range r.data {
+		clone.data[p] = date
+	}
+	return clone
+}
+
+// MobWeight controls how much each pair in a commit with several co-authors counts
+type MobWeight string
+
+const (
+	// MobWeightEqual counts every pair in a commit as a full pairing
+	MobWeightEqual MobWeight = "equal"
+	// MobWeightSplit divides a commit's single pairing between all of its pairs,
+	// so a mob of four (six pairs) adds 1/6 to each
+	MobWeightSplit MobWeight = "split"
+)
+
+// ParseMobWeight converts a mob weight name to a MobWeight
+func ParseMobWeight(s string) (MobWeight, error) {
+	switch MobWeight(s) {
+	case MobWeightEqual, MobWeightSplit:
+		return MobWeight(s), nil
+	default:
+		return "", fmt.Errorf("invalid mob weight: %s (expected 'equal' or 'split')", s)
+	}
+}
+
+// BuildOptions controls which commits are counted when building the pair matrix
+type BuildOptions struct {
+	// MobWeight is how pairs in mob commits are weighted. The zero value means MobWeightEqual.
+	MobWeight MobWeight
+	// ExcludeToday drops commits made on the current day, so that pairings
+	// still in progress don't show up as "paired today"
+	ExcludeToday bool
+	// Now is the current time, whose location decides where the day boundary
+	// falls. The zero value means time.Now().
+	Now time.Time
+	// PairsOnly ignores the pairing in mob commits (those with more than two
+	// participants) instead of counting each pair within them
+	PairsOnly bool
+	// Hours only counts commits made during these working hours, in the
+	// location of Now. The zero value means all hours.
+	Hours Hours
+	// Labels is how developers' abbreviated names are made. The zero value means InitialsLabels.
+	Labels LabelStyle
+	// Names maps emails to display names to use for developers the team file
+	// doesn't give a name, or without a team, in place of the first name seen
+	Names map[string]string
+	// MobsOnly ignores the pairing in two-person commits, so the matrix shows
+	// who has mobbed together
+	MobsOnly bool
+	// Only restricts the matrix to the developers with these emails. Everyone else
+	// is dropped from each commit, and commits left with fewer than two of them
+	// are skipped. Unlike a team, it needs no names. Empty means everyone.
+	Only []string
+}
+
+// onlySet returns the primary emails of the Only developers, or nil if everyone counts
+func (o BuildOptions) onlySet(emailToPrimaryEmail map[string]string, useTeam bool) map[string]bool {
+	if len(o.Only) == 0 {
+		return nil
+	}
+	only := make(map[string]bool, len(o.Only))
+	for _, email := range o.Only {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if primaryEmail, ok := emailToPrimaryEmail[email]; ok && useTeam {
+			email = primaryEmail
+		}
+		only[email] = true
+	}
+	return only
+}
+
+// countsPairing reports whether the pairing between a commit's participants is counted
+func (o BuildOptions) countsPairing(participants []string) bool {
+	switch {
+	case len(participants) < 2:
+		return false
+	case o.PairsOnly && len(participants) > 2:
+		return false
+	case o.MobsOnly && len(participants) == 2:
+		return false
+	}
+	return true
+}
+
+// Filter returns the commits that should be counted under these options
+func (o BuildOptions) Filter(commits []git.Commit) []git.Commit {
+	if !o.ExcludeToday && o.Hours.IsZero() {
+		return commits
+	}
+
+	now := o.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	today := now.Format("2006-01-02")
+
+	filtered := make([]git.Commit, 0, len(commits))
+	for _, c := range commits {
+		date := c.Date.In(now.Location())
+		if o.ExcludeToday && date.Format("2006-01-02") == today {
+			continue
+		}
+		if o.Hours.Contains(date) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
 // BuildPairMatrix constructs a pair matrix from the commits and team data
 func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matrix, *RecencyMatrix, []git.Developer) {
+	return BuildPairMatrixWithOptions(team, commits, useTeam, BuildOptions{})
+}
+
+// BuildPairMatrixWithOptions constructs a pair matrix from the commits and team
+// data, counting only the commits selected by the options
+func BuildPairMatrixWithOptions(team team.Team, commits []git.Commit, useTeam bool, options BuildOptions) (*Matrix, *RecencyMatrix, []git.Developer) {
+	commits = options.Filter(commits)
+
 	// Maps to track emails and names
 	emailToName := make(map[string]string)
 	emailToPrimaryEmail := make(map[string]string)
@@ -136,95 +404,70 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 		emailToName, emailToPrimaryEmail = team.GetEmailMappings()
 	}
 
-	datePairs := make(map[string]map[Pair]struct{})
+	// The weight of each pair on each date. A pair counts at most once per day, at
+	// the largest weight of any of that day's commits.
+	datePairs := make(map[string]map[Pair]float64)
+	attributions := make(map[Pair]int)
 	devsSet := make(map[string]struct{})
+	only := options.onlySet(emailToPrimaryEmail, useTeam)
+	counted := func(email string) bool {
+		return only == nil || only[email]
+	}
 
 	for _, c := range commits {
-		var devsInCommit []git.Developer
-		if useTeam {
-			// When using team mode, include commits where any participant is a team member
-			var teamMembers []git.Developer
-			
-			// Check if author is in team
-			authorEmail := c.Author.CanonicalEmail()
-			if team.HasDeveloperByEmail(authorEmail) {
-				teamMembers = append(teamMembers, c.Author)
-			}
-
-			// Filter co-authors to only include team members
-			for _, ca := range c.CoAuthors {
-				coAuthorEmail := ca.CanonicalEmail()
-				if team.HasDeveloperByEmail(coAuthorEmail) {
-					teamMembers = append(teamMembers, ca)
-				}
-			}
-			
-			// Skip commits where no participants are team members
-			if len(teamMembers) == 0 {
-				continue
-			}
-			
-			devsInCommit = teamMembers
-		} else {
-			devsInCommit = append([]git.Developer{c.Author}, c.CoAuthors...)
-
-			for _, d := range devsInCommit {
+		if !useTeam {
+			for _, d := range append([]git.Developer{c.Author}, c.CoAuthors...) {
 				email := d.CanonicalEmail()
-				if _, ok := emailToName[email]; !ok {
+				// Imported pairings have no names, so a name from any commit wins
+				if _, ok := emailToName[email]; !ok && d.DisplayName != "" {
 					emailToName[email] = d.DisplayName
 				}
+				if name, ok := options.Names[email]; ok {
+					emailToName[email] = name
+				}
 			}
 		}
 
-		// Create a set of unique developers (by primary email)
-		emailMap := make(map[string]struct{})
-		for _, d := range devsInCommit {
-			email := d.CanonicalEmail()
-
-			// If using team, map to primary email
-			if useTeam {
-				if primaryEmail, ok := emailToPrimaryEmail[email]; ok {
-					emailMap[primaryEmail] = struct{}{}
-				} else {
-					// Not using team or email not in team
-					emailMap[email] = struct{}{}
-				}
-			} else {
-				// When not using team, each email is its own developer
-				// We don't try to consolidate different emails for the same person
-				emailMap[email] = struct{}{}
+		uniqueDevs := commitParticipants(team, c, useTeam)
+		if only != nil {
+			uniqueDevs = slices.DeleteFunc(uniqueDevs, func(email string) bool { return !only[email] })
+			if len(uniqueDevs) < 2 {
+				continue
 			}
+		}
+
+		// Track all developers we've seen
+		for _, email := range uniqueDevs {
+			devsSet[email] = struct{}{}
+		}
 
-			// Track all developers we've seen
-			if useTeam {
-				if primaryEmail, ok := emailToPrimaryEmail[email]; ok {
-					devsSet[primaryEmail] = struct{}{}
-				} else {
-					devsSet[email] = struct{}{}
+		if !options.countsPairing(uniqueDevs) {
+			continue
+		}
+
+		if author, ok := participantEmail(emailToPrimaryEmail, c.Author, useTeam); ok && counted(author) {
+			for _, coAuthor := range c.CoAuthors {
+				if email, ok := participantEmail(emailToPrimaryEmail, coAuthor, useTeam); ok && email != author && counted(email) {
+					attributions[Pair{A: author, B: email}]++
 				}
-			} else {
-				devsSet[email] = struct{}{}
 			}
 		}
 
-		uniqueDevs := make([]string, 0, len(emailMap))
-		for e := range emailMap {
-			uniqueDevs = append(uniqueDevs, e)
-		}
-		if len(uniqueDevs) < 2 {
-			continue
+		weight := 1.0
+		if options.MobWeight == MobWeightSplit {
+			pairCount := len(uniqueDevs) * (len(uniqueDevs) - 1) / 2
+			weight = 1 / float64(pairCount)
 		}
 
 		// Create pairs for this date
-		sort.Strings(uniqueDevs)
 		date := c.Date.Format("2006-01-02")
 		if _, ok := datePairs[date]; !ok {
-			datePairs[date] = make(map[Pair]struct{})
+			datePairs[date] = make(map[Pair]float64)
 		}
 		for i := 0; i < len(uniqueDevs); i++ {
 			for j := i + 1; j < len(uniqueDevs); j++ {
 				p := Pair{A: uniqueDevs[i], B: uniqueDevs[j]}
-				datePairs[date][p] = struct{}{}
+				datePairs[date][p] = max(datePairs[date][p], weight)
 			}
 		}
 	}
@@ -246,10 +489,12 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 						allEmails = append(allEmails, teamEmail)
 					}
 				}
+				if strings.TrimSpace(name) == "" {
+					name = nameForEmails(options.Names, allEmails)
+				}
 				dev = git.Developer{
-					DisplayName:     name,
-					EmailAddresses:  allEmails,
-					AbbreviatedName: makeAbbreviatedName(name),
+					DisplayName:    name,
+					EmailAddresses: allEmails,
 				}
 			} else {
 				// Fallback: create from email
@@ -259,9 +504,8 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 			// For non-team mode, use the display name we captured from commits
 			if name, exists := emailToName[email]; exists && name != "" {
 				dev = git.Developer{
-					DisplayName:     name,
-					EmailAddresses:  []string{email},
-					AbbreviatedName: makeAbbreviatedName(name),
+					DisplayName:    name,
+					EmailAddresses: []string{email},
 				}
 			} else {
 				// Fallback: create from email
@@ -275,7 +519,7 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 	if useTeam {
 		for _, dev := range team.GetDevelopers() {
 			primaryEmail := dev.CanonicalEmail()
-			if _, ok := devsSet[primaryEmail]; !ok {
+			if _, ok := devsSet[primaryEmail]; !ok && counted(primaryEmail) {
 				emailToDevs[primaryEmail] = dev
 			}
 		}
@@ -292,9 +536,12 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 	for i, email := range devEmails {
 		devs[i] = emailToDevs[email]
 	}
+	AssignLabels(devs, options.Labels)
 
 	// Build final matrix and recency matrix
 	matrix := NewMatrix()
+	matrix.weighted = options.MobWeight == MobWeightSplit
+	matrix.attributions = attributions
 	recencyMatrix := NewRecencyMatrix()
 	
 	// Sort dates to process in chronological order
@@ -306,41 +553,155 @@ func BuildPairMatrix(team team.Team, commits []git.Commit, useTeam bool) (*Matri
 	
 	for _, date := range sortedDates {
 		pairs := datePairs[date]
-		seen := make(map[Pair]struct{})
-		for p := range pairs {
-			if _, ok := seen[p]; !ok {
-				matrix.data[p]++
-				// Parse the date and update recency
-				if commitDate, err := time.Parse("2006-01-02", date); err == nil {
-					recencyMatrix.data[p] = commitDate
-				}
-				seen[p] = struct{}{}
+		for p, weight := range pairs {
+			matrix.data[p]++
+			matrix.weights[p] += weight
+			// Parse the date and update recency
+			if commitDate, err := time.Parse("2006-01-02", date); err == nil {
+				recencyMatrix.data[p] = commitDate
 			}
 		}
 	}
 	return matrix, recencyMatrix, devs
 }
 
-// makeAbbreviatedName creates initials from a full name, similar to the git package's shortName
-func makeAbbreviatedName(name string) string {
-	if name == "" {
-		return "??"
+// nameForEmails returns the name for the first of the emails that has one, or an
+// empty string if none do
+func nameForEmails(names map[string]string, emails []string) string {
+	for _, email := range emails {
+		if name, ok := names[email]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// commitParticipants returns the unique canonical emails of the developers in a commit.
+// When using a team, only team members are included and each email is mapped to
+// the developer's primary email; otherwise each email is its own developer.
+func commitParticipants(team team.Team, c git.Commit, useTeam bool) []string {
+	_, emailToPrimaryEmail := team.GetEmailMappings()
+
+	emailMap := make(map[string]struct{})
+	for _, d := range append([]git.Developer{c.Author}, c.CoAuthors...) {
+		if email, ok := participantEmail(emailToPrimaryEmail, d, useTeam); ok {
+			emailMap[email] = struct{}{}
+		}
 	}
-	
-	words := strings.Fields(name)
-	if len(words) == 0 {
-		return "??"
+
+	uniqueDevs := make([]string, 0, len(emailMap))
+	for e := range emailMap {
+		uniqueDevs = append(uniqueDevs, e)
 	}
+	sort.Strings(uniqueDevs)
+	return uniqueDevs
+}
 
-	initials := make([]string, len(words))
-	for i, word := range words {
-		if len(word) > 0 {
-			initials[i] = strings.ToUpper(string(word[0]))
-		} else {
-			initials[i] = "?"
+// participantEmail returns the email a commit participant is counted under. When
+// using a team only team members are counted, under their primary email; otherwise
+// we don't try to consolidate different emails for the same person.
+func participantEmail(emailToPrimaryEmail map[string]string, d git.Developer, useTeam bool) (string, bool) {
+	email := d.CanonicalEmail()
+	if !useTeam {
+		return email, true
+	}
+	primaryEmail, ok := emailToPrimaryEmail[email]
+	return primaryEmail, ok
+}
+
+// CountSoloCommits returns, for each developer's canonical email, the number of
+// commits they made with no other participant. Team filtering is applied in the
+// same way as BuildPairMatrix, so co-authors outside the team don't count.
+func CountSoloCommits(team team.Team, commits []git.Commit, useTeam bool) map[string]int {
+	soloCommits := make(map[string]int)
+	for _, c := range commits {
+		if participants := commitParticipants(team, c, useTeam); len(participants) == 1 {
+			soloCommits[participants[0]]++
 		}
 	}
+	return soloCommits
+}
 
-	return strings.Join(initials, "")
+// CommitSizes counts commits by how many developers worked on them
+type CommitSizes struct {
+	Solo  int // Commits by one developer
+	Pairs int // Commits by two developers
+	Mobs  int // Commits by three or more developers
+}
+
+// Total returns the number of commits counted
+func (s CommitSizes) Total() int {
+	return s.Solo + s.Pairs + s.Mobs
+}
+
+// Share returns a number of commits as a fraction of the total, or 0 if there are none
+func (s CommitSizes) Share(commits int) float64 {
+	if s.Total() == 0 {
+		return 0
+	}
+	return float64(commits) / float64(s.Total())
 }
 
+// CountCommitSizes counts the commits by their number of participants, before
+// they are split into pairs. Team filtering and the options' Only and Filter are
+// applied in the same way as BuildPairMatrixWithOptions, so participants who
+// don't count there don't count here, and commits with none of them aren't
+// counted at all.
+func CountCommitSizes(team team.Team, commits []git.Commit, useTeam bool, options BuildOptions) CommitSizes {
+	_, emailToPrimaryEmail := team.GetEmailMappings()
+	only := options.onlySet(emailToPrimaryEmail, useTeam)
+
+	var sizes CommitSizes
+	for _, c := range options.Filter(commits) {
+		participants := 0
+		for _, email := range commitParticipants(team, c, useTeam) {
+			if only == nil || only[email] {
+				participants++
+			}
+		}
+		switch {
+		case participants == 0:
+			continue
+		case participants == 1:
+			sizes.Solo++
+		case participants == 2:
+			sizes.Pairs++
+		default:
+			sizes.Mobs++
+		}
+	}
+	return sizes
+}
+
+// CountMissingTrailers returns, for each author's canonical email, the number of
+// commits they authored and the number of those with no co-authors at all, which
+// may be pairing that wasn't recorded. Unlike CountSoloCommits, co-authors outside
+// the team still count, since the pairing was recorded; only the author has to be
+// in the team. Naming yourself as a co-author doesn't count.
+func CountMissingTrailers(team team.Team, commits []git.Commit, useTeam bool) (authored, missing map[string]int) {
+	_, emailToPrimaryEmail := team.GetEmailMappings()
+
+	authored = make(map[string]int)
+	missing = make(map[string]int)
+	for _, c := range commits {
+		email, ok := participantEmail(emailToPrimaryEmail, c.Author, useTeam)
+		if !ok {
+			continue
+		}
+		authored[email]++
+		if !hasOtherCoAuthor(c) {
+			missing[email]++
+		}
+	}
+	return authored, missing
+}
+
+// hasOtherCoAuthor reports whether a commit names a co-author other than its author
+func hasOtherCoAuthor(c git.Commit) bool {
+	for _, coAuthor := range c.CoAuthors {
+		if coAuthor.CanonicalEmail() != c.Author.CanonicalEmail() {
+			return true
+		}
+	}
+	return false
+}
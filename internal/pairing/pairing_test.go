@@ -1,6 +1,7 @@
 package pairing_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -364,3 +365,1013 @@ func TestBuildPairMatrixConsistentPairOrdering(t *testing.T) {
 		t.Errorf("Expected same count regardless of parameter order, got %d vs %d", count1, count2)
 	}
 }
+
+func TestMatrixClone(t *testing.T) {
+	matrix := pairing.NewMatrix()
+	matrix.Add("alice@example.com", "bob@example.com")
+
+	clone := matrix.Clone()
+	clone.Add("alice@example.com", "bob@example.com")
+
+	if matrix.Count("alice@example.com", "bob@example.com") != 1 {
+		t.Errorf("Expected original matrix to be unchanged, got %d", matrix.Count("alice@example.com", "bob@example.com"))
+	}
+	if clone.Count("alice@example.com", "bob@example.com") != 2 {
+		t.Errorf("Expected clone count 2, got %d", clone.Count("alice@example.com", "bob@example.com"))
+	}
+}
+
+func TestMatrixHasPaired(t *testing.T) {
+	matrix := pairing.NewMatrix()
+	matrix.Add("alice@example.com", "bob@example.com")
+	matrix.Add("carol@example.com", "carol@example.com")
+
+	if !matrix.HasPaired("alice@example.com", "bob@example.com") {
+		t.Error("Expected Alice and Bob to have paired")
+	}
+	if !matrix.HasPaired("bob@example.com", "alice@example.com") {
+		t.Error("Expected HasPaired to ignore the order of the pair")
+	}
+	if matrix.HasPaired("alice@example.com", "carol@example.com") {
+		t.Error("Expected Alice and Carol not to have paired")
+	}
+	if matrix.HasPaired("alice@example.com", "alice@example.com") || matrix.HasPaired("carol@example.com", "carol@example.com") {
+		t.Error("Expected self-pairs never to have paired")
+	}
+}
+
+func TestMatrixSubset(t *testing.T) {
+	matrix := pairing.NewMatrix()
+	matrix.Add("alice@example.com", "bob@example.com")
+	matrix.Add("alice@example.com", "bob@example.com")
+	matrix.AddWeighted("bob@example.com", "carol@example.com", 0.5)
+	matrix.Add("carol@example.com", "dave@example.com")
+	matrix.AddAttribution("alice@example.com", "bob@example.com")
+	matrix.AddAttribution("carol@example.com", "dave@example.com")
+
+	subset := matrix.Subset([]string{"alice@example.com", "bob@example.com", "carol@example.com"})
+
+	if subset.Len() != 2 {
+		t.Errorf("Expected the 2 pairs within the subset, got %d", subset.Len())
+	}
+	if subset.Count("alice@example.com", "bob@example.com") != 2 {
+		t.Errorf("Expected Alice and Bob's count of 2, got %d", subset.Count("alice@example.com", "bob@example.com"))
+	}
+	if subset.Weight("bob@example.com", "carol@example.com") != 0.5 || !subset.Weighted() {
+		t.Errorf("Expected Bob and Carol's weight of 0.5, got %v", subset.Weight("bob@example.com", "carol@example.com"))
+	}
+	if subset.Count("carol@example.com", "dave@example.com") != 0 {
+		t.Error("Expected pairs with a developer outside the subset to be left out")
+	}
+	if subset.Attributions("alice@example.com", "bob@example.com") != 1 || subset.Attributions("carol@example.com", "dave@example.com") != 0 {
+		t.Error("Expected only attributions within the subset")
+	}
+
+	// The original is unchanged, including by changes to the subset
+	subset.Add("alice@example.com", "bob@example.com")
+	if matrix.Len() != 3 || matrix.Count("carol@example.com", "dave@example.com") != 1 || matrix.Count("alice@example.com", "bob@example.com") != 2 {
+		t.Error("Expected the original matrix to be unchanged")
+	}
+}
+
+func TestMatrixMerge(t *testing.T) {
+	matrix := pairing.NewMatrix()
+	matrix.Add("alice@example.com", "bob@example.com")
+	matrix.AddAttribution("alice@example.com", "bob@example.com")
+
+	other := pairing.NewMatrix()
+	other.Add("bob@example.com", "alice@example.com")
+	other.AddWeighted("bob@example.com", "carol@example.com", 0.5)
+	other.AddAttribution("alice@example.com", "bob@example.com")
+
+	matrix.Merge(other)
+
+	if matrix.Count("alice@example.com", "bob@example.com") != 2 {
+		t.Errorf("Expected counts to be summed to 2, got %d", matrix.Count("alice@example.com", "bob@example.com"))
+	}
+	if matrix.Weight("alice@example.com", "bob@example.com") != 2 {
+		t.Errorf("Expected weights to be summed to 2, got %v", matrix.Weight("alice@example.com", "bob@example.com"))
+	}
+	if matrix.Count("bob@example.com", "carol@example.com") != 1 || matrix.Weight("bob@example.com", "carol@example.com") != 0.5 || !matrix.Weighted() {
+		t.Error("Expected pairs only in the other matrix to be added with their weight")
+	}
+	if matrix.Attributions("alice@example.com", "bob@example.com") != 2 {
+		t.Errorf("Expected attributions to be summed to 2, got %d", matrix.Attributions("alice@example.com", "bob@example.com"))
+	}
+	if other.Count("alice@example.com", "bob@example.com") != 1 {
+		t.Error("Expected the other matrix to be unchanged")
+	}
+}
+
+func TestRecencyMatrixMerge(t *testing.T) {
+	earlier := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.AddDate(0, 0, 3)
+
+	recency := pairing.NewRecencyMatrix()
+	recency.Record("alice@example.com", "bob@example.com", earlier)
+	recency.Record("alice@example.com", "carol@example.com", later)
+
+	other := pairing.NewRecencyMatrix()
+	other.Record("bob@example.com", "alice@example.com", later)
+	other.Record("alice@example.com", "carol@example.com", earlier)
+	other.Record("bob@example.com", "carol@example.com", earlier)
+
+	recency.Merge(other)
+
+	tests := []struct {
+		a, b     string
+		expected time.Time
+	}{
+		{"alice@example.com", "bob@example.com", later},
+		{"alice@example.com", "carol@example.com", later},
+		{"bob@example.com", "carol@example.com", earlier},
+	}
+	for _, tt := range tests {
+		if last, ok := recency.LastPaired(tt.a, tt.b); !ok || !last.Equal(tt.expected) {
+			t.Errorf("Expected %s and %s to have last paired %v, got %v", tt.a, tt.b, tt.expected, last)
+		}
+	}
+}
+
+func TestRecencyMatrixClone(t *testing.T) {
+	recency := pairing.NewRecencyMatrix()
+	original := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	recency.Record("alice@example.com", "bob@example.com", original)
+
+	clone := recency.Clone()
+	clone.Record("alice@example.com", "bob@example.com", original.AddDate(0, 0, 1))
+
+	if last, _ := recency.LastPaired("alice@example.com", "bob@example.com"); !last.Equal(original) {
+		t.Errorf("Expected original recency to be unchanged, got %v", last)
+	}
+	if last, _ := clone.LastPaired("alice@example.com", "bob@example.com"); !last.Equal(original.AddDate(0, 0, 1)) {
+		t.Errorf("Expected clone to record the later date, got %v", last)
+	}
+}
+
+func TestCountSoloCommits(t *testing.T) {
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day, Author: git.NewDeveloper("Alice Smith <alice@example.com>")},
+		{Date: day, Author: git.NewDeveloper("Alice Smith <alice@example.com>")},
+		{
+			Date:      day,
+			Author:    git.NewDeveloper("Alice Smith <alice@example.com>"),
+			CoAuthors: []git.Developer{git.NewDeveloper("Bob Jones <bob@example.com>")},
+		},
+		// Listing yourself as a co-author is still a solo commit
+		{
+			Date:      day,
+			Author:    git.NewDeveloper("Bob Jones <bob@example.com>"),
+			CoAuthors: []git.Developer{git.NewDeveloper("Bob Jones <bob@example.com>")},
+		},
+	}
+
+	soloCommits := pairing.CountSoloCommits(team.Empty, commits, false)
+
+	if soloCommits["alice@example.com"] != 2 {
+		t.Errorf("Expected 2 solo commits for Alice, got %d", soloCommits["alice@example.com"])
+	}
+	if soloCommits["bob@example.com"] != 1 {
+		t.Errorf("Expected 1 solo commit for Bob, got %d", soloCommits["bob@example.com"])
+	}
+}
+
+func TestCountCommitSizes(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	outsider := git.NewDeveloper("Olly Outsider <olly@example.org>")
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		{Date: day, Author: alice},
+		{Date: day, Author: bob, CoAuthors: []git.Developer{bob}},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day, Author: carol, CoAuthors: []git.Developer{alice}},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{outsider}},
+		{Date: day, Author: outsider, CoAuthors: []git.Developer{bob, carol}},
+		{Date: day, Author: outsider},
+	}
+
+	t.Run("without a team", func(t *testing.T) {
+		sizes := pairing.CountCommitSizes(team.Empty, commits, false, pairing.BuildOptions{})
+		if want := (pairing.CommitSizes{Solo: 3, Pairs: 3, Mobs: 2}); sizes != want {
+			t.Errorf("Expected %+v, got %+v", want, sizes)
+		}
+		if share := sizes.Share(sizes.Solo); share != 0.375 {
+			t.Errorf("Expected 37.5%% solo commits, got %v", share)
+		}
+	})
+
+	t.Run("with a team", func(t *testing.T) {
+		// The outsider doesn't count, so their mob is a pair and their own commit
+		// isn't counted at all
+		teamObj := team.NewTeamFromDevelopers([]git.Developer{alice, bob, carol})
+		sizes := pairing.CountCommitSizes(teamObj, commits, true, pairing.BuildOptions{})
+		if want := (pairing.CommitSizes{Solo: 3, Pairs: 3, Mobs: 1}); sizes != want {
+			t.Errorf("Expected %+v, got %+v", want, sizes)
+		}
+		if share := sizes.Share(sizes.Pairs); share != 3.0/7 {
+			t.Errorf("Expected 3 in 7 commits paired, got %v", share)
+		}
+	})
+
+	t.Run("with only some developers", func(t *testing.T) {
+		// As in the matrix, everyone else is dropped, so Alice's mob with Bob is a
+		// pair and her pairs with anyone else are solo commits
+		options := pairing.BuildOptions{Only: []string{"Alice@example.com", "bob@example.com"}}
+		sizes := pairing.CountCommitSizes(team.Empty, commits, false, options)
+		if want := (pairing.CommitSizes{Solo: 5, Pairs: 2}); sizes != want {
+			t.Errorf("Expected %+v, got %+v", want, sizes)
+		}
+	})
+
+	if share := (pairing.CommitSizes{}).Share(0); share != 0 {
+		t.Errorf("Expected a share of 0 with no commits, got %v", share)
+	}
+}
+
+func TestBuildPairMatrixWithOptionsOnly(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		// A mob reduced to Alice and Bob
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		// Alice and Carol's pairing is skipped, leaving only Alice
+		{Date: day.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{carol}},
+		// Carol authors Bob and Dave's pairing
+		{Date: day.AddDate(0, 0, 2), Author: carol, CoAuthors: []git.Developer{bob, dave}},
+	}
+	options := pairing.BuildOptions{Only: []string{"alice@example.com", " Bob@Example.com", "dave@example.com"}}
+
+	t.Run("without a team", func(t *testing.T) {
+		matrix, recency, developers := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, options)
+
+		var emails []string
+		for _, dev := range developers {
+			emails = append(emails, dev.CanonicalEmail())
+		}
+		if strings.Join(emails, ",") != "alice@example.com,bob@example.com,dave@example.com" {
+			t.Errorf("Expected only the allowed developers, got %v", emails)
+		}
+		if count := matrix.Count("alice@example.com", "bob@example.com"); count != 1 {
+			t.Errorf("Expected Alice and Bob to have paired once, got %d", count)
+		}
+		if count := matrix.Count("bob@example.com", "dave@example.com"); count != 1 {
+			t.Errorf("Expected Bob and Dave to have paired once, got %d", count)
+		}
+		if count := matrix.Count("alice@example.com", "carol@example.com"); count != 0 {
+			t.Errorf("Expected Carol's pairing not to count, got %d", count)
+		}
+		if last, _ := recency.LastPaired("alice@example.com", "bob@example.com"); last.Format("2006-01-02") != "2024-06-03" {
+			t.Errorf("Expected Alice and Bob to have last paired on 2024-06-03, got %v", last)
+		}
+		if matrix.Attributions("carol@example.com", "bob@example.com") != 0 {
+			t.Error("Expected no attributions to Carol")
+		}
+	})
+
+	t.Run("with a team", func(t *testing.T) {
+		teamObj, err := team.NewTeam([]string{
+			"Alice Smith <alice@example.com>,<alice@home.example.com>",
+			"Bob Jones <bob@example.com>",
+			"Carol Davis <carol@example.com>",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create team: %v", err)
+		}
+		// Alice is allowed by her other email
+		matrix, _, developers := pairing.BuildPairMatrixWithOptions(teamObj, commits, true, pairing.BuildOptions{Only: []string{"alice@home.example.com", "bob@example.com"}})
+
+		if len(developers) != 2 {
+			t.Errorf("Expected the team to be narrowed to Alice and Bob, got %v", developers)
+		}
+		if count := matrix.Count("alice@example.com", "bob@example.com"); count != 1 {
+			t.Errorf("Expected Alice and Bob to have paired once, got %d", count)
+		}
+	})
+}
+
+func TestBuildPairMatrixWithObserver(t *testing.T) {
+	teamObj, err := team.NewTeam([]string{
+		"Alice Smith <alice@example.com>",
+		"Mo Manager <mo@example.com> @observer",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+	commits := []git.Commit{{
+		Date:      time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Author:    git.NewDeveloper("Alice Smith <alice@example.com>"),
+		CoAuthors: []git.Developer{git.NewDeveloper("Mo Manager <mo@example.com>")},
+	}}
+
+	matrix, _, developers := pairing.BuildPairMatrix(teamObj, commits, true)
+	if len(developers) != 2 {
+		t.Errorf("Expected the observer in the matrix, got %v", developers)
+	}
+	if count := matrix.Count("alice@example.com", "mo@example.com"); count != 1 {
+		t.Errorf("Expected Alice's pairing with the observer to count, got %d", count)
+	}
+}
+
+func TestCountMissingTrailers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	outsider := git.NewDeveloper("Olly Outsider <olly@example.com>")
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day, Author: alice},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+		// A co-author outside the team is still a recorded pairing
+		{Date: day, Author: alice, CoAuthors: []git.Developer{outsider}},
+		// Naming yourself isn't
+		{Date: day, Author: bob, CoAuthors: []git.Developer{bob}},
+		{Date: day, Author: outsider},
+	}
+
+	authored, missing := pairing.CountMissingTrailers(team.Empty, commits, false)
+	if authored["alice@example.com"] != 3 || missing["alice@example.com"] != 1 {
+		t.Errorf("Expected 1 of 3 of Alice's commits missing trailers, got %d of %d", missing["alice@example.com"], authored["alice@example.com"])
+	}
+	if authored["bob@example.com"] != 1 || missing["bob@example.com"] != 1 {
+		t.Errorf("Expected 1 of 1 of Bob's commits missing trailers, got %d of %d", missing["bob@example.com"], authored["bob@example.com"])
+	}
+	if authored["olly@example.com"] != 1 {
+		t.Errorf("Expected 1 commit for Olly without a team, got %d", authored["olly@example.com"])
+	}
+
+	teamObj := team.NewTeamFromDevelopers([]git.Developer{alice, bob})
+	authored, missing = pairing.CountMissingTrailers(teamObj, commits, true)
+	if authored["alice@example.com"] != 3 || missing["alice@example.com"] != 1 {
+		t.Errorf("Expected 1 of 3 of Alice's commits missing trailers in the team, got %d of %d", missing["alice@example.com"], authored["alice@example.com"])
+	}
+	if _, ok := authored["olly@example.com"]; ok {
+		t.Error("Expected authors outside the team to be skipped")
+	}
+}
+
+func TestBuildPairMatrixWithOptionsExcludeToday(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: now.Add(-2 * time.Hour), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: now.AddDate(0, 0, -1), Author: alice, CoAuthors: []git.Developer{carol}},
+	}
+
+	t.Run("today's commits are counted by default", func(t *testing.T) {
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{Now: now})
+		if count := matrix.CountByDeveloper(alice, bob); count != 1 {
+			t.Errorf("Expected Alice-Bob count 1, got %d", count)
+		}
+	})
+
+	t.Run("today's commits are dropped with ExcludeToday", func(t *testing.T) {
+		matrix, recencyMatrix, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{ExcludeToday: true, Now: now})
+		if count := matrix.CountByDeveloper(alice, bob); count != 0 {
+			t.Errorf("Expected Alice-Bob count 0, got %d", count)
+		}
+		if _, exists := recencyMatrix.LastPairedByDeveloper(alice, bob); exists {
+			t.Error("Expected no recency for a pair that only paired today")
+		}
+		if count := matrix.CountByDeveloper(alice, carol); count != 1 {
+			t.Errorf("Expected Alice-Carol count 1, got %d", count)
+		}
+	})
+
+	t.Run("the day boundary follows Now's location", func(t *testing.T) {
+		// 23:30 UTC on the 11th is already the 12th in UTC+2
+		local := time.FixedZone("UTC+2", 2*60*60)
+		lateCommits := []git.Commit{
+			{Date: time.Date(2024, 6, 11, 23, 30, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+		}
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, lateCommits, false, pairing.BuildOptions{ExcludeToday: true, Now: now.In(local)})
+		if count := matrix.CountByDeveloper(alice, bob); count != 0 {
+			t.Errorf("Expected commit made today in local time to be excluded, got count %d", count)
+		}
+	})
+}
+
+func TestBuildPairMatrixWithOptionsHours(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	day := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day.Add(10 * time.Hour), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day.Add(23 * time.Hour), Author: alice, CoAuthors: []git.Developer{carol}},
+		// The end of the range is excluded
+		{Date: day.Add(18 * time.Hour), Author: carol, CoAuthors: []git.Developer{dave}},
+	}
+	hours, err := pairing.ParseHours("09:00-18:00")
+	if err != nil {
+		t.Fatalf("ParseHours failed: %v", err)
+	}
+	now := day.AddDate(0, 0, 1)
+
+	t.Run("commits outside the hours aren't counted", func(t *testing.T) {
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{Hours: hours, Now: now})
+		if count := matrix.CountByDeveloper(alice, bob); count != 1 {
+			t.Errorf("Expected Alice-Bob count 1, got %d", count)
+		}
+		if count := matrix.CountByDeveloper(alice, carol); count != 0 {
+			t.Errorf("Expected Alice-Carol count 0, got %d", count)
+		}
+		if count := matrix.CountByDeveloper(carol, dave); count != 0 {
+			t.Errorf("Expected Carol-Dave count 0, got %d", count)
+		}
+	})
+
+	t.Run("all hours are counted by default", func(t *testing.T) {
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{Now: now})
+		if count := matrix.CountByDeveloper(alice, carol); count != 1 {
+			t.Errorf("Expected Alice-Carol count 1, got %d", count)
+		}
+	})
+
+	t.Run("the hours follow Now's location", func(t *testing.T) {
+		// 23:00 UTC is 09:00 the next day in UTC+10
+		local := time.FixedZone("UTC+10", 10*60*60)
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{Hours: hours, Now: now.In(local)})
+		if count := matrix.CountByDeveloper(alice, carol); count != 1 {
+			t.Errorf("Expected Alice-Carol count 1, got %d", count)
+		}
+		if count := matrix.CountByDeveloper(alice, bob); count != 0 {
+			t.Errorf("Expected Alice-Bob count 0, got %d", count)
+		}
+	})
+}
+
+func TestParseHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 6, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		input   string
+		wantErr bool
+		inside  []time.Time
+		outside []time.Time
+	}{
+		{input: "", inside: []time.Time{at(0, 0), at(12, 0), at(23, 59)}},
+		{input: "09:00-18:00", inside: []time.Time{at(9, 0), at(17, 59)}, outside: []time.Time{at(8, 59), at(18, 0), at(23, 0)}},
+		{input: " 08:30 - 12:15 ", inside: []time.Time{at(8, 30), at(12, 14)}, outside: []time.Time{at(8, 29), at(12, 15)}},
+		{input: "22:00-06:00", inside: []time.Time{at(23, 0), at(2, 0)}, outside: []time.Time{at(6, 0), at(12, 0)}},
+		{input: "09:00", wantErr: true},
+		{input: "9am-5pm", wantErr: true},
+		{input: "25:00-26:00", wantErr: true},
+		{input: "09:00-09:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hours, err := pairing.ParseHours(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, tm := range tt.inside {
+				if !hours.Contains(tm) {
+					t.Errorf("Expected %s to be within %q", tm.Format("15:04"), tt.input)
+				}
+			}
+			for _, tm := range tt.outside {
+				if hours.Contains(tm) {
+					t.Errorf("Expected %s to be outside %q", tm.Format("15:04"), tt.input)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPairMatrixWithOptionsMobWeight(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	day1 := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	commits := []git.Commit{
+		// A mob of four: six pairs
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob, carol, dave}},
+		// Alice and Bob also paired on their own the same day
+		{Date: day1.Add(time.Hour), Author: alice, CoAuthors: []git.Developer{bob}},
+		// Carol and Dave paired the next day
+		{Date: day2, Author: carol, CoAuthors: []git.Developer{dave}},
+	}
+
+	tests := []struct {
+		name       string
+		mobWeight  pairing.MobWeight
+		a, b       git.Developer
+		wantCount  int
+		wantWeight float64
+	}{
+		{"equal counts mob pairs fully", pairing.MobWeightEqual, alice, carol, 1, 1},
+		{"split divides the mob between its pairs", pairing.MobWeightSplit, alice, carol, 1, 1.0 / 6},
+		{"split keeps the larger weight for a pair seen twice in a day", pairing.MobWeightSplit, alice, bob, 1, 1},
+		{"split adds up weights across days", pairing.MobWeightSplit, carol, dave, 2, 1 + 1.0/6},
+		{"equal adds up weights across days", pairing.MobWeightEqual, carol, dave, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{MobWeight: tt.mobWeight})
+
+			if count := matrix.CountByDeveloper(tt.a, tt.b); count != tt.wantCount {
+				t.Errorf("Expected count %d, got %d", tt.wantCount, count)
+			}
+			if weight := matrix.WeightByDeveloper(tt.a, tt.b); weight < tt.wantWeight-1e-9 || weight > tt.wantWeight+1e-9 {
+				t.Errorf("Expected weight %.4f, got %.4f", tt.wantWeight, weight)
+			}
+			if matrix.Weighted() != (tt.mobWeight == pairing.MobWeightSplit) {
+				t.Errorf("Expected Weighted() to be %v", tt.mobWeight == pairing.MobWeightSplit)
+			}
+		})
+	}
+}
+
+func TestBuildPairMatrixWithOptionsPairsOnly(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+
+	day1 := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		{Date: day1.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{bob}},
+	}
+
+	t.Run("mob commits are split into pairs by default", func(t *testing.T) {
+		matrix, _, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{})
+		if count := matrix.CountByDeveloper(alice, carol); count != 1 {
+			t.Errorf("Expected Alice-Carol count 1, got %d", count)
+		}
+		if count := matrix.CountByDeveloper(alice, bob); count != 2 {
+			t.Errorf("Expected Alice-Bob count 2, got %d", count)
+		}
+	})
+
+	t.Run("mob commits contribute nothing with PairsOnly", func(t *testing.T) {
+		matrix, recencyMatrix, developers := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{PairsOnly: true})
+		if count := matrix.CountByDeveloper(alice, carol); count != 0 {
+			t.Errorf("Expected Alice-Carol count 0, got %d", count)
+		}
+		if count := matrix.CountByDeveloper(bob, carol); count != 0 {
+			t.Errorf("Expected Bob-Carol count 0, got %d", count)
+		}
+		if _, exists := recencyMatrix.LastPairedByDeveloper(alice, carol); exists {
+			t.Error("Expected no recency for a pair that only paired in a mob")
+		}
+		if count := matrix.CountByDeveloper(alice, bob); count != 1 {
+			t.Errorf("Expected Alice-Bob count 1, got %d", count)
+		}
+		if len(developers) != 3 {
+			t.Errorf("Expected mob participants to still be listed as developers, got %d developers", len(developers))
+		}
+	})
+}
+
+func TestBuildPairMatrixWithOptionsPairsOnlyCountsDedicatedDays(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	// Alice and Bob pair on their own on the 10th, between mob commits, then only
+	// mob together on the 11th and 12th
+	day1 := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob, carol, dave}},
+		{Date: day1.Add(2 * time.Hour), Author: bob, CoAuthors: []git.Developer{alice}},
+		{Date: day1.Add(4 * time.Hour), Author: carol, CoAuthors: []git.Developer{alice, bob}},
+		{Date: day1.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		{Date: day1.AddDate(0, 0, 2), Author: dave, CoAuthors: []git.Developer{alice, bob}},
+	}
+
+	for _, mobWeight := range []pairing.MobWeight{pairing.MobWeightEqual, pairing.MobWeightSplit} {
+		t.Run(string(mobWeight), func(t *testing.T) {
+			matrix, recencyMatrix, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{PairsOnly: true, MobWeight: mobWeight})
+			if count := matrix.CountByDeveloper(alice, bob); count != 1 {
+				t.Errorf("Expected Alice-Bob to count only their dedicated day, got %d", count)
+			}
+			if weight := matrix.WeightByDeveloper(alice, bob); weight != 1 {
+				t.Errorf("Expected the dedicated day to count in full, got %v", weight)
+			}
+			if last, _ := recencyMatrix.LastPairedByDeveloper(alice, bob); !last.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("Expected Alice-Bob to have last paired on their dedicated day, not a mob-only day, got %v", last)
+			}
+			if count := matrix.CountByDeveloper(alice, carol); count != 0 {
+				t.Errorf("Expected Alice-Carol, who only mobbed, to count 0, got %d", count)
+			}
+		})
+	}
+}
+
+func TestBuildPairMatrixWithOptionsMobsOnly(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	day1 := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		{Date: day1.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day1.AddDate(0, 0, 2), Author: carol, CoAuthors: []git.Developer{dave}},
+	}
+
+	matrix, recencyMatrix, _ := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{MobsOnly: true})
+
+	tests := []struct {
+		name      string
+		a, b      git.Developer
+		wantCount int
+	}{
+		{"pair in the mob is counted", alice, carol, 1},
+		{"pair in the mob and a pair commit only counts the mob", alice, bob, 1},
+		{"pair that only paired is not counted", carol, dave, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if count := matrix.CountByDeveloper(tt.a, tt.b); count != tt.wantCount {
+				t.Errorf("Expected count %d, got %d", tt.wantCount, count)
+			}
+		})
+	}
+
+	if lastPaired, _ := recencyMatrix.LastPairedByDeveloper(alice, bob); !lastPaired.Equal(day1.Truncate(24 * time.Hour)) {
+		t.Errorf("Expected Alice-Bob last paired on the mob day, got %v", lastPaired)
+	}
+}
+
+func TestBuildPairMatrixAttributions(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+
+	day := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		// Alice always authors when pairing with Bob
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day.Add(time.Hour), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{bob}},
+		// A mob authored by Carol: only the author's pairs are directed
+		{Date: day.AddDate(0, 0, 2), Author: carol, CoAuthors: []git.Developer{alice, bob}},
+	}
+
+	matrix, _, _ := pairing.BuildPairMatrix(team.Empty, commits, false)
+
+	tests := []struct {
+		name     string
+		author   git.Developer
+		coAuthor git.Developer
+		want     int
+	}{
+		{"author to co-author counts every commit", alice, bob, 3},
+		{"co-author to author is never counted", bob, alice, 0},
+		{"mob author to each co-author", carol, alice, 1},
+		{"mob author to each co-author", carol, bob, 1},
+		{"co-authors in a mob have no direction", alice, carol, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matrix.AttributionsByDeveloper(tt.author, tt.coAuthor); got != tt.want {
+				t.Errorf("Expected %d attributions, got %d", tt.want, got)
+			}
+		})
+	}
+
+	// The pairing counts stay symmetric
+	if matrix.CountByDeveloper(alice, bob) != 3 || matrix.CountByDeveloper(bob, alice) != 3 {
+		t.Errorf("Expected a symmetric Alice-Bob count of 3, got %d and %d", matrix.CountByDeveloper(alice, bob), matrix.CountByDeveloper(bob, alice))
+	}
+	if clone := matrix.Clone(); clone.AttributionsByDeveloper(alice, bob) != 3 {
+		t.Error("Expected Clone to copy attributions")
+	}
+}
+
+func TestParseMobWeight(t *testing.T) {
+	for _, valid := range []string{"equal", "split"} {
+		if weight, err := pairing.ParseMobWeight(valid); err != nil || string(weight) != valid {
+			t.Errorf("Expected %q to parse, got %q, %v", valid, weight, err)
+		}
+	}
+	if _, err := pairing.ParseMobWeight("half"); err == nil {
+		t.Error("Expected error for invalid mob weight")
+	}
+}
+
+func TestBuildPairMatrixBareEmailAbbreviations(t *testing.T) {
+	named := git.NewDeveloper("John Doe <john@example.com>")
+	bare := git.NewDeveloper("<jane.doe@example.com>")
+	single := git.NewDeveloper("<jdoe@example.com>")
+	plain := git.NewDeveloper("<sam_o-neil@example.com>")
+
+	commits := []git.Commit{
+		{Date: time.Now(), Author: named, CoAuthors: []git.Developer{bare}},
+		{Date: time.Now(), Author: single, CoAuthors: []git.Developer{plain}},
+	}
+
+	_, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+
+	expected := map[string]string{
+		"john@example.com":       "JD",
+		"jane.doe@example.com":   "JD2",
+		"jdoe@example.com":       "JD3",
+		"sam_o-neil@example.com": "SON",
+	}
+	if len(developers) != len(expected) {
+		t.Fatalf("Expected %d developers, got %d", len(expected), len(developers))
+	}
+	for _, dev := range developers {
+		if want := expected[dev.CanonicalEmail()]; dev.AbbreviatedName != want {
+			t.Errorf("Expected %s to be abbreviated %q, got %q", dev.CanonicalEmail(), want, dev.AbbreviatedName)
+		}
+	}
+}
+
+func TestAssignLabels(t *testing.T) {
+	developers := func() []git.Developer {
+		return []git.Developer{
+			git.NewDeveloper("Jane Doe <jane@example.com>"),
+			git.NewDeveloper("John Dunne <jd@example.com>"),
+			git.NewDeveloper("Jane Smith <jsmith@example.com>"),
+			git.NewDeveloper("<sam_o-neil@example.com>"),
+			git.NewDeveloper("élodie martin <elodie@example.com>"),
+		}
+	}
+
+	tests := []struct {
+		style pairing.LabelStyle
+		want  []string
+	}{
+		{pairing.InitialsLabels, []string{"JD", "JD2", "JS", "SON", "ÉM"}},
+		{"", []string{"JD", "JD2", "JS", "SON", "ÉM"}},
+		{pairing.FirstNameLabels, []string{"Jane", "John", "Jane2", "Sam", "Élodie"}},
+		{pairing.EmailPrefixLabels, []string{"jane", "jd", "jsmith", "sam_o-neil", "elodie"}},
+		{"3", []string{"Jan", "Joh", "Jan2", "Sam", "Élo"}},
+		{"20", []string{"JaneDoe", "JohnDunne", "JaneSmith", "Samoneil", "Élodiemartin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			devs := developers()
+			pairing.AssignLabels(devs, tt.style)
+			for i, dev := range devs {
+				if dev.AbbreviatedName != tt.want[i] {
+					t.Errorf("Expected %s to be labelled %q, got %q", dev.CanonicalEmail(), tt.want[i], dev.AbbreviatedName)
+				}
+			}
+		})
+	}
+}
+
+func TestAssignLabelsNamedDevelopersFirst(t *testing.T) {
+	// The developer with a name keeps the plain label even though the bare email sorts first
+	devs := []git.Developer{
+		git.NewDeveloper("<jane.doe@example.com>"),
+		git.NewDeveloper("John Doe <john@example.com>"),
+	}
+	pairing.AssignLabels(devs, pairing.InitialsLabels)
+	if devs[0].AbbreviatedName != "JD2" || devs[1].AbbreviatedName != "JD" {
+		t.Errorf("Expected labels JD2 and JD, got %q and %q", devs[0].AbbreviatedName, devs[1].AbbreviatedName)
+	}
+}
+
+func TestParseLabelStyle(t *testing.T) {
+	for _, valid := range []string{"initials", "firstname", "emailprefix", "1", "4"} {
+		if style, err := pairing.ParseLabelStyle(valid); err != nil || string(style) != valid {
+			t.Errorf("Expected %q to be a valid label style, got %q, %v", valid, style, err)
+		}
+	}
+	for _, invalid := range []string{"", "surname", "0", "-2", "3x"} {
+		if _, err := pairing.ParseLabelStyle(invalid); err == nil {
+			t.Errorf("Expected an error for label style %q", invalid)
+		}
+	}
+}
+
+func TestBuildPairMatrixWithOptionsLabels(t *testing.T) {
+	commits := []git.Commit{{
+		Date:      time.Now(),
+		Author:    git.NewDeveloper("Alice Smith <alice@example.com>"),
+		CoAuthors: []git.Developer{git.NewDeveloper("Alan Smithers <alan@example.com>")},
+	}}
+
+	_, _, developers := pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{})
+	if developers[0].AbbreviatedName != "AS" || developers[1].AbbreviatedName != "AS2" {
+		t.Errorf("Expected clashing initials to be made unique, got %q and %q", developers[0].AbbreviatedName, developers[1].AbbreviatedName)
+	}
+
+	_, _, developers = pairing.BuildPairMatrixWithOptions(team.Empty, commits, false, pairing.BuildOptions{Labels: pairing.FirstNameLabels})
+	if developers[0].AbbreviatedName != "Alan" || developers[1].AbbreviatedName != "Alice" {
+		t.Errorf("Expected first name labels, got %q and %q", developers[0].AbbreviatedName, developers[1].AbbreviatedName)
+	}
+}
+
+func TestBuildParticipation(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+
+	day1 := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	commits := []git.Commit{
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day1.Add(time.Hour), Author: alice, CoAuthors: []git.Developer{carol}},
+		{Date: day2, Author: bob, CoAuthors: []git.Developer{carol}},
+		{Date: day2, Author: alice},
+	}
+
+	participation := pairing.BuildParticipation(team.Empty, commits, false, pairing.BuildOptions{})
+
+	tests := []struct {
+		email    string
+		date     time.Time
+		expected []string
+	}{
+		{"alice@example.com", day1, []string{"bob@example.com", "carol@example.com"}},
+		{"bob@example.com", day1, []string{"alice@example.com"}},
+		{"alice@example.com", day2, nil},
+		{"carol@example.com", day2, []string{"bob@example.com"}},
+	}
+
+	for _, tt := range tests {
+		partners := participation.Partners(tt.email, tt.date)
+		if strings.Join(partners, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("Partners(%s, %s): expected %v, got %v", tt.email, tt.date.Format("2006-01-02"), tt.expected, partners)
+		}
+	}
+}
+
+func TestParticipationFirstPaired(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+
+	day1 := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 3)
+	commits := []git.Commit{
+		{Date: day2, Author: bob, CoAuthors: []git.Developer{carol}},
+		{Date: day1, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day1, Author: carol},
+	}
+
+	participation := pairing.BuildParticipation(team.Empty, commits, false, pairing.BuildOptions{})
+
+	tests := []struct {
+		email    string
+		expected time.Time
+		paired   bool
+	}{
+		{"alice@example.com", time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), true},
+		{"bob@example.com", time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), true},
+		{"carol@example.com", time.Date(2024, 6, 13, 0, 0, 0, 0, time.UTC), true},
+		{"dave@example.com", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		first, paired := participation.FirstPaired(tt.email)
+		if paired != tt.paired || !first.Equal(tt.expected) {
+			t.Errorf("FirstPaired(%s): expected %v, %v, got %v, %v", tt.email, tt.expected, tt.paired, first, paired)
+		}
+	}
+}
+
+func TestActiveDevelopers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>,<alice@old.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	commits := []git.Commit{
+		{Author: git.NewDeveloper("Alice <alice@old.com>")},
+		{Author: git.NewDeveloper("Dave Brown <dave@example.com>"), CoAuthors: []git.Developer{carol}},
+	}
+
+	active := pairing.ActiveDevelopers([]git.Developer{alice, bob, carol}, commits)
+
+	if len(active) != 2 || active[0].CanonicalEmail() != "alice@example.com" || active[1].CanonicalEmail() != "carol@example.com" {
+		t.Errorf("Expected Alice, by her old email, and Carol, as a co-author, to be active, got %v", active)
+	}
+}
+
+func TestBuildReviewMatrix(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	commits := []git.Commit{
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}, Reviewers: []git.Developer{carol}},
+		{Date: day.Add(time.Hour), Author: alice, Reviewers: []git.Developer{carol}},
+		{Date: day.AddDate(0, 0, 1), Author: bob, CoAuthors: []git.Developer{carol}, Reviewers: []git.Developer{carol}},
+	}
+
+	reviews := pairing.BuildReviewMatrix(team.Team{}, commits, false)
+	if got := reviews.CountByDeveloper(alice, carol); got != 1 {
+		t.Errorf("Expected Carol's reviews of Alice to count once for the day, got %d", got)
+	}
+	if got := reviews.CountByDeveloper(bob, carol); got != 1 {
+		t.Errorf("Expected Carol to count as a reviewer of Bob only where she didn't co-author, got %d", got)
+	}
+	if got := reviews.CountByDeveloper(alice, bob); got != 0 {
+		t.Errorf("Expected co-authoring not to count as a review, got %d", got)
+	}
+
+	pairs, _, _ := pairing.BuildPairMatrix(team.Team{}, commits, false)
+	if got := pairs.CountByDeveloper(alice, carol); got != 0 {
+		t.Errorf("Expected a review not to count as pairing, got %d", got)
+	}
+}
+
+func TestReadImport(t *testing.T) {
+	csv := `date,emailA,emailB
+2024-01-15,Alice@Example.com,bob@example.com
+# Recorded in the sprint board
+2024-01-16, carol@example.com, alice@example.com
+`
+	commits, err := pairing.ReadImport(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 imported pairings, got %d", len(commits))
+	}
+	if got := commits[0].Author.CanonicalEmail(); got != "alice@example.com" {
+		t.Errorf("Expected the first developer as the author, lower-cased, got %q", got)
+	}
+	if len(commits[0].CoAuthors) != 1 || commits[0].CoAuthors[0].CanonicalEmail() != "bob@example.com" {
+		t.Errorf("Expected the second developer as the only co-author, got %v", commits[0].CoAuthors)
+	}
+	if want := time.Date(2024, 1, 16, 12, 0, 0, 0, time.Local); !commits[1].Date.Equal(want) {
+		t.Errorf("Expected the pairing at noon on its date, got %v", commits[1].Date)
+	}
+
+	for name, bad := range map[string]string{
+		"bad date":      "15/01/2024,alice@example.com,bob@example.com\n",
+		"bad email":     "2024-01-15,alice,bob@example.com\n",
+		"missing email": "2024-01-15,alice@example.com\n",
+		"same email":    "2024-01-15,alice@example.com,alice@example.com\n",
+	} {
+		if _, err := pairing.ReadImport(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s: expected an error for %q", name, bad)
+		}
+	}
+}
+
+func TestBuildPairMatrixWithImportedPairings(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	commits := []git.Commit{
+		{Date: time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local), Author: alice, CoAuthors: []git.Developer{bob}},
+	}
+
+	// The import repeats the pairing in git on the 15th, adds another day for Alice
+	// and Bob, and adds Carol who never commits
+	imported, err := pairing.ReadImport(strings.NewReader(`2024-01-15,bob@example.com,alice@example.com
+2024-01-17,alice@example.com,bob@example.com
+2024-01-17,carol@example.com,alice@example.com
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	matrix, recencyMatrix, devs := pairing.BuildPairMatrix(team.Team{}, append(imported, commits...), false)
+
+	if count := matrix.Count("alice@example.com", "bob@example.com"); count != 2 {
+		t.Errorf("Expected Alice and Bob to have paired on 2 days, counting the 15th once, got %d", count)
+	}
+	if count := matrix.Count("alice@example.com", "carol@example.com"); count != 1 {
+		t.Errorf("Expected Alice and Carol's imported pairing to count, got %d", count)
+	}
+	if last, ok := recencyMatrix.LastPaired("alice@example.com", "bob@example.com"); !ok || last.Day() != 17 {
+		t.Errorf("Expected Alice and Bob to have last paired on the 17th, got %v", last)
+	}
+
+	if len(devs) != 3 {
+		t.Fatalf("Expected 3 developers, got %v", devs)
+	}
+	if devs[0].DisplayName != "Alice Smith" {
+		t.Errorf("Expected Alice's name from git even though the import came first, got %q", devs[0].DisplayName)
+	}
+	if devs[2].CanonicalEmail() != "carol@example.com" {
+		t.Errorf("Expected Carol from the import, got %v", devs[2])
+	}
+}
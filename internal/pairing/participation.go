@@ -0,0 +1,127 @@
+package pairing
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+// Participation records, for each developer and day, who they paired with
+type Participation struct {
+	data map[string]map[string]map[string]struct{} // email -> date -> partner emails
+}
+
+// NewParticipation creates a new empty participation record
+func NewParticipation() *Participation {
+	return &Participation{data: make(map[string]map[string]map[string]struct{})}
+}
+
+// Record notes that two developers paired on the given day
+func (p *Participation) Record(a, b string, date time.Time) {
+	if a == b {
+		return // Skip self-pairs
+	}
+	p.add(a, b, date)
+	p.add(b, a, date)
+}
+
+func (p *Participation) add(email, partner string, date time.Time) {
+	day := date.Format("2006-01-02")
+	if _, ok := p.data[email]; !ok {
+		p.data[email] = make(map[string]map[string]struct{})
+	}
+	if _, ok := p.data[email][day]; !ok {
+		p.data[email][day] = make(map[string]struct{})
+	}
+	p.data[email][day][partner] = struct{}{}
+}
+
+// Partners returns the sorted emails of the developers someone paired with on the given day
+func (p *Participation) Partners(email string, date time.Time) []string {
+	var partners []string
+	for partner := range p.data[email][date.Format("2006-01-02")] {
+		partners = append(partners, partner)
+	}
+	sort.Strings(partners)
+	return partners
+}
+
+// PairDays returns the number of pairs that paired on each day anyone paired.
+// Days are midnight UTC on the calendar date the pairing was recorded for.
+func (p *Participation) PairDays() map[time.Time]int {
+	pairDays := make(map[time.Time]int)
+	for email, days := range p.data {
+		for day, partners := range days {
+			date, err := time.Parse("2006-01-02", day)
+			if err != nil {
+				continue
+			}
+			for partner := range partners {
+				if email < partner { // Each pair is recorded for both developers
+					pairDays[date]++
+				}
+			}
+		}
+	}
+	return pairDays
+}
+
+// FirstPaired returns the first day someone paired with anyone, as midnight UTC on
+// its calendar date, and whether they have paired at all
+func (p *Participation) FirstPaired(email string) (time.Time, bool) {
+	var first time.Time
+	for day := range p.data[email] {
+		date, err := time.Parse("2006-01-02", day)
+		if err == nil && (first.IsZero() || date.Before(first)) {
+			first = date
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// WeeklyPairDays returns the number of days two developers paired in each week
+// from start to end. The first week begins on start's calendar date, and the last
+// holds end, so it may be short.
+func (p *Participation) WeeklyPairDays(a, b string, start, end time.Time) []int {
+	first := calendarDate(start)
+	weeks := make([]int, int(calendarDate(end).Sub(first).Hours()/24)/7+1)
+	for day, partners := range p.data[a] {
+		if _, ok := partners[b]; !ok {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil || date.Before(first) {
+			continue
+		}
+		if week := int(date.Sub(first).Hours()/24) / 7; week < len(weeks) {
+			weeks[week]++
+		}
+	}
+	return weeks
+}
+
+// calendarDate returns midnight UTC on the calendar date of t, as days are recorded
+func calendarDate(t time.Time) time.Time {
+	date, _ := time.Parse("2006-01-02", t.Format("2006-01-02"))
+	return date
+}
+
+// BuildParticipation records who each developer paired with on each day, using
+// the same commit selection and identity rules as BuildPairMatrixWithOptions
+func BuildParticipation(team team.Team, commits []git.Commit, useTeam bool, options BuildOptions) *Participation {
+	participation := NewParticipation()
+	for _, c := range options.Filter(commits) {
+		participants := commitParticipants(team, c, useTeam)
+		if !options.countsPairing(participants) {
+			continue
+		}
+		for i := 0; i < len(participants); i++ {
+			for j := i + 1; j < len(participants); j++ {
+				participation.Record(participants[i], participants[j], c.Date)
+			}
+		}
+	}
+	return participation
+}
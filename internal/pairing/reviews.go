@@ -0,0 +1,44 @@
+package pairing
+
+import (
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+// BuildReviewMatrix counts the days each reviewer reviewed each commit participant's
+// work, from the commits' Reviewed-by trailers. Reviews are a weaker kind of
+// collaboration than pairing, so they're kept in their own matrix. A reviewer who
+// also worked on the commit is already counted as pairing there, and isn't counted
+// again as a reviewer.
+func BuildReviewMatrix(team team.Team, commits []git.Commit, useTeam bool) *Matrix {
+	_, emailToPrimaryEmail := team.GetEmailMappings()
+
+	reviews := NewMatrix()
+	counted := make(map[string]map[Pair]bool)
+	for _, c := range commits {
+		participants := commitParticipants(team, c, useTeam)
+		isParticipant := make(map[string]bool, len(participants))
+		for _, email := range participants {
+			isParticipant[email] = true
+		}
+
+		date := c.Date.Format("2006-01-02")
+		if _, ok := counted[date]; !ok {
+			counted[date] = make(map[Pair]bool)
+		}
+		for _, reviewer := range c.Reviewers {
+			email, ok := participantEmail(emailToPrimaryEmail, reviewer, useTeam)
+			if !ok || isParticipant[email] {
+				continue
+			}
+			for _, participant := range participants {
+				p := Pair{A: min(email, participant), B: max(email, participant)}
+				if !counted[date][p] {
+					counted[date][p] = true
+					reviews.Add(p.A, p.B)
+				}
+			}
+		}
+	}
+	return reviews
+}
@@ -0,0 +1,53 @@
+package recommend
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+)
+
+// BigTeamMode chooses what happens when there are more developers than the
+// GreedyCutoff
+type BigTeamMode string
+
+const (
+	// BigTeamSkip makes no recommendations at all
+	BigTeamSkip BigTeamMode = "skip"
+	// BigTeamSample recommends pairs for a random sample of GreedyCutoff developers,
+	// chosen by the Seed so the same seed always picks the same sample
+	BigTeamSample BigTeamMode = "sample"
+	// BigTeamGreedy recommends pairs for everyone with the greedy matcher, which
+	// scales to any size of team
+	BigTeamGreedy BigTeamMode = "greedy"
+)
+
+// ParseBigTeamMode converts a mode name to a BigTeamMode. The empty string skips.
+func ParseBigTeamMode(name string) (BigTeamMode, error) {
+	switch mode := BigTeamMode(name); mode {
+	case BigTeamSkip, BigTeamSample, BigTeamGreedy:
+		return mode, nil
+	case "":
+		return BigTeamSkip, nil
+	default:
+		return "", fmt.Errorf("unknown big team mode %q: use 'skip', 'sample' or 'greedy'", name)
+	}
+}
+
+// sample returns n of the developers chosen at random by the seed, keeping their
+// order so that ties between pairs are still broken the same way
+func sample(developers []git.Developer, n int, seed int64) []git.Developer {
+	if n >= len(developers) {
+		return developers
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	chosen := rng.Perm(len(developers))[:n]
+	slices.Sort(chosen)
+
+	sampled := make([]git.Developer, n)
+	for i, index := range chosen {
+		sampled[i] = developers[index]
+	}
+	return sampled
+}
@@ -0,0 +1,140 @@
+package recommend
+
+import (
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+// matching is a set of pairings where each developer appears at most once
+type matching struct {
+	pairs     []candidate
+	unpaired  []git.Developer
+	newPairs  int
+	staleness time.Duration
+	repeats   int // Pairs already planned
+}
+
+// betterThan reports whether m leaves fewer developers unpaired than other, then
+// whether it repeats fewer planned pairs, then whether it introduces more
+// never-paired pairs, breaking ties by the greater total time since the chosen
+// pairs last paired
+func (m matching) betterThan(other matching) bool {
+	if len(m.unpaired) != len(other.unpaired) {
+		return len(m.unpaired) < len(other.unpaired)
+	}
+	if m.repeats != other.repeats {
+		return m.repeats < other.repeats
+	}
+	if m.newPairs != other.newPairs {
+		return m.newPairs > other.newPairs
+	}
+	return m.staleness > other.staleness
+}
+
+// coverageSearch exhaustively searches for the best matching of a set of developers
+type coverageSearch struct {
+	developers []git.Developer
+	candidates map[[2]int]candidate
+	minGap     int
+	planned    pairSet
+	now        time.Time
+	best       matching
+	found      bool
+}
+
+// generateCoverage recommends the matching, among all possible matchings, that
+// introduces the most pairs who have never worked together. Ties are broken by
+// total staleness. Every matching is examined, so callers must limit it to small
+// teams (see Options.OptimalCutoff). Pairs within the minimum gap (in days) are
+// never chosen, so more than one developer may be left unpaired. Planned pairs are
+// only chosen when every matching without them leaves more developers unpaired.
+func generateCoverage(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, minGap int, planned pairSet, now time.Time) []Recommendation {
+	if len(developers) < 2 {
+		return nil
+	}
+
+	search := &coverageSearch{
+		developers: developers,
+		candidates: make(map[[2]int]candidate),
+		minGap:     minGap,
+		planned:    planned,
+		now:        now,
+	}
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			lastTime, hasData := recencyMatrix.LastPairedByDeveloper(developers[i], developers[j])
+			search.candidates[[2]int{i, j}] = candidate{
+				devA:     developers[i],
+				devB:     developers[j],
+				count:    matrix.CountByDeveloper(developers[i], developers[j]),
+				weight:   matrix.WeightByDeveloper(developers[i], developers[j]),
+				lastTime: lastTime,
+				hasData:  hasData,
+			}
+		}
+	}
+
+	remaining := make([]int, len(developers))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	// Without a gap everyone has a partner but the odd one out. With one, anyone
+	// might be left without an allowed partner.
+	spare := len(developers) % 2
+	if minGap > 0 {
+		spare = len(developers)
+	}
+	search.run(remaining, spare, matching{})
+
+	recommendations := make([]Recommendation, 0, len(search.best.pairs)+len(search.best.unpaired))
+	for _, c := range search.best.pairs {
+		recommendations = append(recommendations, newRecommendation(c, now))
+	}
+	for _, dev := range search.best.unpaired {
+		recommendations = append(recommendations, Recommendation{A: dev})
+	}
+	return recommendations
+}
+
+// run enumerates every matching of the remaining developers (by index), keeping
+// the best. Up to spare developers may be left without a partner.
+func (s *coverageSearch) run(remaining []int, spare int, current matching) {
+	if len(remaining) == 0 {
+		if !s.found || current.betterThan(s.best) {
+			s.best = current
+			s.found = true
+		}
+		return
+	}
+
+	first, rest := remaining[0], remaining[1:]
+
+	if spare > 0 {
+		skipped := current
+		skipped.unpaired = append(append([]git.Developer(nil), current.unpaired...), s.developers[first])
+		s.run(rest, spare-1, skipped)
+	}
+
+	for i, partner := range rest {
+		c := s.candidates[[2]int{first, partner}]
+		if withinGap(c, s.minGap, s.now) {
+			continue
+		}
+
+		next := current
+		next.pairs = append(append([]candidate(nil), current.pairs...), c)
+		if s.planned.has(c.devA, c.devB) {
+			next.repeats++
+		}
+		if c.hasData {
+			next.staleness += s.now.Sub(c.lastTime)
+		} else {
+			next.newPairs++
+		}
+
+		others := append(append([]int(nil), rest[:i]...), rest[i+1:]...)
+		s.run(others, spare, next)
+	}
+}
@@ -0,0 +1,164 @@
+package recommend_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestGenerateRecommendations_CoverageBeatsLeastRecentOnNewPairs(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	// Never paired: Alice/Bob, Alice/Carol, Bob/Dave.
+	// Greedy least-recent takes Alice/Bob first, leaving Carol/Dave (already paired).
+	// The best matching is Alice/Carol + Bob/Dave: two new pairs.
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	for _, p := range [][2]git.Developer{{alice, dave}, {bob, carol}, {carol, dave}} {
+		matrix.AddByDeveloper(p[0], p[1])
+		recencyMatrix.RecordByDeveloper(p[0], p[1], time.Now().AddDate(0, 0, -10))
+	}
+
+	leastRecent := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, recommend.LeastRecent)
+	coverage := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, recommend.Coverage)
+
+	if got := countNewPairs(leastRecent); got != 1 {
+		t.Errorf("Expected least-recent to find 1 new pair, got %d", got)
+	}
+	if got := countNewPairs(coverage); got != 2 {
+		t.Errorf("Expected coverage to find 2 new pairs, got %d", got)
+	}
+}
+
+func TestGenerateRecommendations_CoverageBreaksTiesByStaleness(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	// Everyone has paired; Alice/Dave and Bob/Carol paired longest ago
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pairings := []struct {
+		a, b    git.Developer
+		daysAgo int
+	}{
+		{alice, bob, 2},
+		{carol, dave, 2},
+		{alice, carol, 5},
+		{bob, dave, 5},
+		{alice, dave, 40},
+		{bob, carol, 40},
+	}
+	for _, p := range pairings {
+		matrix.AddByDeveloper(p.a, p.b)
+		recencyMatrix.RecordByDeveloper(p.a, p.b, time.Now().AddDate(0, 0, -p.daysAgo))
+	}
+
+	recommendations := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, recommend.Coverage)
+
+	if len(recommendations) != 2 {
+		t.Fatalf("Expected 2 recommendations, got %d", len(recommendations))
+	}
+	for _, rec := range recommendations {
+		if rec.DaysSince < 39 {
+			t.Errorf("Expected only the stalest pairs, got %s <-> %s (%d days)", rec.A.DisplayName, rec.B.DisplayName, rec.DaysSince)
+		}
+	}
+}
+
+func TestGenerateRecommendations_CoverageOddDevelopers(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+		git.NewDeveloper("Carol Davis <carol@example.com>"),
+	}
+
+	recommendations := recommend.GenerateRecommendations(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), recommend.Coverage)
+
+	if len(recommendations) != 2 {
+		t.Fatalf("Expected 1 pair and 1 unpaired developer, got %d recommendations", len(recommendations))
+	}
+	if len(recommendations[1].B.EmailAddresses) != 0 {
+		t.Errorf("Expected the last recommendation to be the unpaired developer")
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_CutoffBoundaries(t *testing.T) {
+	options := recommend.Options{GreedyCutoff: 6, OptimalCutoff: 4}
+
+	tests := []struct {
+		name              string
+		developers        int
+		strategy          recommend.Strategy
+		expectedAlgorithm recommend.Algorithm
+		expectedCount     int
+	}{
+		{"coverage at the optimal cutoff", 4, recommend.Coverage, recommend.AlgorithmOptimal, 2},
+		{"coverage above the optimal cutoff falls back to greedy", 5, recommend.Coverage, recommend.AlgorithmGreedy, 3},
+		{"coverage at the greedy cutoff", 6, recommend.Coverage, recommend.AlgorithmGreedy, 3},
+		{"coverage above the greedy cutoff", 7, recommend.Coverage, recommend.AlgorithmNone, 0},
+		{"least-paired is always greedy", 2, recommend.LeastPaired, recommend.AlgorithmGreedy, 1},
+		{"least-paired above the greedy cutoff", 7, recommend.LeastPaired, recommend.AlgorithmNone, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			developers := makeDevelopers(tt.developers)
+
+			recommendations, algorithm := recommend.GenerateRecommendationsWithOptions(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), tt.strategy, options)
+
+			if algorithm != tt.expectedAlgorithm {
+				t.Errorf("Expected algorithm %s, got %s", tt.expectedAlgorithm, algorithm)
+			}
+			if len(recommendations) != tt.expectedCount {
+				t.Errorf("Expected %d recommendations, got %d", tt.expectedCount, len(recommendations))
+			}
+		})
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_CoverageFallbackPrefersNewPairs(t *testing.T) {
+	developers := makeDevelopers(4)
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	matrix.AddByDeveloper(developers[0], developers[1])
+	recencyMatrix.RecordByDeveloper(developers[0], developers[1], lastWeek)
+
+	recommendations, algorithm := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.Coverage, recommend.Options{GreedyCutoff: 20, OptimalCutoff: 2})
+
+	if algorithm != recommend.AlgorithmGreedy {
+		t.Fatalf("Expected greedy fallback, got %s", algorithm)
+	}
+	if countNewPairs(recommendations) != 2 {
+		t.Errorf("Expected the fallback to pick 2 new pairs, got %d", countNewPairs(recommendations))
+	}
+}
+
+func makeDevelopers(n int) []git.Developer {
+	developers := make([]git.Developer, n)
+	for i := range developers {
+		developers[i] = git.NewDeveloper(fmt.Sprintf("Dev %c <dev%d@example.com>", 'A'+i, i))
+	}
+	return developers
+}
+
+func countNewPairs(recommendations []recommend.Recommendation) int {
+	count := 0
+	for _, rec := range recommendations {
+		if len(rec.B.EmailAddresses) > 0 && !rec.HasPaired {
+			count++
+		}
+	}
+	return count
+}
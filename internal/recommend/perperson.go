@@ -0,0 +1,72 @@
+package recommend
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+// compareNeverPaired prefers pairs who have never worked together over pairs who have
+func compareNeverPaired(a, b candidate) int {
+	switch {
+	case a.hasData == b.hasData:
+		return 0
+	case !a.hasData:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// BestPartners suggests the single best partner for each developer by the
+// strategy, in the developers' order. Unlike GenerateRecommendations each
+// developer is considered on their own, so one developer can be the best partner
+// for several others. Partners they have never paired with come first, then the
+// strategy decides.
+//
+// Each recommendation has the developer as A and their partner as B. A developer
+// with no partner allowed, because they are alone or by the options' MinGap, has
+// an empty B.
+func BestPartners(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, options Options) []Recommendation {
+	return bestPartnersAt(developers, matrix, recencyMatrix, strategy, options, time.Now())
+}
+
+// bestPartnersAt suggests each developer's best partner as if run at the given time
+func bestPartnersAt(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, options Options, now time.Time) []Recommendation {
+	developers = WithoutObservers(developers, options.Observers)
+	if !options.WideRecency {
+		recencyMatrix = countedRecency(developers, matrix, recencyMatrix)
+	}
+	compare := chainComparators([]compareFunc{compareNeverPaired, strategyComparator(strategy, options, now)})
+
+	all := buildCandidates(developers, matrix, recencyMatrix)
+	var suggestions []Recommendation
+	for _, dev := range developers {
+		var candidates []candidate
+		for _, c := range all {
+			if withinGap(c, options.MinGap, now) {
+				continue
+			}
+			switch dev.CanonicalEmail() {
+			case c.devA.CanonicalEmail():
+				candidates = append(candidates, c)
+			case c.devB.CanonicalEmail():
+				c.devA, c.devB = c.devB, c.devA
+				candidates = append(candidates, c)
+			}
+		}
+
+		// Stable sort keeps developer order for complete ties, making results deterministic
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return compare(candidates[i], candidates[j]) < 0
+		})
+		if len(candidates) == 0 {
+			suggestions = append(suggestions, Recommendation{A: dev})
+			continue
+		}
+		suggestions = append(suggestions, newRecommendation(candidates[0], now))
+	}
+	return markRecent(suggestions, options.RecentThreshold)
+}
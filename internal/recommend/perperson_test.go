@@ -0,0 +1,87 @@
+package recommend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestBestPartners(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	frank := git.NewDeveloper("Frank Green <frank@example.com>")
+	developers := []git.Developer{alice, bob, carol, frank}
+
+	// Frank has never paired with anyone, so he is everyone's best new partner even
+	// though a global matching could only give him to one of them
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	now := time.Now()
+	for _, pair := range [][2]git.Developer{{alice, bob}, {alice, carol}, {bob, carol}, {bob, carol}} {
+		matrix.AddByDeveloper(pair[0], pair[1])
+		recencyMatrix.RecordByDeveloper(pair[0], pair[1], now.AddDate(0, 0, -3))
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.LeastRecent, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			suggestions := recommend.BestPartners(developers, matrix, recencyMatrix, strategy, recommend.DefaultOptions)
+			if len(suggestions) != len(developers) {
+				t.Fatalf("Expected a suggestion for each of the %d developers, got %+v", len(developers), suggestions)
+			}
+			for i, suggestion := range suggestions[:3] {
+				if suggestion.A.CanonicalEmail() != developers[i].CanonicalEmail() {
+					t.Errorf("Expected suggestion %d to be for %s, got %s", i, developers[i].DisplayName, suggestion.A.DisplayName)
+				}
+				if suggestion.B.CanonicalEmail() != frank.CanonicalEmail() || suggestion.HasPaired {
+					t.Errorf("Expected %s's best partner to be Frank, who they never paired with, got %+v", suggestion.A.DisplayName, suggestion.B)
+				}
+			}
+			if suggestions[3].B.CanonicalEmail() == "" {
+				t.Errorf("Expected Frank to get a partner too, got %+v", suggestions[3])
+			}
+		})
+	}
+}
+
+func TestBestPartnersByStrategy(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	// Alice paired with Bob once, long ago, and with Carol twice, recently
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	now := time.Now()
+	matrix.AddByDeveloper(alice, bob)
+	recencyMatrix.RecordByDeveloper(alice, bob, now.AddDate(0, 0, -30))
+	matrix.AddByDeveloper(alice, carol)
+	matrix.AddByDeveloper(alice, carol)
+	recencyMatrix.RecordByDeveloper(alice, carol, now.AddDate(0, 0, -1))
+	matrix.AddByDeveloper(bob, carol)
+	recencyMatrix.RecordByDeveloper(bob, carol, now.AddDate(0, 0, -60))
+
+	suggestions := recommend.BestPartners(developers, matrix, recencyMatrix, recommend.LeastRecent, recommend.DefaultOptions)
+	if got := suggestions[2].B.DisplayName; got != "Bob Jones" {
+		t.Errorf("Expected Carol's least recent partner to be Bob, got %q", got)
+	}
+
+	options := recommend.DefaultOptions
+	options.MinGap = 7
+	suggestions = recommend.BestPartners(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	if got := suggestions[2].B.DisplayName; got != "Bob Jones" {
+		t.Errorf("Expected Carol's only partner outside the gap to be Bob, got %q", got)
+	}
+
+	options.MinGap = 90
+	suggestions = recommend.BestPartners(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	for _, suggestion := range suggestions {
+		if len(suggestion.B.EmailAddresses) != 0 {
+			t.Errorf("Expected no partner for anyone within a 90 day gap, got %+v", suggestion)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+// PlanDay holds the recommended pairings for a single day of a rotation plan
+type PlanDay struct {
+	Date            time.Time
+	Recommendations []Recommendation
+}
+
+// WorkingDays is the set of weekdays on which pairings are planned
+type WorkingDays map[time.Weekday]bool
+
+// DefaultWorkingDays is Monday to Friday
+var DefaultWorkingDays = WorkingDays{
+	time.Monday:    true,
+	time.Tuesday:   true,
+	time.Wednesday: true,
+	time.Thursday:  true,
+	time.Friday:    true,
+}
+
+// ParseWorkingDays parses a comma-separated list of weekday abbreviations (e.g. "mon,tue,wed")
+func ParseWorkingDays(s string) (WorkingDays, error) {
+	dayNames := map[string]time.Weekday{
+		"sun": time.Sunday,
+		"mon": time.Monday,
+		"tue": time.Tuesday,
+		"wed": time.Wednesday,
+		"thu": time.Thursday,
+		"fri": time.Friday,
+		"sat": time.Saturday,
+	}
+
+	days := make(WorkingDays)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		day, ok := dayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid working day: %q", name)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// GeneratePlan proposes pairings for each of the next working days after start.
+// Each day's recommendations are simulated as having happened (added to copies of
+// the count and recency matrices) before the following day is planned, and pairs
+// already in the plan are only recommended again for a developer with no other
+// partner, so the plan rotates through new pairs rather than repeating any.
+func GeneratePlan(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, start time.Time, days int, workingDays WorkingDays) []PlanDay {
+	return GeneratePlanWithOptions(developers, matrix, recencyMatrix, strategy, start, days, workingDays, DefaultOptions)
+}
+
+// GeneratePlanWithOptions proposes pairings like GeneratePlan, recommending each
+// day with the given options rather than DefaultOptions
+func GeneratePlanWithOptions(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, start time.Time, days int, workingDays WorkingDays, options Options) []PlanDay {
+	if len(workingDays) == 0 {
+		return nil
+	}
+
+	simulatedMatrix := matrix.Clone()
+	simulatedRecency := recencyMatrix.Clone()
+	options.planned = make(pairSet)
+
+	var plan []PlanDay
+	date := truncateToDay(start)
+	for len(plan) < days {
+		date = date.AddDate(0, 0, 1)
+		if !workingDays[date.Weekday()] {
+			continue
+		}
+
+		recommendations, _ := generateRecommendationsAt(developers, simulatedMatrix, simulatedRecency, strategy, options, date)
+		simulatePairings(recommendations, simulatedMatrix, simulatedRecency, date)
+		for _, rec := range recommendations {
+			if len(rec.B.EmailAddresses) > 0 {
+				options.planned.add(rec.A, rec.B)
+			}
+		}
+		plan = append(plan, PlanDay{Date: date, Recommendations: recommendations})
+	}
+	return plan
+}
+
+// simulatePairings records the recommended pairs as having paired on the given date
+func simulatePairings(recommendations []Recommendation, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, date time.Time) {
+	for _, rec := range recommendations {
+		if len(rec.B.EmailAddresses) == 0 {
+			continue // Unpaired developer
+		}
+		matrix.AddByDeveloper(rec.A, rec.B)
+		recencyMatrix.RecordByDeveloper(rec.A, rec.B, date)
+	}
+}
+
+// pairSet is a set of pairs of developers, in either order
+type pairSet map[[2]string]bool
+
+// key identifies a pair by their canonical emails, in order
+func (s pairSet) key(a, b git.Developer) [2]string {
+	emailA, emailB := a.CanonicalEmail(), b.CanonicalEmail()
+	if emailA > emailB {
+		emailA, emailB = emailB, emailA
+	}
+	return [2]string{emailA, emailB}
+}
+
+// add puts the pair in the set
+func (s pairSet) add(a, b git.Developer) {
+	s[s.key(a, b)] = true
+}
+
+// has reports whether the pair is in the set; a nil set has no pairs
+func (s pairSet) has(a, b git.Developer) bool {
+	return s[s.key(a, b)]
+}
+
+// truncateToDay returns midnight of the given time's day in its own location
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
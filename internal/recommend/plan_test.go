@@ -0,0 +1,195 @@
+package recommend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/recommend"
+)
+
+func TestGeneratePlan_NoRepeatsWithinPlan(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+		git.NewDeveloper("Carol Davis <carol@example.com>"),
+		git.NewDeveloper("Dave Brown <dave@example.com>"),
+	}
+	// Friday, so the plan has to skip the weekend
+	start := time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC)
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.LeastRecent} {
+		t.Run(string(strategy), func(t *testing.T) {
+			plan := recommend.GeneratePlan(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), strategy, start, 3, recommend.DefaultWorkingDays)
+
+			if len(plan) != 3 {
+				t.Fatalf("Expected 3 planned days, got %d", len(plan))
+			}
+
+			seen := make(map[string]bool)
+			for _, day := range plan {
+				for _, rec := range day.Recommendations {
+					key := pairKey(rec.A, rec.B)
+					if seen[key] {
+						t.Errorf("Pair %s repeated on %s", key, day.Date.Format("2006-01-02"))
+					}
+					seen[key] = true
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePlan_NoRepeatsWithLopsidedHistory(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+	start := time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC)
+
+	// Alice–Bob and Carol–Dave are so far behind everyone else that simulating a
+	// few days of pairing doesn't catch them up
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	for _, pair := range [][2]git.Developer{{alice, carol}, {alice, dave}, {bob, carol}, {bob, dave}} {
+		for i := 0; i < 10; i++ {
+			matrix.AddByDeveloper(pair[0], pair[1])
+		}
+		recencyMatrix.RecordByDeveloper(pair[0], pair[1], start.AddDate(0, 0, -1))
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.LeastRecent, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			plan := recommend.GeneratePlan(developers, matrix, recencyMatrix, strategy, start, 3, recommend.DefaultWorkingDays)
+			if len(plan) != 3 {
+				t.Fatalf("Expected 3 planned days, got %d", len(plan))
+			}
+
+			seen := make(map[string]bool)
+			for _, day := range plan {
+				if len(day.Recommendations) != 2 {
+					t.Errorf("Expected everyone paired on %s, got %+v", day.Date.Format("2006-01-02"), day.Recommendations)
+				}
+				for _, rec := range day.Recommendations {
+					key := pairKey(rec.A, rec.B)
+					if seen[key] {
+						t.Errorf("Pair %s repeated on %s", key, day.Date.Format("2006-01-02"))
+					}
+					seen[key] = true
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePlan_RepeatsOnlyWithoutAnotherPartner(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+	start := time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC)
+
+	plan := recommend.GeneratePlan(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), recommend.LeastPaired, start, 2, recommend.DefaultWorkingDays)
+	for _, day := range plan {
+		if len(day.Recommendations) != 1 || len(day.Recommendations[0].B.EmailAddresses) == 0 {
+			t.Errorf("Expected Alice and Bob to pair on %s, the only pair there is, got %+v", day.Date.Format("2006-01-02"), day.Recommendations)
+		}
+	}
+}
+
+func TestGeneratePlan_SkipsNonWorkingDays(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+	start := time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC) // Friday
+
+	plan := recommend.GeneratePlan(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), recommend.LeastPaired, start, 2, recommend.DefaultWorkingDays)
+
+	expected := []time.Time{
+		time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), // Monday
+		time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), // Tuesday
+	}
+	if len(plan) != len(expected) {
+		t.Fatalf("Expected %d planned days, got %d", len(expected), len(plan))
+	}
+	for i, day := range plan {
+		if !day.Date.Equal(expected[i]) {
+			t.Errorf("Day %d: expected %v, got %v", i, expected[i], day.Date)
+		}
+	}
+}
+
+func TestGeneratePlan_DoesNotModifyMatrices(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+
+	recommend.GeneratePlan(developers, matrix, recencyMatrix, recommend.LeastPaired, time.Now(), 3, recommend.DefaultWorkingDays)
+
+	if matrix.Len() != 0 {
+		t.Errorf("Expected original matrix to be untouched, got %d pairs", matrix.Len())
+	}
+	if _, exists := recencyMatrix.LastPaired("alice@example.com", "bob@example.com"); exists {
+		t.Error("Expected original recency matrix to be untouched")
+	}
+}
+
+func TestGeneratePlanWithOptions_UsesTheCutoffs(t *testing.T) {
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+		git.NewDeveloper("Carol Davis <carol@example.com>"),
+		git.NewDeveloper("Dave Brown <dave@example.com>"),
+	}
+	start := time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC)
+
+	options := recommend.Options{GreedyCutoff: 3, OptimalCutoff: 3}
+	plan := recommend.GeneratePlanWithOptions(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), recommend.LeastPaired, start, 2, recommend.DefaultWorkingDays, options)
+	if len(plan) != 2 {
+		t.Fatalf("Expected 2 planned days, got %d", len(plan))
+	}
+	for _, day := range plan {
+		if len(day.Recommendations) != 0 {
+			t.Errorf("Expected no recommendations above the greedy cutoff on %s, got %d", day.Date.Format("2006-01-02"), len(day.Recommendations))
+		}
+	}
+
+	options.GreedyCutoff = 4
+	plan = recommend.GeneratePlanWithOptions(developers, pairing.NewMatrix(), pairing.NewRecencyMatrix(), recommend.LeastPaired, start, 2, recommend.DefaultWorkingDays, options)
+	if len(plan) != 2 || len(plan[0].Recommendations) != 2 {
+		t.Errorf("Expected 2 pairs a day at the greedy cutoff, got %+v", plan)
+	}
+}
+
+func TestParseWorkingDays(t *testing.T) {
+	days, err := recommend.ParseWorkingDays("mon, Tue,sun")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Sunday} {
+		if !days[day] {
+			t.Errorf("Expected %s to be a working day", day)
+		}
+	}
+	if days[time.Friday] {
+		t.Error("Expected Friday not to be a working day")
+	}
+
+	if _, err := recommend.ParseWorkingDays("mon,funday"); err == nil {
+		t.Error("Expected error for invalid working day")
+	}
+}
+
+func pairKey(a, b git.Developer) string {
+	emailA, emailB := a.CanonicalEmail(), b.CanonicalEmail()
+	if emailA > emailB {
+		emailA, emailB = emailB, emailA
+	}
+	return emailA + "|" + emailB
+}
@@ -6,7 +6,11 @@
 package recommend
 
 import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
@@ -20,6 +24,8 @@ type Recommendation struct {
 	LastPaired time.Time
 	DaysSince  int
 	HasPaired  bool
+	// Recent is set when the pair paired within Options.RecentThreshold days
+	Recent bool
 }
 
 // Strategy represents a recommendation strategy
@@ -28,70 +34,323 @@ type Strategy string
 const (
 	LeastPaired Strategy = "least-paired"
 	LeastRecent Strategy = "least-recent"
+	Coverage    Strategy = "coverage"
 )
 
-// GenerateRecommendations generates pairing recommendations using the specified strategy
-func GenerateRecommendations(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy) []Recommendation {
+// Algorithm identifies how a set of recommendations was chosen
+type Algorithm string
+
+const (
+	// AlgorithmGreedy ranks every pair and picks the best ones in turn
+	AlgorithmGreedy Algorithm = "greedy"
+	// AlgorithmOptimal examines every possible matching
+	AlgorithmOptimal Algorithm = "optimal"
+	// AlgorithmNone means the team was too large to make recommendations
+	AlgorithmNone Algorithm = "none"
+)
+
+// Options controls how many developers each algorithm will handle, and how
+// recently paired pairs are treated
+type Options struct {
+	// GreedyCutoff is the largest team that gets recommendations at all, unless
+	// the BigTeamMode says otherwise
+	GreedyCutoff int
+	// OptimalCutoff is the largest team the exhaustive matcher will consider; the
+	// number of possible matchings grows too quickly beyond it. Larger teams fall
+	// back to greedy matching.
+	OptimalCutoff int
+	// RecentThreshold marks recommended pairs who last paired this many days ago or
+	// less as Recent. Zero turns marking off.
+	RecentThreshold int
+	// DemoteRecent ranks pairs within the RecentThreshold after all other pairs,
+	// whatever the strategy. It applies to greedy matching; the optimal coverage
+	// matcher already prefers the stalest pairs.
+	DemoteRecent bool
+	// MinGap leaves out pairs who last paired fewer than this many days ago, as if
+	// they were already taken. Developers left with no allowed partner are
+	// recommended unpaired. Zero turns it off.
+	MinGap int
+	// Seed shuffles the developers before matching, so that pairs who tie are
+	// chosen at random but the same seed always gives the same recommendations.
+	// Zero keeps the developers' order, so ties are broken alphabetically.
+	Seed int64
+	// SitOut chooses who is left unpaired when there's an odd number of
+	// developers, before the rest are matched. It doesn't apply with a MinGap,
+	// where leaving out the wrong developer could strand others too.
+	SitOut SitOutRule
+	// Observers are the emails of developers who are never recommended, though
+	// their pairing history still counts for everyone else
+	Observers []string
+	// WideRecency keeps the last pairing of pairs who haven't paired according to
+	// the matrix, for when recency is read from a wider window than the counts.
+	// Otherwise those pairs are treated as never having paired, so a pair's count
+	// and recency always agree.
+	WideRecency bool
+	// BigTeamMode chooses what happens to teams larger than the GreedyCutoff. The
+	// zero value skips them.
+	BigTeamMode BigTeamMode
+
+	// planned are the pairs already recommended earlier in a plan. They're only
+	// chosen for developers left with no other partner.
+	planned pairSet
+}
+
+// DefaultOptions are the cutoffs used by GenerateRecommendations
+var DefaultOptions = Options{GreedyCutoff: 20, OptimalCutoff: 12}
+
+// Components returns the individual strategies in a combined strategy such as
+// "least-paired,least-recent". The first is the primary strategy and the rest
+// break ties in order.
+func (s Strategy) Components() []Strategy {
+	var components []Strategy
+	for _, part := range strings.Split(string(s), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			components = append(components, Strategy(part))
+		}
+	}
+	return components
+}
+
+// Primary returns the primary strategy of a (possibly combined) strategy
+func (s Strategy) Primary() Strategy {
+	components := s.Components()
+	if len(components) == 0 {
+		return LeastPaired
+	}
+	return components[0]
+}
+
+// candidate is a possible pairing along with the history used to rank it
+type candidate struct {
+	devA, devB git.Developer
+	count      int
+	weight     float64
+	lastTime   time.Time
+	hasData    bool
+}
+
+// compareFunc orders two candidates, returning a negative number when a should be
+// recommended before b, a positive number when b should come first, and zero on a tie
+type compareFunc func(a, b candidate) int
+
+// compareLeastPaired prefers pairs that have worked together the fewest times,
+// by weight so that split mob commits count for less
+func compareLeastPaired(a, b candidate) int {
+	return cmp.Compare(a.weight, b.weight)
+}
+
+// compareLeastRecent prefers pairs that have never worked together, then the
+// pairs that worked together longest ago
+func compareLeastRecent(a, b candidate) int {
+	switch {
+	case !a.hasData && !b.hasData:
+		return 0
+	case !a.hasData:
+		return -1
+	case !b.hasData:
+		return 1
+	}
+	return a.lastTime.Compare(b.lastTime)
+}
+
+// demoteRecent puts pairs who paired within the threshold (in days) after all others
+func demoteRecent(threshold int, now time.Time) compareFunc {
+	isRecent := func(c candidate) bool {
+		return c.hasData && daysSince(c, now) <= threshold
+	}
+	return func(a, b candidate) int {
+		switch recentA, recentB := isRecent(a), isRecent(b); {
+		case recentA == recentB:
+			return 0
+		case recentA:
+			return 1
+		default:
+			return -1
+		}
+	}
+}
+
+// comparatorFor returns the comparison function for a single strategy. Coverage
+// has no pairwise ranking of its own, so it approximates it with least-recent,
+// which also puts never-paired pairs first.
+func comparatorFor(strategy Strategy) compareFunc {
 	switch strategy {
-	case LeastRecent:
-		return generateLeastRecent(developers, matrix, recencyMatrix)
+	case LeastRecent, Coverage:
+		return compareLeastRecent
 	default: // LeastPaired
-		return generateLeastPaired(developers, matrix)
+		return compareLeastPaired
 	}
 }
 
-// generateLeastPaired generates pairing recommendations using greedy approach
-// (minimize total pair count, each dev appears once)
-func generateLeastPaired(developers []git.Developer, matrix *pairing.Matrix) []Recommendation {
-	if len(developers) < 2 {
-		return nil
+// chainComparators combines comparison functions so that each subsequent function
+// only breaks ties left by the ones before it
+func chainComparators(comparators []compareFunc) compareFunc {
+	return func(a, b candidate) int {
+		for _, compare := range comparators {
+			if result := compare(a, b); result != 0 {
+				return result
+			}
+		}
+		return 0
+	}
+}
+
+// GenerateRecommendations generates pairing recommendations using the specified strategy
+func GenerateRecommendations(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy) []Recommendation {
+	recommendations, _ := GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, DefaultOptions)
+	return recommendations
+}
+
+// GenerateRecommendationsWithOptions generates pairing recommendations using the
+// specified strategy and cutoffs, and reports which algorithm produced them
+func GenerateRecommendationsWithOptions(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, options Options) ([]Recommendation, Algorithm) {
+	return generateRecommendationsAt(developers, matrix, recencyMatrix, strategy, options, time.Now())
+}
+
+// generateRecommendationsAt generates recommendations as if run at the given time
+func generateRecommendationsAt(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, strategy Strategy, options Options, now time.Time) ([]Recommendation, Algorithm) {
+	developers = WithoutObservers(developers, options.Observers)
+	if len(developers) > options.GreedyCutoff {
+		switch options.BigTeamMode {
+		case BigTeamSample:
+			developers = sample(developers, options.GreedyCutoff, options.Seed)
+		case BigTeamGreedy:
+			options.OptimalCutoff = 0 // The exhaustive matcher is far too slow for a team this size
+		default:
+			return []Recommendation{}, AlgorithmNone // Return empty list for too many developers
+		}
+	}
+	if !options.WideRecency {
+		recencyMatrix = countedRecency(developers, matrix, recencyMatrix)
+	}
+	if options.Seed != 0 {
+		developers = shuffled(developers, options.Seed)
+	}
+
+	var satOut []Recommendation
+	if options.SitOut != SitOutAny && options.MinGap <= 0 && len(developers) > 1 && len(developers)%2 == 1 {
+		var rec Recommendation
+		developers, rec = sitOut(developers, matrix, recencyMatrix, options.SitOut)
+		satOut = append(satOut, rec)
 	}
 
-	if len(developers) > 20 {
-		return []Recommendation{} // Return empty list for too many developers
+	if strategy.Primary() == Coverage && len(developers) <= options.OptimalCutoff {
+		recommendations := generateCoverage(developers, matrix, recencyMatrix, options.MinGap, options.planned, now)
+		return append(markRecent(recommendations, options.RecentThreshold), satOut...), AlgorithmOptimal
 	}
 
-	// Create all possible pairs with their counts
-	type pairCandidate struct {
-		devA, devB git.Developer
-		count      int
+	recommendations := generateGreedy(developers, matrix, recencyMatrix, strategyComparator(strategy, options, now), options.MinGap, options.planned, now)
+	return append(markRecent(recommendations, options.RecentThreshold), satOut...), AlgorithmGreedy
+}
+
+// strategyComparator combines the comparisons for each of the strategy's
+// components, after demoting recent pairs if the options ask for it
+func strategyComparator(strategy Strategy, options Options, now time.Time) compareFunc {
+	var comparators []compareFunc
+	if options.DemoteRecent && options.RecentThreshold > 0 {
+		comparators = append(comparators, demoteRecent(options.RecentThreshold, now))
+	}
+	for _, component := range strategy.Components() {
+		comparators = append(comparators, comparatorFor(component))
+	}
+	if len(strategy.Components()) == 0 {
+		comparators = append(comparators, compareLeastPaired)
 	}
+	return chainComparators(comparators)
+}
 
-	var candidates []pairCandidate
+// WithoutObservers returns the developers who don't have any of the observers'
+// email addresses
+func WithoutObservers(developers []git.Developer, observers []string) []git.Developer {
+	if len(observers) == 0 {
+		return developers
+	}
+	var remaining []git.Developer
+	for _, dev := range developers {
+		if !slices.ContainsFunc(dev.EmailAddresses, func(email string) bool { return slices.Contains(observers, email) }) {
+			remaining = append(remaining, dev)
+		}
+	}
+	return remaining
+}
+
+// countedRecency returns the last pairings of the developers who have paired
+// according to the matrix, leaving out any recency the counts don't account for
+func countedRecency(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix) *pairing.RecencyMatrix {
+	counted := pairing.NewRecencyMatrix()
 	for i := 0; i < len(developers); i++ {
 		for j := i + 1; j < len(developers); j++ {
-			candidates = append(candidates, pairCandidate{
-				devA:  developers[i],
-				devB:  developers[j],
-				count: matrix.CountByDeveloper(developers[i], developers[j]),
-			})
+			if matrix.CountByDeveloper(developers[i], developers[j]) == 0 {
+				continue
+			}
+			if last, ok := recencyMatrix.LastPairedByDeveloper(developers[i], developers[j]); ok {
+				counted.RecordByDeveloper(developers[i], developers[j], last)
+			}
 		}
 	}
+	return counted
+}
+
+// shuffled returns a copy of the developers in an order determined by the seed.
+// Both matchers keep the first of several equally good choices, so this shuffles
+// within ties without changing which pairs rank best.
+func shuffled(developers []git.Developer, seed int64) []git.Developer {
+	developers = append([]git.Developer(nil), developers...)
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	rng.Shuffle(len(developers), func(i, j int) {
+		developers[i], developers[j] = developers[j], developers[i]
+	})
+	return developers
+}
+
+// markRecent marks the recommendations for pairs who paired within the threshold, in days
+func markRecent(recommendations []Recommendation, threshold int) []Recommendation {
+	if threshold <= 0 {
+		return recommendations
+	}
+	for i, rec := range recommendations {
+		recommendations[i].Recent = rec.HasPaired && rec.DaysSince <= threshold
+	}
+	return recommendations
+}
+
+// generateGreedy generates pairing recommendations by ranking every possible pair
+// with the given comparison and greedily selecting pairs so each dev appears once.
+// Pairs within the minimum gap (in days) are never selected, and planned pairs
+// only for developers left without any other partner.
+func generateGreedy(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, compare compareFunc, minGap int, planned pairSet, now time.Time) []Recommendation {
+	if len(developers) < 2 {
+		return nil
+	}
+
+	candidates := buildCandidates(developers, matrix, recencyMatrix)
 
-	// Sort by count (ascending - least paired first)
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].count < candidates[j].count
+	// Stable sort keeps developer order for complete ties, making results deterministic
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return compare(candidates[i], candidates[j]) < 0
 	})
 
 	// Greedily select pairs ensuring each dev appears only once
 	used := make(map[string]bool)
 	var recommendations []Recommendation
 
-	for _, candidate := range candidates {
-		emailA := candidate.devA.CanonicalEmail()
-		emailB := candidate.devB.CanonicalEmail()
-		if !used[emailA] && !used[emailB] {
-			recommendations = append(recommendations, Recommendation{
-				A:     candidate.devA,
-				B:     candidate.devB,
-				Count: candidate.count,
-			})
+	// Planned pairs are left until everyone else has had their pick
+	for _, fallback := range []bool{false, true} {
+		for _, c := range candidates {
+			emailA := c.devA.CanonicalEmail()
+			emailB := c.devB.CanonicalEmail()
+			if used[emailA] || used[emailB] || withinGap(c, minGap, now) || planned.has(c.devA, c.devB) != fallback {
+				continue
+			}
+
+			recommendations = append(recommendations, newRecommendation(c, now))
 			used[emailA] = true
 			used[emailB] = true
 		}
 	}
 
-	// Handle unpaired developer if odd number
+	// Handle unpaired developers: one if there's an odd number, or more if the
+	// minimum gap left them without a partner
 	for _, dev := range developers {
 		email := dev.CanonicalEmail()
 		if !used[email] {
@@ -100,116 +359,53 @@ func generateLeastPaired(developers []git.Developer, matrix *pairing.Matrix) []R
 				B:     git.Developer{}, // Empty Developer object for unpaired
 				Count: 0,
 			})
-			break
 		}
 	}
 
 	return recommendations
 }
 
-// generateLeastRecent generates pairing recommendations based on least recent collaboration
-func generateLeastRecent(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix) []Recommendation {
-	n := len(developers)
-	if n < 2 {
-		return nil
-	}
-
-	if n > 20 {
-		return []Recommendation{} // Return empty list for too many developers
-	}
-
-	type pairWithRecency struct {
-		devA, devB git.Developer
-		lastTime   time.Time
-		hasData    bool
-		count      int
-	}
-
-	var allPairs []pairWithRecency
-	now := time.Now()
-
-	// Generate all possible pairs
-	for i := 0; i < n; i++ {
-		for j := i + 1; j < n; j++ {
-			devA := developers[i]
-			devB := developers[j]
-
-			lastTime, hasData := recencyMatrix.LastPairedByDeveloper(devA, devB)
-			count := matrix.CountByDeveloper(devA, devB)
-
-			allPairs = append(allPairs, pairWithRecency{
-				devA:     devA,
-				devB:     devB,
+// buildCandidates creates every possible pair of developers with their pairing history
+func buildCandidates(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix) []candidate {
+	var candidates []candidate
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			lastTime, hasData := recencyMatrix.LastPairedByDeveloper(developers[i], developers[j])
+			candidates = append(candidates, candidate{
+				devA:     developers[i],
+				devB:     developers[j],
+				count:    matrix.CountByDeveloper(developers[i], developers[j]),
+				weight:   matrix.WeightByDeveloper(developers[i], developers[j]),
 				lastTime: lastTime,
 				hasData:  hasData,
-				count:    count,
 			})
 		}
 	}
+	return candidates
+}
 
-	// Sort pairs by recency (least recent first)
-	sort.Slice(allPairs, func(i, j int) bool {
-		// Pairs that have never worked together come first
-		if !allPairs[i].hasData && allPairs[j].hasData {
-			return true
-		}
-		if allPairs[i].hasData && !allPairs[j].hasData {
-			return false
-		}
-		if !allPairs[i].hasData && !allPairs[j].hasData {
-			return false // Both have no data, order doesn't matter
-		}
-		// Both have data, sort by oldest first
-		return allPairs[i].lastTime.Before(allPairs[j].lastTime)
-	})
-
-	// Create recommendations using a greedy approach
-	var recommendations []Recommendation
-	used := make(map[string]bool)
-
-	for _, pairData := range allPairs {
-		emailA := pairData.devA.CanonicalEmail()
-		emailB := pairData.devB.CanonicalEmail()
-		if used[emailA] || used[emailB] {
-			continue
-		}
+// newRecommendation creates a recommendation from a candidate pair
+func newRecommendation(c candidate, now time.Time) Recommendation {
+	return Recommendation{
+		A:          c.devA,
+		B:          c.devB,
+		Count:      c.count,
+		LastPaired: c.lastTime,
+		DaysSince:  daysSince(c, now),
+		HasPaired:  c.hasData,
+	}
+}
 
-		daysSince := 0
-		if pairData.hasData {
-			daysSince = int(now.Sub(pairData.lastTime).Hours() / 24)
-		} else {
-			daysSince = -1 // Never paired
-		}
+// withinGap reports whether the candidate pair paired fewer than minGap days ago.
+// A minGap of zero or less never excludes a pair.
+func withinGap(c candidate, minGap int, now time.Time) bool {
+	return minGap > 0 && c.hasData && daysSince(c, now) < minGap
+}
 
-		recommendations = append(recommendations, Recommendation{
-			A:          pairData.devA,
-			B:          pairData.devB,
-			Count:      pairData.count,
-			LastPaired: pairData.lastTime,
-			DaysSince:  daysSince,
-			HasPaired:  pairData.hasData,
-		})
-
-		used[emailA] = true
-		used[emailB] = true
-	}
-
-	// Handle odd number of developers
-	if n%2 != 0 {
-		for _, dev := range developers {
-			email := dev.CanonicalEmail()
-			if !used[email] {
-				recommendations = append(recommendations, Recommendation{
-					A:         dev,
-					B:         git.Developer{}, // Empty Developer object for unpaired
-					Count:     0,
-					DaysSince: 0,
-					HasPaired: false,
-				})
-				break
-			}
-		}
+// daysSince returns the whole days since the candidate pair last paired, or -1 if they never have
+func daysSince(c candidate, now time.Time) int {
+	if !c.hasData {
+		return -1
 	}
-
-	return recommendations
+	return int(now.Sub(c.lastTime).Hours() / 24)
 }
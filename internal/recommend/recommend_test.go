@@ -1,7 +1,10 @@
 package recommend_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
 	"github.com/gypsydave5/pairstair/internal/pairing"
@@ -85,3 +88,566 @@ func TestGenerateRecommendations_SingleDeveloper(t *testing.T) {
 		t.Errorf("Expected nil for single developer, got %v", recommendations)
 	}
 }
+
+func TestGenerateRecommendations_CombinedStrategyBreaksTiesByRecency(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	// Every pair has paired exactly once, so counts alone can't separate them
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	recent := time.Now().AddDate(0, 0, -1)
+	old := time.Now().AddDate(0, 0, -30)
+	pairings := []struct {
+		a, b git.Developer
+		date time.Time
+	}{
+		{alice, bob, recent},
+		{carol, dave, recent},
+		{alice, carol, old},
+		{bob, dave, old},
+		{alice, dave, recent},
+		{bob, carol, recent},
+	}
+	for _, p := range pairings {
+		matrix.AddByDeveloper(p.a, p.b)
+		recencyMatrix.RecordByDeveloper(p.a, p.b, p.date)
+	}
+
+	strategy := recommend.Strategy("least-paired,least-recent")
+	recommendations := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, strategy)
+
+	if len(recommendations) != 2 {
+		t.Fatalf("Expected 2 recommendations, got %d", len(recommendations))
+	}
+	expected := [][2]git.Developer{{alice, carol}, {bob, dave}}
+	for i, rec := range recommendations {
+		if rec.A.CanonicalEmail() != expected[i][0].CanonicalEmail() || rec.B.CanonicalEmail() != expected[i][1].CanonicalEmail() {
+			t.Errorf("Recommendation %d: expected %s <-> %s, got %s <-> %s", i,
+				expected[i][0].CanonicalEmail(), expected[i][1].CanonicalEmail(),
+				rec.A.CanonicalEmail(), rec.B.CanonicalEmail())
+		}
+	}
+}
+
+func TestGenerateRecommendations_PrimaryStrategyWinsOverTieBreaker(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+
+	// Alice/Bob paired long ago but many times; Alice/Carol paired once, recently
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	for i := 0; i < 3; i++ {
+		matrix.AddByDeveloper(alice, bob)
+	}
+	recencyMatrix.RecordByDeveloper(alice, bob, time.Now().AddDate(0, 0, -60))
+	matrix.AddByDeveloper(alice, carol)
+	recencyMatrix.RecordByDeveloper(alice, carol, time.Now().AddDate(0, 0, -1))
+	matrix.AddByDeveloper(bob, carol)
+	recencyMatrix.RecordByDeveloper(bob, carol, time.Now().AddDate(0, 0, -2))
+
+	recommendations := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, recommend.Strategy("least-paired,least-recent"))
+
+	// Alice/Carol and Bob/Carol tie on count; Bob/Carol is less recent
+	first := recommendations[0]
+	if first.A.CanonicalEmail() != bob.CanonicalEmail() || first.B.CanonicalEmail() != carol.CanonicalEmail() {
+		t.Errorf("Expected Bob <-> Carol first, got %s <-> %s", first.A.CanonicalEmail(), first.B.CanonicalEmail())
+	}
+}
+
+func TestStrategyComponents(t *testing.T) {
+	strategy := recommend.Strategy("least-paired, least-recent")
+
+	components := strategy.Components()
+	if len(components) != 2 || components[0] != recommend.LeastPaired || components[1] != recommend.LeastRecent {
+		t.Errorf("Unexpected components: %v", components)
+	}
+	if strategy.Primary() != recommend.LeastPaired {
+		t.Errorf("Expected primary least-paired, got %s", strategy.Primary())
+	}
+	if recommend.Strategy("").Primary() != recommend.LeastPaired {
+		t.Errorf("Expected empty strategy to default to least-paired")
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_RecentThreshold(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		lastPaired time.Time
+		hasPaired  bool
+		wantRecent bool
+	}{
+		{"paired today", now.Add(-time.Hour), true, true},
+		{"paired exactly at the threshold", now.AddDate(0, 0, -7).Add(-time.Hour), true, true},
+		{"paired a day beyond the threshold", now.AddDate(0, 0, -8).Add(-time.Hour), true, false},
+		{"never paired", time.Time{}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matrix := pairing.NewMatrix()
+			recencyMatrix := pairing.NewRecencyMatrix()
+			if tt.hasPaired {
+				matrix.AddByDeveloper(alice, bob)
+				recencyMatrix.RecordByDeveloper(alice, bob, tt.lastPaired)
+			}
+
+			for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.LeastRecent, recommend.Coverage} {
+				options := recommend.DefaultOptions
+				options.RecentThreshold = 7
+				recs, _ := recommend.GenerateRecommendationsWithOptions([]git.Developer{alice, bob}, matrix, recencyMatrix, strategy, options)
+				if len(recs) != 1 {
+					t.Fatalf("Expected 1 recommendation, got %d", len(recs))
+				}
+				if recs[0].Recent != tt.wantRecent {
+					t.Errorf("%s: expected Recent to be %v with %d days since, got %v", strategy, tt.wantRecent, recs[0].DaysSince, recs[0].Recent)
+				}
+			}
+		})
+	}
+
+	t.Run("no threshold marks nothing", func(t *testing.T) {
+		matrix := pairing.NewMatrix()
+		recencyMatrix := pairing.NewRecencyMatrix()
+		matrix.AddByDeveloper(alice, bob)
+		recencyMatrix.RecordByDeveloper(alice, bob, now)
+		recs, _ := recommend.GenerateRecommendationsWithOptions([]git.Developer{alice, bob}, matrix, recencyMatrix, recommend.LeastRecent, recommend.DefaultOptions)
+		if recs[0].Recent {
+			t.Error("Expected no recommendation marked recent without a threshold")
+		}
+	})
+}
+
+func TestGenerateRecommendationsWithOptions_DemoteRecent(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+	now := time.Now()
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pair := func(a, b git.Developer, count int, last time.Time) {
+		for i := 0; i < count; i++ {
+			matrix.AddByDeveloper(a, b)
+		}
+		recencyMatrix.RecordByDeveloper(a, b, last)
+	}
+	// Alice and Bob have paired least, but did so yesterday
+	pair(alice, bob, 1, now.AddDate(0, 0, -1))
+	pair(alice, carol, 2, now.AddDate(0, 0, -30))
+	pair(bob, dave, 2, now.AddDate(0, 0, -30))
+	pair(carol, dave, 5, now.AddDate(0, 0, -30))
+	pair(alice, dave, 5, now.AddDate(0, 0, -30))
+	pair(bob, carol, 5, now.AddDate(0, 0, -30))
+
+	options := recommend.DefaultOptions
+	options.RecentThreshold = 7
+
+	recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	if recs[0].A.CanonicalEmail() != alice.CanonicalEmail() || recs[0].B.CanonicalEmail() != bob.CanonicalEmail() || !recs[0].Recent {
+		t.Errorf("Expected Alice and Bob first and marked recent without demotion, got %+v", recs[0])
+	}
+
+	options.DemoteRecent = true
+	recs, _ = recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	for _, rec := range recs {
+		if rec.Recent {
+			t.Errorf("Expected recently paired pairs to be avoided, got %s and %s", rec.A.DisplayName, rec.B.DisplayName)
+		}
+	}
+	if len(recs) != 2 || recs[0].Count != 2 || recs[1].Count != 2 {
+		t.Errorf("Expected the two pairs that paired twice, got %+v", recs)
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_MinGap(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+	now := time.Now()
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pair := func(a, b git.Developer, count int, last time.Time) {
+		for i := 0; i < count; i++ {
+			matrix.AddByDeveloper(a, b)
+		}
+		recencyMatrix.RecordByDeveloper(a, b, last)
+	}
+	// Alice and Bob have paired least, and Alice and Bob with Carol and Dave is
+	// the stalest matching, but Alice and Bob paired yesterday
+	pair(alice, bob, 1, now.AddDate(0, 0, -1))
+	pair(carol, dave, 5, now.AddDate(0, 0, -30))
+	pair(alice, carol, 2, now.AddDate(0, 0, -10))
+	pair(bob, dave, 2, now.AddDate(0, 0, -10))
+	pair(alice, dave, 5, now.AddDate(0, 0, -5))
+	pair(bob, carol, 5, now.AddDate(0, 0, -5))
+
+	hasPair := func(recs []recommend.Recommendation, a, b git.Developer) bool {
+		for _, rec := range recs {
+			if (rec.A.CanonicalEmail() == a.CanonicalEmail() && rec.B.CanonicalEmail() == b.CanonicalEmail()) ||
+				(rec.A.CanonicalEmail() == b.CanonicalEmail() && rec.B.CanonicalEmail() == a.CanonicalEmail()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			options := recommend.DefaultOptions
+			recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+			if !hasPair(recs, alice, bob) {
+				t.Errorf("Expected Alice and Bob to be recommended without a minimum gap, got %+v", recs)
+			}
+
+			options.MinGap = 3
+			recs, _ = recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+			if len(recs) != 2 || !hasPair(recs, alice, carol) || !hasPair(recs, bob, dave) {
+				t.Errorf("Expected Alice with Carol and Bob with Dave within a 3 day gap, got %+v", recs)
+			}
+		})
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_MinGapLeavesDevelopersUnpaired(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	developers := []git.Developer{alice, bob, carol}
+	now := time.Now()
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	recencyMatrix.RecordByDeveloper(alice, bob, now)
+	matrix.AddByDeveloper(alice, carol)
+	recencyMatrix.RecordByDeveloper(alice, carol, now.AddDate(0, 0, -1))
+	matrix.AddByDeveloper(bob, carol)
+	recencyMatrix.RecordByDeveloper(bob, carol, now)
+
+	options := recommend.DefaultOptions
+	options.MinGap = 2
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+			if len(recs) != 3 {
+				t.Fatalf("Expected all three developers unpaired, got %+v", recs)
+			}
+			for _, rec := range recs {
+				if len(rec.B.EmailAddresses) != 0 {
+					t.Errorf("Expected %s to be unpaired, got a pair with %s", rec.A.DisplayName, rec.B.DisplayName)
+				}
+			}
+		})
+	}
+
+	// With a shorter gap, only the pairs from today are excluded
+	options.MinGap = 1
+	recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.Coverage, options)
+	if len(recs) != 2 || recs[0].A.CanonicalEmail() != alice.CanonicalEmail() || recs[0].B.CanonicalEmail() != carol.CanonicalEmail() || recs[1].A.CanonicalEmail() != bob.CanonicalEmail() {
+		t.Errorf("Expected Alice with Carol and Bob unpaired, got %+v", recs)
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_Seed(t *testing.T) {
+	var developers []git.Developer
+	for _, entry := range []string{
+		"Alice Smith <alice@example.com>",
+		"Bob Jones <bob@example.com>",
+		"Carol Davis <carol@example.com>",
+		"Dave Brown <dave@example.com>",
+		"Eve Adams <eve@example.com>",
+		"Frank Moore <frank@example.com>",
+	} {
+		developers = append(developers, git.NewDeveloper(entry))
+	}
+	// Nobody has paired, so every pair ties
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+
+	pairsOf := func(recs []recommend.Recommendation) string {
+		var pairs []string
+		for _, rec := range recs {
+			pairs = append(pairs, rec.A.AbbreviatedName+"-"+rec.B.AbbreviatedName)
+		}
+		return strings.Join(pairs, ",")
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			options := recommend.DefaultOptions
+			recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+			if got := pairsOf(recs); got != "AS-BJ,CD-DB,EA-FM" {
+				t.Errorf("Expected alphabetical tie-breaking without a seed, got %s", got)
+			}
+
+			seen := make(map[string]bool)
+			for seed := int64(1); seed <= 10; seed++ {
+				options.Seed = seed
+				first, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+				second, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+				if pairsOf(first) != pairsOf(second) {
+					t.Errorf("Expected seed %d to reproduce %s, got %s", seed, pairsOf(first), pairsOf(second))
+				}
+				if len(first) != 3 {
+					t.Errorf("Expected 3 pairs with seed %d, got %s", seed, pairsOf(first))
+				}
+				seen[pairsOf(first)] = true
+			}
+			if len(seen) < 2 {
+				t.Errorf("Expected different seeds to rotate the recommendations, got only %v", seen)
+			}
+		})
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_SitOut(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	erin := git.NewDeveloper("Erin White <erin@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave, erin}
+	now := time.Now()
+
+	// Alice and Bob have paired the most, Carol and Dave the most recently
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	for i := 0; i < 3; i++ {
+		matrix.AddByDeveloper(alice, bob)
+	}
+	recencyMatrix.RecordByDeveloper(alice, bob, now.AddDate(0, 0, -10))
+	matrix.AddByDeveloper(carol, dave)
+	recencyMatrix.RecordByDeveloper(carol, dave, now.AddDate(0, 0, -1))
+
+	tests := []struct {
+		rule recommend.SitOutRule
+		want git.Developer
+	}{
+		{recommend.SitOutMostPaired, alice},
+		{recommend.SitOutMostRecent, carol},
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		for _, tt := range tests {
+			t.Run(string(strategy)+"/"+string(tt.rule), func(t *testing.T) {
+				options := recommend.DefaultOptions
+				options.SitOut = tt.rule
+				recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+				if len(recs) != 3 {
+					t.Fatalf("Expected two pairs and one unpaired developer, got %+v", recs)
+				}
+				var unpaired []string
+				for _, rec := range recs {
+					if len(rec.B.EmailAddresses) == 0 {
+						unpaired = append(unpaired, rec.A.CanonicalEmail())
+					}
+				}
+				if len(unpaired) != 1 || unpaired[0] != tt.want.CanonicalEmail() {
+					t.Errorf("Expected %s to be unpaired, got %v", tt.want.CanonicalEmail(), unpaired)
+				}
+			})
+		}
+	}
+
+	// Otherwise greedy matching leaves out whoever is left over
+	recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, recommend.DefaultOptions)
+	if last := recs[len(recs)-1]; len(last.B.EmailAddresses) != 0 || last.A.CanonicalEmail() != erin.CanonicalEmail() {
+		t.Errorf("Expected Erin left over without a rule, got %+v", recs)
+	}
+
+	// An even team has nobody to leave out
+	options := recommend.DefaultOptions
+	options.SitOut = recommend.SitOutMostPaired
+	recs, _ = recommend.GenerateRecommendationsWithOptions(developers[:4], matrix, recencyMatrix, recommend.LeastPaired, options)
+	for _, rec := range recs {
+		if len(rec.B.EmailAddresses) == 0 {
+			t.Errorf("Expected everyone paired in an even team, got %s unpaired", rec.A.DisplayName)
+		}
+	}
+}
+
+func TestParseSitOutRule(t *testing.T) {
+	for name, want := range map[string]recommend.SitOutRule{
+		"most-paired": recommend.SitOutMostPaired,
+		"most-recent": recommend.SitOutMostRecent,
+		"any":         recommend.SitOutAny,
+		"":            recommend.SitOutAny,
+	} {
+		got, err := recommend.ParseSitOutRule(name)
+		if err != nil || got != want {
+			t.Errorf("ParseSitOutRule(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := recommend.ParseSitOutRule("least-paired"); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_BigTeamMode(t *testing.T) {
+	var developers []git.Developer
+	for i := 0; i < 16; i++ {
+		developers = append(developers, git.NewDeveloper(fmt.Sprintf("Dev %02d <dev%02d@example.com>", i, i)))
+	}
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	options := recommend.Options{GreedyCutoff: 14, OptimalCutoff: 12, Seed: 7}
+
+	tests := []struct {
+		mode      recommend.BigTeamMode
+		wantPairs int
+		wantAlg   recommend.Algorithm
+	}{
+		{recommend.BigTeamSkip, 0, recommend.AlgorithmNone},
+		{"", 0, recommend.AlgorithmNone},
+		{recommend.BigTeamSample, 7, recommend.AlgorithmGreedy},
+		{recommend.BigTeamGreedy, 8, recommend.AlgorithmGreedy},
+	}
+
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		for _, tt := range tests {
+			t.Run(string(strategy)+"/"+string(tt.mode), func(t *testing.T) {
+				options := options
+				options.BigTeamMode = tt.mode
+				recs, alg := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+				if alg != tt.wantAlg {
+					t.Errorf("Expected the %s algorithm, got %s", tt.wantAlg, alg)
+				}
+				if len(recs) != tt.wantPairs {
+					t.Fatalf("Expected %d pairs, got %+v", tt.wantPairs, recs)
+				}
+				seen := make(map[string]bool)
+				for _, rec := range recs {
+					if len(rec.B.EmailAddresses) == 0 {
+						t.Errorf("Expected everyone recommended to have a partner, got %s unpaired", rec.A.DisplayName)
+					}
+					for _, email := range []string{rec.A.CanonicalEmail(), rec.B.CanonicalEmail()} {
+						if seen[email] {
+							t.Errorf("Expected %s to be recommended once, got %+v", email, recs)
+						}
+						seen[email] = true
+					}
+				}
+			})
+		}
+	}
+
+	// The same seed samples the same developers, and another seed samples others
+	pairsOf := func(recs []recommend.Recommendation) string {
+		var pairs []string
+		for _, rec := range recs {
+			pairs = append(pairs, rec.A.CanonicalEmail()+"-"+rec.B.CanonicalEmail())
+		}
+		return strings.Join(pairs, ",")
+	}
+	options.BigTeamMode = recommend.BigTeamSample
+	first, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	again, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	options.Seed = 8
+	other, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastPaired, options)
+	if pairsOf(first) != pairsOf(again) {
+		t.Errorf("Expected the same sample for the same seed, got %s and %s", pairsOf(first), pairsOf(again))
+	}
+	if pairsOf(first) == pairsOf(other) {
+		t.Errorf("Expected a different sample for a different seed, got %s for both", pairsOf(first))
+	}
+}
+
+func TestParseBigTeamMode(t *testing.T) {
+	for name, want := range map[string]recommend.BigTeamMode{
+		"skip":   recommend.BigTeamSkip,
+		"sample": recommend.BigTeamSample,
+		"greedy": recommend.BigTeamGreedy,
+		"":       recommend.BigTeamSkip,
+	} {
+		got, err := recommend.ParseBigTeamMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseBigTeamMode(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := recommend.ParseBigTeamMode("optimal"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_Observers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	manager := git.NewDeveloper("Mo Manager <mo@example.com>,<mo@old.com>")
+	developers := []git.Developer{alice, bob, carol, manager}
+
+	// Everyone has paired with Alice except the manager, who would be her best partner
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	for _, dev := range []git.Developer{bob, carol} {
+		matrix.AddByDeveloper(alice, dev)
+		recencyMatrix.RecordByDeveloper(alice, dev, time.Now())
+	}
+
+	options := recommend.DefaultOptions
+	options.Observers = []string{"mo@old.com"}
+	for _, strategy := range []recommend.Strategy{recommend.LeastPaired, recommend.Coverage} {
+		t.Run(string(strategy), func(t *testing.T) {
+			recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, strategy, options)
+			if len(recs) != 2 {
+				t.Fatalf("Expected one pair and one unpaired developer among the other three, got %+v", recs)
+			}
+			for _, rec := range recs {
+				if rec.A.CanonicalEmail() == manager.CanonicalEmail() || rec.B.CanonicalEmail() == manager.CanonicalEmail() {
+					t.Errorf("Expected the observer never to be recommended, got %+v", rec)
+				}
+			}
+		})
+	}
+
+	if got := recommend.WithoutObservers(developers, nil); len(got) != 4 {
+		t.Errorf("Expected no one removed without observers, got %v", got)
+	}
+}
+
+func TestGenerateRecommendationsWithOptions_RecencyOutsideCounts(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	developers := []git.Developer{alice, bob}
+	now := time.Now()
+
+	// Alice and Bob last paired 400 days ago, long before the window they're counted in
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	recencyMatrix.RecordByDeveloper(alice, bob, now.AddDate(0, 0, -400))
+
+	options := recommend.DefaultOptions
+	recs, _ := recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastRecent, options)
+	if len(recs) != 1 || recs[0].Count != 0 || recs[0].HasPaired || recs[0].DaysSince != -1 {
+		t.Errorf("Expected a pair with no count to have never paired, got %+v", recs)
+	}
+
+	options.WideRecency = true
+	recs, _ = recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastRecent, options)
+	if len(recs) != 1 || !recs[0].HasPaired || recs[0].DaysSince != 400 {
+		t.Errorf("Expected the wider recency to be kept, got %+v", recs)
+	}
+
+	// A counted pair keeps its recency either way
+	matrix.AddByDeveloper(alice, bob)
+	recs, _ = recommend.GenerateRecommendationsWithOptions(developers, matrix, recencyMatrix, recommend.LeastRecent, recommend.DefaultOptions)
+	if len(recs) != 1 || !recs[0].HasPaired || recs[0].DaysSince != 400 {
+		t.Errorf("Expected a counted pair to keep its recency, got %+v", recs)
+	}
+}
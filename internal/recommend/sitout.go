@@ -0,0 +1,79 @@
+package recommend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+// SitOutRule chooses which developer is left unpaired when there's an odd number
+type SitOutRule string
+
+const (
+	// SitOutAny leaves it to the matcher, which leaves out whoever is left over
+	SitOutAny SitOutRule = ""
+	// SitOutMostPaired leaves out the developer who has paired the most with the
+	// rest of the team, so the one most in need of a partner gets one
+	SitOutMostPaired SitOutRule = "most-paired"
+	// SitOutMostRecent leaves out the developer who paired with anyone on the
+	// team most recently
+	SitOutMostRecent SitOutRule = "most-recent"
+)
+
+// ParseSitOutRule converts a rule name to a SitOutRule. "any" and the empty
+// string both leave the choice to the matcher.
+func ParseSitOutRule(name string) (SitOutRule, error) {
+	switch rule := SitOutRule(name); rule {
+	case SitOutMostPaired, SitOutMostRecent:
+		return rule, nil
+	case SitOutAny, "any":
+		return SitOutAny, nil
+	default:
+		return "", fmt.Errorf("unknown sit-out rule %q: use 'most-paired', 'most-recent' or 'any'", name)
+	}
+}
+
+// chooseSitOut returns the index of the developer the rule leaves unpaired. The
+// first of several equally good choices wins, keeping results deterministic.
+func chooseSitOut(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, rule SitOutRule) int {
+	chosen := 0
+	var bestWeight float64
+	var bestTime time.Time
+	for i, dev := range developers {
+		var weight float64
+		var latest time.Time
+		for j, other := range developers {
+			if i == j {
+				continue
+			}
+			weight += matrix.WeightByDeveloper(dev, other)
+			if last, ok := recencyMatrix.LastPairedByDeveloper(dev, other); ok && last.After(latest) {
+				latest = last
+			}
+		}
+
+		better := false
+		switch rule {
+		case SitOutMostRecent:
+			better = latest.After(bestTime) || (latest.Equal(bestTime) && weight > bestWeight)
+		default: // SitOutMostPaired
+			better = weight > bestWeight || (weight == bestWeight && latest.After(bestTime))
+		}
+		if i == 0 || better {
+			chosen, bestWeight, bestTime = i, weight, latest
+		}
+	}
+	return chosen
+}
+
+// sitOut removes the developer chosen by the rule, returning the rest and a
+// recommendation leaving the chosen developer unpaired
+func sitOut(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, rule SitOutRule) ([]git.Developer, Recommendation) {
+	chosen := chooseSitOut(developers, matrix, recencyMatrix, rule)
+	rest := make([]git.Developer, 0, len(developers)-1)
+	rest = append(rest, developers[:chosen]...)
+	rest = append(rest, developers[chosen+1:]...)
+	return rest, Recommendation{A: developers[chosen]}
+}
@@ -0,0 +1,356 @@
+// Package stats provides derived statistics about developer pairing, built on
+// top of the pair matrices and parsed commits.
+//
+// The package focuses on summarising collaboration data for reports,
+// separating these calculations from matrix construction and output formatting.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+)
+
+// LoneWolf is a developer who committed on their own but never paired
+type LoneWolf struct {
+	Developer   git.Developer
+	SoloCommits int
+}
+
+// LoneWolves returns the developers with solo commits who never paired with any
+// other developer, ordered by most solo commits first
+func LoneWolves(developers []git.Developer, matrix *pairing.Matrix, soloCommits map[string]int) []LoneWolf {
+	var loneWolves []LoneWolf
+	for _, dev := range developers {
+		solo := soloCommits[dev.CanonicalEmail()]
+		if solo == 0 || hasPairedWithAnyone(dev, developers, matrix) {
+			continue
+		}
+		loneWolves = append(loneWolves, LoneWolf{Developer: dev, SoloCommits: solo})
+	}
+
+	sort.SliceStable(loneWolves, func(i, j int) bool {
+		return loneWolves[i].SoloCommits > loneWolves[j].SoloCommits
+	})
+	return loneWolves
+}
+
+// hasPairedWithAnyone reports whether the developer has paired with any of the others
+func hasPairedWithAnyone(dev git.Developer, developers []git.Developer, matrix *pairing.Matrix) bool {
+	for _, other := range developers {
+		if matrix.CountByDeveloper(dev, other) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LastPairing records the most recent pairing of a developer with anyone
+type LastPairing struct {
+	Developer git.Developer
+	Partner   git.Developer
+	Date      time.Time
+	HasPaired bool
+}
+
+// LastPairings returns, for each developer, their most recent pairing partner
+// and the date they paired, taken as the latest date across all their pairs.
+// Developers who have never paired have HasPaired set to false.
+func LastPairings(developers []git.Developer, recencyMatrix *pairing.RecencyMatrix) []LastPairing {
+	lastPairings := make([]LastPairing, 0, len(developers))
+	for _, dev := range developers {
+		lastPairings = append(lastPairings, lastPairingFor(dev, developers, recencyMatrix))
+	}
+	return lastPairings
+}
+
+// lastPairingFor finds the developer's most recent pairing among the others
+func lastPairingFor(dev git.Developer, developers []git.Developer, recencyMatrix *pairing.RecencyMatrix) LastPairing {
+	last := LastPairing{Developer: dev}
+	for _, other := range developers {
+		date, ok := recencyMatrix.LastPairedByDeveloper(dev, other)
+		if ok && (!last.HasPaired || date.After(last.Date)) {
+			last.Partner = other
+			last.Date = date
+			last.HasPaired = true
+		}
+	}
+	return last
+}
+
+// Coverage describes how many of the possible pairs of developers have paired
+type Coverage struct {
+	Paired   int
+	Possible int
+}
+
+// Ratio returns the fraction of possible pairs that have paired, between 0 and 1
+func (c Coverage) Ratio() float64 {
+	if c.Possible == 0 {
+		return 0
+	}
+	return float64(c.Paired) / float64(c.Possible)
+}
+
+// CalculateCoverage counts the pairs of developers who have paired at least once
+// out of all possible pairs
+func CalculateCoverage(developers []git.Developer, matrix *pairing.Matrix) Coverage {
+	var coverage Coverage
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			coverage.Possible++
+			if matrix.CountByDeveloper(developers[i], developers[j]) > 0 {
+				coverage.Paired++
+			}
+		}
+	}
+	return coverage
+}
+
+// neverPairedDebt is how much a partner someone has never paired with adds to
+// their pairing debt; a partner they have paired with adds at most 1
+const neverPairedDebt = 2.0
+
+// PairingDebt is how overdue a developer is to pair with someone new
+type PairingDebt struct {
+	Developer   git.Developer
+	Score       float64
+	NeverPaired int // Number of teammates they have never paired with
+}
+
+// PairingDebts scores each developer by how overdue they are to pair with someone
+// new, ordered by highest debt first.
+//
+// A developer's debt is the sum, over every other developer, of:
+//   - 2 if they have never paired (in the analyzed history), or
+//   - the days since they last paired divided by the horizon, capped at 1.
+//
+// The horizon is normally the length of the analysis window, so a pair who last
+// worked together at the start of the window adds nearly 1 and a pair who paired
+// today adds 0. Never having paired counts double, as that is a whole new pairing.
+func PairingDebts(developers []git.Developer, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, now time.Time, horizon time.Duration) []PairingDebt {
+	debts := make([]PairingDebt, 0, len(developers))
+	for _, dev := range developers {
+		debt := PairingDebt{Developer: dev}
+		for _, other := range developers {
+			if other.CanonicalEmail() == dev.CanonicalEmail() {
+				continue
+			}
+			lastPaired, hasPaired := recencyMatrix.LastPairedByDeveloper(dev, other)
+			if !hasPaired || matrix.CountByDeveloper(dev, other) == 0 {
+				debt.Score += neverPairedDebt
+				debt.NeverPaired++
+				continue
+			}
+			if horizon > 0 {
+				debt.Score += min(float64(now.Sub(lastPaired))/float64(horizon), 1)
+			}
+		}
+		debts = append(debts, debt)
+	}
+
+	sort.SliceStable(debts, func(i, j int) bool {
+		return debts[i].Score > debts[j].Score
+	})
+	return debts
+}
+
+// Newcomer is a developer who paired for the first time recently
+type Newcomer struct {
+	Developer   git.Developer
+	FirstPaired time.Time
+}
+
+// Newcomers returns the developers whose first pairing in the participation was
+// on or after the calendar date of since, earliest first. For the result to mean
+// "new", the participation must cover the whole history, not just the window.
+func Newcomers(developers []git.Developer, participation *pairing.Participation, since time.Time) []Newcomer {
+	sinceDate := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	var newcomers []Newcomer
+	for _, dev := range developers {
+		first, ok := participation.FirstPaired(dev.CanonicalEmail())
+		if ok && !first.Before(sinceDate) {
+			newcomers = append(newcomers, Newcomer{Developer: dev, FirstPaired: first})
+		}
+	}
+	sort.SliceStable(newcomers, func(i, j int) bool {
+		return newcomers[i].FirstPaired.Before(newcomers[j].FirstPaired)
+	})
+	return newcomers
+}
+
+// StaleDeveloper is how long it has been since a developer last paired with anyone
+type StaleDeveloper struct {
+	LastPairing
+	DaysSince int  // Days since they last paired; meaningless if they never paired
+	Stale     bool // Never paired, or paired longer ago than the threshold
+}
+
+// StaleDevelopers returns each developer's days since they last paired with anyone,
+// most overdue first, flagging those who have never paired or last paired more than
+// threshold days before now. Developers who have never paired come first.
+func StaleDevelopers(developers []git.Developer, recencyMatrix *pairing.RecencyMatrix, now time.Time, threshold int) []StaleDeveloper {
+	staleDevelopers := make([]StaleDeveloper, 0, len(developers))
+	for _, lp := range LastPairings(developers, recencyMatrix) {
+		stale := StaleDeveloper{LastPairing: lp, Stale: !lp.HasPaired}
+		if lp.HasPaired {
+			stale.DaysSince = int(now.Sub(lp.Date).Hours() / 24)
+			stale.Stale = stale.DaysSince > threshold
+		}
+		staleDevelopers = append(staleDevelopers, stale)
+	}
+
+	sort.SliceStable(staleDevelopers, func(i, j int) bool {
+		a, b := staleDevelopers[i], staleDevelopers[j]
+		if a.HasPaired != b.HasPaired {
+			return !a.HasPaired
+		}
+		return a.DaysSince > b.DaysSince
+	})
+	return staleDevelopers
+}
+
+// WeekdayPairing is the number of pairing days that fell on a day of the week
+type WeekdayPairing struct {
+	Weekday  time.Weekday
+	PairDays int
+}
+
+// PairingByWeekday totals the days each pair paired by the day of the week they fell
+// on, from Monday to Sunday. Days are bucketed by the commit dates, as in the matrix,
+// so the totals add up to the sum of the matrix's counts.
+func PairingByWeekday(participation *pairing.Participation) []WeekdayPairing {
+	byWeekday := make([]WeekdayPairing, 7)
+	for i := range byWeekday {
+		byWeekday[i].Weekday = time.Weekday((i + 1) % 7)
+	}
+	for day, pairs := range participation.PairDays() {
+		byWeekday[(int(day.Weekday())+6)%7].PairDays += pairs
+	}
+	return byWeekday
+}
+
+// Attribution is how the commits a pair made together were attributed: how many
+// each of them authored with the other as co-author. A is the more frequent author.
+type Attribution struct {
+	A, B      git.Developer
+	AAuthored int
+	BAuthored int
+}
+
+// Total returns the number of commits the pair made together
+func (a Attribution) Total() int {
+	return a.AAuthored + a.BAuthored
+}
+
+// AuthorShare returns the fraction of the pair's commits authored by A, between 0.5 and 1
+func (a Attribution) AuthorShare() float64 {
+	if a.Total() == 0 {
+		return 0
+	}
+	return float64(a.AAuthored) / float64(a.Total())
+}
+
+// Attributions returns the attribution of every pair with commits together, most
+// one-sided first, so that pairs where one person always authors (perhaps always
+// driving) stand out. Ties are ordered by the most commits.
+func Attributions(developers []git.Developer, matrix *pairing.Matrix) []Attribution {
+	var attributions []Attribution
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			a := Attribution{
+				A:         developers[i],
+				B:         developers[j],
+				AAuthored: matrix.AttributionsByDeveloper(developers[i], developers[j]),
+				BAuthored: matrix.AttributionsByDeveloper(developers[j], developers[i]),
+			}
+			if a.Total() == 0 {
+				continue
+			}
+			if a.BAuthored > a.AAuthored {
+				a.A, a.B, a.AAuthored, a.BAuthored = a.B, a.A, a.BAuthored, a.AAuthored
+			}
+			attributions = append(attributions, a)
+		}
+	}
+
+	sort.SliceStable(attributions, func(i, j int) bool {
+		if attributions[i].AuthorShare() != attributions[j].AuthorShare() {
+			return attributions[i].AuthorShare() > attributions[j].AuthorShare()
+		}
+		return attributions[i].Total() > attributions[j].Total()
+	})
+	return attributions
+}
+
+// MissingTrailer is how many of a developer's commits had no co-authors at all
+type MissingTrailer struct {
+	Developer git.Developer
+	Commits   int
+	Missing   int
+}
+
+// Fraction returns the fraction of the developer's commits with no co-authors
+func (m MissingTrailer) Fraction() float64 {
+	if m.Commits == 0 {
+		return 0
+	}
+	return float64(m.Missing) / float64(m.Commits)
+}
+
+// MissingTrailers returns, for each developer who authored commits, how many had
+// no co-authors, ordered by the highest fraction first and then the most commits.
+// A team that pairs but has a high fraction may not be recording its pairing.
+func MissingTrailers(developers []git.Developer, authored, missing map[string]int) []MissingTrailer {
+	var missingTrailers []MissingTrailer
+	for _, dev := range developers {
+		commits := authored[dev.CanonicalEmail()]
+		if commits == 0 {
+			continue
+		}
+		missingTrailers = append(missingTrailers, MissingTrailer{Developer: dev, Commits: commits, Missing: missing[dev.CanonicalEmail()]})
+	}
+
+	sort.SliceStable(missingTrailers, func(i, j int) bool {
+		if missingTrailers[i].Fraction() != missingTrailers[j].Fraction() {
+			return missingTrailers[i].Fraction() > missingTrailers[j].Fraction()
+		}
+		return missingTrailers[i].Commits > missingTrailers[j].Commits
+	})
+	return missingTrailers
+}
+
+// Metrics are the names of the single values Metric can report
+var Metrics = []string{"coverage", "pairs", "developers", "commits", "solo", "paired", "mobbed"}
+
+// Metric returns a single value for scripts and dashboards:
+//   - coverage: the fraction of possible pairs that have paired, e.g. "0.62"
+//   - pairs: the number of pairs that have paired
+//   - developers: the number of developers
+//   - commits: the number of commits analyzed
+//   - solo, paired, mobbed: the fraction of the commits made by one developer, by
+//     two, or by three or more, e.g. "0.25"
+func Metric(name string, developers []git.Developer, matrix *pairing.Matrix, commits []git.Commit, sizes pairing.CommitSizes) (string, error) {
+	switch name {
+	case "coverage":
+		return fmt.Sprintf("%.2f", CalculateCoverage(developers, matrix).Ratio()), nil
+	case "pairs":
+		return fmt.Sprint(CalculateCoverage(developers, matrix).Paired), nil
+	case "developers":
+		return fmt.Sprint(len(developers)), nil
+	case "commits":
+		return fmt.Sprint(len(commits)), nil
+	case "solo":
+		return fmt.Sprintf("%.2f", sizes.Share(sizes.Solo)), nil
+	case "paired":
+		return fmt.Sprintf("%.2f", sizes.Share(sizes.Pairs)), nil
+	case "mobbed":
+		return fmt.Sprintf("%.2f", sizes.Share(sizes.Mobs)), nil
+	default:
+		return "", fmt.Errorf("unknown metric: %s (expected one of %s)", name, strings.Join(Metrics, ", "))
+	}
+}
@@ -0,0 +1,495 @@
+package stats_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/pairing"
+	"github.com/gypsydave5/pairstair/internal/stats"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+func TestLoneWolves(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		// Carol only ever commits alone
+		{Date: day, Author: carol},
+		{Date: day.AddDate(0, 0, 1), Author: carol},
+		// Alice commits alone sometimes, but also pairs with Bob
+		{Date: day, Author: alice},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+	}
+
+	matrix, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+	soloCommits := pairing.CountSoloCommits(team.Empty, commits, false)
+
+	loneWolves := stats.LoneWolves(developers, matrix, soloCommits)
+
+	if len(loneWolves) != 1 {
+		t.Fatalf("Expected 1 lone wolf, got %d: %v", len(loneWolves), loneWolves)
+	}
+	if loneWolves[0].Developer.CanonicalEmail() != carol.CanonicalEmail() {
+		t.Errorf("Expected Carol to be the lone wolf, got %s", loneWolves[0].Developer.CanonicalEmail())
+	}
+	if loneWolves[0].SoloCommits != 2 {
+		t.Errorf("Expected 2 solo commits, got %d", loneWolves[0].SoloCommits)
+	}
+}
+
+func TestLoneWolvesHonorsTeamFiltering(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	outsider := git.NewDeveloper("Olly Outsider <olly@example.com>")
+	teamObj := team.NewTeamFromDevelopers([]git.Developer{alice})
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		// Alice pairs only with someone outside the team, and once solo
+		{Date: day, Author: alice, CoAuthors: []git.Developer{outsider}},
+		{Date: day, Author: outsider},
+	}
+
+	matrix, _, developers := pairing.BuildPairMatrix(teamObj, commits, true)
+	soloCommits := pairing.CountSoloCommits(teamObj, commits, true)
+
+	loneWolves := stats.LoneWolves(developers, matrix, soloCommits)
+
+	if len(loneWolves) != 1 || loneWolves[0].Developer.CanonicalEmail() != alice.CanonicalEmail() {
+		t.Fatalf("Expected only Alice as a lone wolf within the team, got %v", loneWolves)
+	}
+	if loneWolves[0].SoloCommits != 1 {
+		t.Errorf("Expected the commit with a non-team co-author to count as solo, got %d", loneWolves[0].SoloCommits)
+	}
+}
+
+func TestMissingTrailers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		// Alice always records her pairing
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day, Author: alice, CoAuthors: []git.Developer{carol}},
+		// Bob records half of it
+		{Date: day, Author: bob, CoAuthors: []git.Developer{alice}},
+		{Date: day, Author: bob},
+		// Carol never does
+		{Date: day, Author: carol},
+		{Date: day, Author: carol},
+		{Date: day, Author: carol},
+		// Dave is only ever a co-author
+		{Date: day, Author: alice, CoAuthors: []git.Developer{dave}},
+	}
+
+	_, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+	authored, missing := pairing.CountMissingTrailers(team.Empty, commits, false)
+
+	missingTrailers := stats.MissingTrailers(developers, authored, missing)
+
+	expected := []struct {
+		email    string
+		commits  int
+		missing  int
+		fraction float64
+	}{
+		{carol.CanonicalEmail(), 3, 3, 1},
+		{bob.CanonicalEmail(), 2, 1, 0.5},
+		{alice.CanonicalEmail(), 3, 0, 0},
+	}
+	if len(missingTrailers) != len(expected) {
+		t.Fatalf("Expected %d authors, got %d: %v", len(expected), len(missingTrailers), missingTrailers)
+	}
+	for i, want := range expected {
+		got := missingTrailers[i]
+		if got.Developer.CanonicalEmail() != want.email || got.Commits != want.commits || got.Missing != want.missing || got.Fraction() != want.fraction {
+			t.Errorf("Expected %s with %d of %d missing (%.2f) at %d, got %s with %d of %d (%.2f)",
+				want.email, want.missing, want.commits, want.fraction, i,
+				got.Developer.CanonicalEmail(), got.Missing, got.Commits, got.Fraction())
+		}
+	}
+}
+
+func TestLastPairings(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	recencyMatrix := pairing.NewRecencyMatrix()
+	older := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	recencyMatrix.RecordByDeveloper(alice, bob, older)
+	recencyMatrix.RecordByDeveloper(alice, carol, newer)
+
+	lastPairings := stats.LastPairings(developers, recencyMatrix)
+
+	if len(lastPairings) != 4 {
+		t.Fatalf("Expected a summary for each of 4 developers, got %d", len(lastPairings))
+	}
+
+	tests := []struct {
+		developer git.Developer
+		partner   git.Developer
+		date      time.Time
+		hasPaired bool
+	}{
+		{alice, carol, newer, true},
+		{bob, alice, older, true},
+		{carol, alice, newer, true},
+		{dave, git.Developer{}, time.Time{}, false},
+	}
+	for i, tt := range tests {
+		got := lastPairings[i]
+		if got.Developer.CanonicalEmail() != tt.developer.CanonicalEmail() {
+			t.Errorf("Summary %d: expected developer %s, got %s", i, tt.developer.CanonicalEmail(), got.Developer.CanonicalEmail())
+		}
+		if got.HasPaired != tt.hasPaired {
+			t.Errorf("%s: expected HasPaired %t, got %t", tt.developer.DisplayName, tt.hasPaired, got.HasPaired)
+		}
+		if got.Partner.CanonicalEmail() != tt.partner.CanonicalEmail() {
+			t.Errorf("%s: expected partner %q, got %q", tt.developer.DisplayName, tt.partner.CanonicalEmail(), got.Partner.CanonicalEmail())
+		}
+		if !got.Date.Equal(tt.date) {
+			t.Errorf("%s: expected date %v, got %v", tt.developer.DisplayName, tt.date, got.Date)
+		}
+	}
+}
+
+func TestCalculateCoverage(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	matrix := pairing.NewMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(alice, bob)
+	matrix.AddByDeveloper(carol, dave)
+
+	coverage := stats.CalculateCoverage(developers, matrix)
+
+	if coverage.Paired != 2 || coverage.Possible != 6 {
+		t.Errorf("Expected 2 of 6 pairs covered, got %d of %d", coverage.Paired, coverage.Possible)
+	}
+	if ratio := coverage.Ratio(); ratio < 0.333 || ratio > 0.334 {
+		t.Errorf("Expected ratio of 1/3, got %f", ratio)
+	}
+	if ratio := (stats.Coverage{}).Ratio(); ratio != 0 {
+		t.Errorf("Expected zero ratio with no possible pairs, got %f", ratio)
+	}
+}
+
+func TestPairingDebts(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	now := time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC)
+	horizon := 28 * 24 * time.Hour
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	pair := func(a, b git.Developer, daysAgo int) {
+		matrix.AddByDeveloper(a, b)
+		recencyMatrix.RecordByDeveloper(a, b, now.AddDate(0, 0, -daysAgo))
+	}
+	// Alice pairs with everyone, recently; Dave has only ever paired with Alice, long ago
+	pair(alice, bob, 0)
+	pair(alice, carol, 7)
+	pair(alice, dave, 21)
+	pair(bob, carol, 14)
+
+	debts := stats.PairingDebts(developers, matrix, recencyMatrix, now, horizon)
+
+	expected := []struct {
+		email       string
+		score       float64
+		neverPaired int
+	}{
+		{"dave@example.com", 0.75 + 2 + 2, 2},
+		{"carol@example.com", 0.25 + 0.5 + 2, 1},
+		{"bob@example.com", 0 + 0.5 + 2, 1},
+		{"alice@example.com", 0 + 0.25 + 0.75, 0},
+	}
+	if len(debts) != len(expected) {
+		t.Fatalf("Expected %d debts, got %d", len(expected), len(debts))
+	}
+	for i, want := range expected {
+		got := debts[i]
+		if got.Developer.CanonicalEmail() != want.email {
+			t.Errorf("Position %d: expected %s, got %s", i, want.email, got.Developer.CanonicalEmail())
+			continue
+		}
+		if got.Score < want.score-1e-9 || got.Score > want.score+1e-9 {
+			t.Errorf("%s: expected score %.2f, got %.2f", want.email, want.score, got.Score)
+		}
+		if got.NeverPaired != want.neverPaired {
+			t.Errorf("%s: expected %d never paired, got %d", want.email, want.neverPaired, got.NeverPaired)
+		}
+	}
+}
+
+func TestNewcomers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	since := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	// Alice and Bob are established; Carol first paired in the window, on the day
+	// it starts; Dave has never paired
+	commits := []git.Commit{
+		{Date: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC), Author: carol, CoAuthors: []git.Developer{alice}},
+		{Date: time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC), Author: dave},
+	}
+	participation := pairing.BuildParticipation(team.Empty, commits, false, pairing.BuildOptions{})
+
+	newcomers := stats.Newcomers(developers, participation, since)
+
+	if len(newcomers) != 1 {
+		t.Fatalf("Expected only Carol to be a newcomer, got %v", newcomers)
+	}
+	if newcomers[0].Developer.CanonicalEmail() != "carol@example.com" {
+		t.Errorf("Expected Carol to be the newcomer, got %s", newcomers[0].Developer.CanonicalEmail())
+	}
+	if got := newcomers[0].FirstPaired.Format("2006-01-02"); got != "2024-06-01" {
+		t.Errorf("Expected Carol to have first paired on 2024-06-01, got %s", got)
+	}
+}
+
+func TestNewcomersInTeamCountOnlyTeamPairing(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	outsider := git.NewDeveloper("Olive Out <olive@example.com>")
+	teamObj, err := team.NewTeam([]string{"Alice Smith <alice@example.com>", "Bob Jones <bob@example.com>"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// Bob paired with someone outside the team long ago, but first paired with a
+	// team member in the window
+	commits := []git.Commit{
+		{Date: time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC), Author: bob, CoAuthors: []git.Developer{outsider}},
+		{Date: time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+	}
+	participation := pairing.BuildParticipation(teamObj, commits, true, pairing.BuildOptions{})
+
+	newcomers := stats.Newcomers([]git.Developer{alice, bob}, participation, since)
+
+	if len(newcomers) != 2 {
+		t.Fatalf("Expected both team members to be newcomers to pairing in the team, got %v", newcomers)
+	}
+}
+
+func TestStaleDevelopers(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	erin := git.NewDeveloper("Erin Ford <erin@example.com>")
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	recency := pairing.NewRecencyMatrix()
+	recency.RecordByDeveloper(alice, bob, now.AddDate(0, 0, -20))
+	recency.RecordByDeveloper(bob, carol, now.AddDate(0, 0, -14))
+	recency.RecordByDeveloper(carol, dave, now.AddDate(0, 0, -15))
+
+	staleDevelopers := stats.StaleDevelopers([]git.Developer{alice, bob, carol, dave, erin}, recency, now, 14)
+
+	var got []string
+	for _, sd := range staleDevelopers {
+		got = append(got, fmt.Sprintf("%s %d %t", sd.Developer.AbbreviatedName, sd.DaysSince, sd.Stale))
+	}
+	// Erin never paired so comes first; Bob and Carol paired within the threshold
+	want := []string{"EF 0 true", "AS 20 true", "DB 15 true", "BJ 14 false", "CD 14 false"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPairingDebtsCapsStaleness(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	now := time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC)
+
+	matrix := pairing.NewMatrix()
+	recencyMatrix := pairing.NewRecencyMatrix()
+	matrix.AddByDeveloper(alice, bob)
+	recencyMatrix.RecordByDeveloper(alice, bob, now.AddDate(0, -6, 0))
+
+	debts := stats.PairingDebts([]git.Developer{alice, bob}, matrix, recencyMatrix, now, 7*24*time.Hour)
+
+	for _, debt := range debts {
+		if debt.Score != 1 {
+			t.Errorf("Expected staleness beyond the horizon to be capped at 1, got %.2f", debt.Score)
+		}
+	}
+}
+
+func TestPairingByWeekday(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	newYork := time.FixedZone("EDT", -4*60*60)
+
+	commits := []git.Commit{
+		// Monday 3 June 2024: three pairs in one mob, and Alice-Bob again the same day
+		{Date: time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob, carol}},
+		{Date: time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+		// Wednesday 5 June 2024
+		{Date: time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC), Author: bob, CoAuthors: []git.Developer{carol}},
+		// Friday evening in New York, already Saturday in UTC: bucketed by the commit's own date
+		{Date: time.Date(2024, 6, 7, 22, 0, 0, 0, newYork), Author: alice, CoAuthors: []git.Developer{carol}},
+		// Sunday 9 June 2024
+		{Date: time.Date(2024, 6, 9, 10, 0, 0, 0, time.UTC), Author: alice, CoAuthors: []git.Developer{bob}},
+		// A solo commit on Tuesday doesn't count
+		{Date: time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC), Author: alice},
+	}
+
+	participation := pairing.BuildParticipation(team.Empty, commits, false, pairing.BuildOptions{})
+	byWeekday := stats.PairingByWeekday(participation)
+
+	expected := []stats.WeekdayPairing{
+		{Weekday: time.Monday, PairDays: 3},
+		{Weekday: time.Tuesday, PairDays: 0},
+		{Weekday: time.Wednesday, PairDays: 1},
+		{Weekday: time.Thursday, PairDays: 0},
+		{Weekday: time.Friday, PairDays: 1},
+		{Weekday: time.Saturday, PairDays: 0},
+		{Weekday: time.Sunday, PairDays: 1},
+	}
+	if len(byWeekday) != len(expected) {
+		t.Fatalf("Expected %d weekdays, got %d", len(expected), len(byWeekday))
+	}
+	for i, want := range expected {
+		if byWeekday[i] != want {
+			t.Errorf("Expected %v, got %v", want, byWeekday[i])
+		}
+	}
+
+	matrix, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+	matrixTotal, weekdayTotal := 0, 0
+	for i := 0; i < len(developers); i++ {
+		for j := i + 1; j < len(developers); j++ {
+			matrixTotal += matrix.CountByDeveloper(developers[i], developers[j])
+		}
+	}
+	for _, day := range byWeekday {
+		weekdayTotal += day.PairDays
+	}
+	if weekdayTotal != matrixTotal {
+		t.Errorf("Expected weekday totals to add up to the matrix total %d, got %d", matrixTotal, weekdayTotal)
+	}
+}
+
+func TestAttributions(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+	developers := []git.Developer{alice, bob, carol, dave}
+
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	var commits []git.Commit
+	commit := func(author, coAuthor git.Developer, n int) {
+		for i := 0; i < n; i++ {
+			commits = append(commits, git.Commit{Date: day.AddDate(0, 0, len(commits)), Author: author, CoAuthors: []git.Developer{coAuthor}})
+		}
+	}
+	// Bob always drives when pairing with Alice
+	commit(bob, alice, 4)
+	// Carol and Dave take turns
+	commit(carol, dave, 2)
+	commit(dave, carol, 2)
+	// Alice mostly drives with Carol
+	commit(alice, carol, 3)
+	commit(carol, alice, 1)
+
+	matrix, _, _ := pairing.BuildPairMatrix(team.Empty, commits, false)
+	attributions := stats.Attributions(developers, matrix)
+
+	expected := []struct {
+		a, b      git.Developer
+		aAuthored int
+		bAuthored int
+		share     float64
+	}{
+		{bob, alice, 4, 0, 1},
+		{alice, carol, 3, 1, 0.75},
+		{carol, dave, 2, 2, 0.5},
+	}
+	if len(attributions) != len(expected) {
+		t.Fatalf("Expected %d attributions, got %d: %+v", len(expected), len(attributions), attributions)
+	}
+	for i, want := range expected {
+		got := attributions[i]
+		if got.A.CanonicalEmail() != want.a.CanonicalEmail() || got.B.CanonicalEmail() != want.b.CanonicalEmail() {
+			t.Errorf("Expected attribution %d to be %s -> %s, got %s -> %s", i, want.a.DisplayName, want.b.DisplayName, got.A.DisplayName, got.B.DisplayName)
+		}
+		if got.AAuthored != want.aAuthored || got.BAuthored != want.bAuthored {
+			t.Errorf("Expected %d/%d authored, got %d/%d", want.aAuthored, want.bAuthored, got.AAuthored, got.BAuthored)
+		}
+		if got.AuthorShare() != want.share {
+			t.Errorf("Expected author share %.2f, got %.2f", want.share, got.AuthorShare())
+		}
+	}
+}
+
+func TestMetric(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	day := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	commits := []git.Commit{
+		{Date: day, Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day.AddDate(0, 0, 1), Author: alice, CoAuthors: []git.Developer{bob}},
+		{Date: day.AddDate(0, 0, 2), Author: carol},
+	}
+	matrix, _, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"coverage", "0.33"},
+		{"pairs", "1"},
+		{"developers", "3"},
+		{"commits", "3"},
+		{"solo", "0.33"},
+		{"paired", "0.67"},
+		{"mobbed", "0.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stats.Metric(tt.name, developers, matrix, commits, pairing.CountCommitSizes(team.Empty, commits, false, pairing.BuildOptions{}))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s to be %q, got %q", tt.name, tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := stats.Metric("velocity", developers, matrix, commits, pairing.CommitSizes{}); err == nil {
+		t.Error("Expected error for an unknown metric")
+	}
+}
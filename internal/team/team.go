@@ -7,8 +7,11 @@ package team
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -27,8 +30,35 @@ var Empty = Team{
 type Team struct {
 	team                []string
 	developers          map[string]git.Developer
-	emailToName         map[string]string // Maps emails to display names
-	emailToPrimaryEmail map[string]string // Maps all emails to their canonical/primary email
+	emailToName         map[string]string   // Maps emails to display names
+	emailToPrimaryEmail map[string]string   // Maps all emails to their canonical/primary email
+	subTeams            map[string][]string // Maps all emails to the sub-teams they're listed in
+	observers           map[string]bool     // All emails of the developers annotated @observer
+	roles               map[string]string   // Maps all emails to the role they're given, e.g. "Lead"
+}
+
+// observerAnnotation follows a developer in a team file to mark them as an observer
+const observerAnnotation = "@observer"
+
+// cutObserver removes a trailing @observer annotation from a team file line,
+// reporting whether it was there
+func cutObserver(member string) (string, bool) {
+	fields := strings.Fields(member)
+	if len(fields) > 0 && fields[len(fields)-1] == observerAnnotation {
+		return strings.Join(fields[:len(fields)-1], " "), true
+	}
+	return member, false
+}
+
+// cutRole removes a trailing role in square brackets, such as "[Tech Lead]", from
+// after the emails on a team file line, returning the rest and the role
+func cutRole(member string) (string, string) {
+	trimmed := strings.TrimSpace(member)
+	open := strings.LastIndex(trimmed, "[")
+	if !strings.HasSuffix(trimmed, "]") || open < strings.LastIndex(trimmed, ">") {
+		return member, ""
+	}
+	return strings.TrimSpace(trimmed[:open]), strings.TrimSpace(trimmed[open+1 : len(trimmed)-1])
 }
 
 // HasDeveloperByEmail checks if the given email belongs to a developer on the team
@@ -60,6 +90,76 @@ func (t Team) GetDevelopers() []git.Developer {
 	return developers
 }
 
+// SubTeams returns the names of the sub-teams the developer with the given email
+// is listed in, in the order they appear in the team file
+func (t Team) SubTeams(email string) []string {
+	return t.subTeams[email]
+}
+
+// IsObserver reports whether the developer with the given email is an observer:
+// their pairing is counted and shown, but they're never recommended as a pair
+func (t Team) IsObserver(email string) bool {
+	return t.observers[email]
+}
+
+// Observers returns the canonical emails of the observers on the team, sorted
+func (t Team) Observers() []string {
+	var observers []string
+	for _, dev := range t.GetDevelopers() {
+		if t.observers[dev.CanonicalEmail()] {
+			observers = append(observers, dev.CanonicalEmail())
+		}
+	}
+	return observers
+}
+
+// Role returns the role of the developer with the given email, or "" if they
+// haven't been given one
+func (t Team) Role(email string) string {
+	return t.roles[email]
+}
+
+// HasRoles reports whether any developer has been given a role
+func (t Team) HasRoles() bool {
+	for _, role := range t.roles {
+		if role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSubTeams reports whether any developer is listed in a sub-team
+func (t Team) HasSubTeams() bool {
+	return len(t.subTeams) > 0
+}
+
+// UnknownParticipants returns the emails of commit authors and co-authors who aren't
+// on the team but made commits with someone who is, sorted. These are usually typos
+// in the team file or in Co-authored-by trailers. Commits with no team member at all
+// are ignored.
+func (t Team) UnknownParticipants(commits []git.Commit) []string {
+	unknown := make(map[string]struct{})
+	for _, c := range commits {
+		participants := append([]git.Developer{c.Author}, c.CoAuthors...)
+		if !slices.ContainsFunc(participants, func(d git.Developer) bool { return t.HasDeveloperByEmail(d.CanonicalEmail()) }) {
+			continue
+		}
+		for _, d := range participants {
+			if email := d.CanonicalEmail(); email != "" && !t.HasDeveloperByEmail(email) {
+				unknown[email] = struct{}{}
+			}
+		}
+	}
+
+	emails := make([]string, 0, len(unknown))
+	for email := range unknown {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	return emails
+}
+
 // GetTeamMembers returns the original team member strings
 func (t Team) GetTeamMembers() []string {
 	return t.team
@@ -72,7 +172,167 @@ func NewTeamFromFile(filename string, subTeam string) (Team, error) {
 		return Team{}, err
 	}
 
-	return NewTeam(teamMembers)
+	team, err := NewTeam(teamMembers)
+	if err != nil {
+		return Team{}, err
+	}
+
+	team.subTeams, err = ReadSubTeamMemberships(filename)
+	return team, err
+}
+
+// NewTeamFromFiles creates a Team by merging several team files, optionally filtering
+// by sub-team. Later files augment and override earlier ones, as described by Merge.
+// Missing files are skipped; if none of the files exist the error is a not-exist error.
+func NewTeamFromFiles(filenames []string, subTeam string) (Team, error) {
+	var merged Team
+	found := false
+	var notFound error = os.ErrNotExist
+	var subTeamNotFound *SubTeamNotFoundError
+
+	for _, filename := range filenames {
+		team, err := NewTeamFromFile(filename, subTeam)
+		if os.IsNotExist(err) {
+			notFound = err
+			continue
+		}
+		// The sub-team only needs to be in one of the files
+		var missing *SubTeamNotFoundError
+		if errors.As(err, &missing) {
+			if subTeamNotFound == nil {
+				subTeamNotFound = &SubTeamNotFoundError{SubTeam: missing.SubTeam}
+			}
+			for _, available := range missing.Available {
+				if !slices.Contains(subTeamNotFound.Available, available) {
+					subTeamNotFound.Available = append(subTeamNotFound.Available, available)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return Team{}, err
+		}
+		if found {
+			merged = Merge(merged, team)
+		} else {
+			merged = team
+		}
+		found = true
+	}
+
+	if !found && subTeamNotFound != nil {
+		return Team{}, subTeamNotFound
+	}
+	if !found {
+		return Team{}, notFound
+	}
+	return merged, nil
+}
+
+// SubTeamSize is a sub-team of the team files, or the main team, and how many
+// developers it has
+type SubTeamSize struct {
+	Name    string // The sub-team, or "" for the main team
+	Members int
+}
+
+// ListSubTeams returns the main team, then each sub-team in the team files in the
+// order they're first found, with the number of developers in each when it's
+// selected with NewTeamFromFiles. Missing files are skipped; if none of the files
+// exist the error is a not-exist error.
+func ListSubTeams(filenames []string) ([]SubTeamSize, error) {
+	names := []string{""}
+	found := false
+	for _, filename := range filenames {
+		_, sections, err := readTeamEntries(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, section := range sections {
+			if !slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, section) }) {
+				names = append(names, section)
+			}
+		}
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+
+	sizes := make([]SubTeamSize, 0, len(names))
+	for _, name := range names {
+		team, err := NewTeamFromFiles(filenames, name)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, SubTeamSize{Name: name, Members: len(team.GetDevelopers())})
+	}
+	return sizes, nil
+}
+
+// Merge combines two teams, with the developers in override augmenting those in base.
+// A developer in override replaces any developer in base sharing one of their email
+// addresses: the name and primary email come from override, and the base developer's
+// other addresses are kept as aliases. Sub-team memberships from both are kept. A
+// developer in override is an observer, and has a role, only if override says so.
+func Merge(base, override Team) Team {
+	replaced := make(map[string]bool) // Canonical emails of the base developers that were overridden
+	var overrides []git.Developer
+	for _, dev := range override.GetDevelopers() {
+		emails := slices.Clone(dev.EmailAddresses)
+		for _, email := range dev.EmailAddresses {
+			primary, ok := base.emailToPrimaryEmail[email]
+			if !ok || replaced[primary] {
+				continue
+			}
+			replaced[primary] = true
+			for _, alias := range base.developers[primary].EmailAddresses {
+				if !slices.Contains(emails, alias) && !override.HasDeveloperByEmail(alias) {
+					emails = append(emails, alias)
+				}
+			}
+		}
+		dev.EmailAddresses = emails
+		overrides = append(overrides, dev)
+	}
+
+	var developers []git.Developer
+	for _, dev := range base.GetDevelopers() {
+		if !replaced[dev.CanonicalEmail()] {
+			developers = append(developers, dev)
+		}
+	}
+	merged := NewTeamFromDevelopers(append(developers, overrides...))
+
+	merged.observers = make(map[string]bool)
+	merged.roles = make(map[string]string)
+	for _, dev := range developers {
+		for _, email := range dev.EmailAddresses {
+			merged.observers[email] = base.observers[dev.CanonicalEmail()]
+			merged.roles[email] = base.roles[dev.CanonicalEmail()]
+		}
+	}
+	for _, dev := range overrides {
+		for _, email := range dev.EmailAddresses {
+			merged.observers[email] = override.observers[dev.CanonicalEmail()]
+			merged.roles[email] = override.roles[dev.CanonicalEmail()]
+		}
+	}
+
+	merged.subTeams = make(map[string][]string)
+	for _, memberships := range []map[string][]string{base.subTeams, override.subTeams} {
+		for email, subTeams := range memberships {
+			for _, subTeam := range subTeams {
+				if !slices.Contains(merged.subTeams[email], subTeam) {
+					merged.subTeams[email] = append(merged.subTeams[email], subTeam)
+				}
+			}
+		}
+	}
+	return merged
 }
 
 // NewTeamFromDevelopers creates a Team from a slice of git.Developer objects
@@ -109,17 +369,27 @@ func NewTeamFromDevelopers(developers []git.Developer) Team {
 	}
 }
 
-// NewTeam creates a Team from a list of team member strings
+// NewTeam creates a Team from a list of team member strings. A member followed by
+// @observer is an observer, and one followed by a name in square brackets, such as
+// "[Lead]", has that role. A role comes before any @observer.
 func NewTeam(teamMembers []string) (Team, error) {
 	developers := make(map[string]git.Developer)
 	emailToName := make(map[string]string)
 	emailToPrimaryEmail := make(map[string]string)
+	observers := make(map[string]bool)
+	roles := make(map[string]string)
 
 	for _, member := range teamMembers {
+		member, observer := cutObserver(member)
+		member, role := cutRole(member)
 		developer := git.NewDeveloper(member)
 		if len(developer.EmailAddresses) == 0 {
 			continue // Skip invalid entries
 		}
+		for _, email := range developer.EmailAddresses {
+			observers[email] = observer
+			roles[email] = role
+		}
 
 		// Associate all emails with this name and primary email
 		for _, email := range developer.EmailAddresses {
@@ -135,20 +405,110 @@ func NewTeam(teamMembers []string) (Team, error) {
 		developers:          developers,
 		emailToName:         emailToName,
 		emailToPrimaryEmail: emailToPrimaryEmail,
+		observers:           observers,
+		roles:               roles,
 	}, nil
 }
 
-// ReadTeamFile reads and parses a team file, optionally filtering by sub-team
+// SubTeamNotFoundError is returned when the requested sub-team has no section in
+// the team file. A section that exists but lists nobody is not an error.
+type SubTeamNotFoundError struct {
+	SubTeam   string
+	Available []string // The sub-teams the file does have, in the order they appear
+}
+
+func (e *SubTeamNotFoundError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("sub-team %q not found: the team file has no sub-teams", e.SubTeam)
+	}
+	return fmt.Sprintf("sub-team %q not found (available: %s)", e.SubTeam, strings.Join(e.Available, ", "))
+}
+
+// ReadSubTeamMemberships reads a team file and returns, for every email listed in a
+// sub-team section, the names of the sub-teams it is listed in
+func ReadSubTeamMemberships(filename string) (map[string][]string, error) {
+	entries, _, err := readTeamEntries(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.section == "" {
+			continue
+		}
+		for _, email := range git.NewDeveloper(entry.line).EmailAddresses {
+			if !slices.Contains(memberships[email], entry.section) {
+				memberships[email] = append(memberships[email], entry.section)
+			}
+		}
+	}
+
+	return memberships, nil
+}
+
+// ReadTeamFile reads and parses a team file, optionally filtering by sub-team.
+// Sub-team names are matched ignoring case and surrounding whitespace. If the file
+// has no section for the sub-team the error is a *SubTeamNotFoundError.
 func ReadTeamFile(filename string, subTeam string) ([]string, error) {
+	entries, sections, err := readTeamEntries(filename)
+	if err != nil {
+		return nil, err
+	}
+	if subTeam = strings.TrimSpace(subTeam); subTeam != "" && !slices.ContainsFunc(sections, func(section string) bool { return strings.EqualFold(section, subTeam) }) {
+		return nil, &SubTeamNotFoundError{SubTeam: subTeam, Available: sections}
+	}
+
+	// If no sub-team specified, include all lines not in sections
+	// If sub-team specified, only include lines from that section
+	var teamMembers []string
+	for _, entry := range entries {
+		if strings.EqualFold(entry.section, subTeam) {
+			teamMembers = append(teamMembers, entry.line)
+		}
+	}
+
+	return teamMembers, nil
+}
+
+// teamEntry is a developer line from a team file, with the section it's listed in
+type teamEntry struct {
+	section string // The sub-team, or "" for the main team
+	line    string
+}
+
+// readTeamEntries reads the developer lines from a team file, expanding include
+// directives, along with the names of all the sections, including empty ones
+func readTeamEntries(filename string) ([]teamEntry, []string, error) {
+	var sections []string
+	entries, err := readTeamEntriesIncluding(filename, "", nil, &sections)
+	return entries, sections, err
+}
+
+// readTeamEntriesIncluding reads the developer lines from a team file, placing lines
+// outside any section in the given section. A line of the form "include other.team"
+// is replaced with the entries of that file, resolved relative to the including file,
+// so that its sub-teams merge with the including file's. The chain of files being
+// included is used to report include cycles. The names of the sections found are
+// added to sections.
+func readTeamEntriesIncluding(filename string, section string, chain []string, sections *[]string) ([]teamEntry, error) {
+	absolute, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, absolute) {
+		return nil, fmt.Errorf("include cycle in team files: %s", strings.Join(append(chain, absolute), " -> "))
+	}
+	chain = append(slices.Clone(chain), absolute)
+
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	var teamMembers []string
-	var currentSection string
-	var inTargetSection bool
+	var entries []teamEntry
+	currentSection := section
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
@@ -161,21 +521,29 @@ func ReadTeamFile(filename string, subTeam string) ([]string, error) {
 
 		// Check if this is a section header [section_name]
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentSection = strings.Trim(line, "[]")
-			inTargetSection = (subTeam == "" || currentSection == subTeam)
+			currentSection = strings.TrimSpace(strings.Trim(line, "[]"))
+			if !slices.Contains(*sections, currentSection) {
+				*sections = append(*sections, currentSection)
+			}
 			continue
 		}
 
-		// If no sub-team specified, include all lines not in sections
-		// If sub-team specified, only include lines from that section
-		if subTeam == "" {
-			if currentSection == "" {
-				teamMembers = append(teamMembers, line)
+		if included, ok := strings.CutPrefix(line, "include "); ok && !strings.Contains(line, "<") {
+			includePath := strings.TrimSpace(included)
+			if !filepath.IsAbs(includePath) {
+				includePath = filepath.Join(filepath.Dir(filename), includePath)
+			}
+			includedEntries, err := readTeamEntriesIncluding(includePath, currentSection, chain, sections)
+			if err != nil {
+				// Not wrapped, so a missing include isn't mistaken for a missing team file
+				return nil, fmt.Errorf("include %s: %v", includePath, err)
 			}
-		} else if inTargetSection {
-			teamMembers = append(teamMembers, line)
+			entries = append(entries, includedEntries...)
+			continue
 		}
+
+		entries = append(entries, teamEntry{section: currentSection, line: line})
 	}
 
-	return teamMembers, scanner.Err()
+	return entries, scanner.Err()
 }
@@ -1,8 +1,12 @@
 package team_test
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gypsydave5/pairstair/internal/git"
@@ -188,15 +192,6 @@ Grace Gray <grace@example.com>
 				"dave@example.com",
 			},
 		},
-		{
-			name:        "non-existent sub-team",
-			subTeam:     "nonexistent",
-			expectedDevs: []string{},
-			notExpected: []string{
-				"alice@example.com",
-				"dave@example.com",
-			},
-		},
 	}
 
 	for _, tt := range tests {
@@ -223,6 +218,79 @@ Grace Gray <grace@example.com>
 	}
 }
 
+func TestNewTeamFromFileMissingSubTeam(t *testing.T) {
+	content := `Alice Lead <alice@example.com>
+
+[frontend]
+Carol Frontend <carol@example.com>
+
+[backend]
+# Nobody yet
+`
+	teamFile := filepath.Join(t.TempDir(), ".team")
+	if err := ioutil.WriteFile(teamFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	t.Run("a missing section is an error listing the sub-teams", func(t *testing.T) {
+		_, err := team.NewTeamFromFile(teamFile, "nonexistent")
+		var notFound *team.SubTeamNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Expected a SubTeamNotFoundError, got %v", err)
+		}
+		if strings.Join(notFound.Available, ",") != "frontend,backend" {
+			t.Errorf("Expected available sub-teams frontend and backend, got %v", notFound.Available)
+		}
+		if !strings.Contains(err.Error(), "available: frontend, backend") {
+			t.Errorf("Expected the error to list the available sub-teams, got %q", err.Error())
+		}
+	})
+
+	t.Run("an empty section is not an error", func(t *testing.T) {
+		teamObj, err := team.NewTeamFromFile(teamFile, "backend")
+		if err != nil {
+			t.Fatalf("Expected no error for an empty sub-team, got %v", err)
+		}
+		if developers := teamObj.GetDevelopers(); len(developers) != 0 {
+			t.Errorf("Expected no developers, got %v", developers)
+		}
+	})
+
+	t.Run("the sub-team only needs to be in one of several files", func(t *testing.T) {
+		otherFile := filepath.Join(t.TempDir(), "team")
+		if err := ioutil.WriteFile(otherFile, []byte("[devops]\nGrace Ops <grace@example.com>\n"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		teamObj, err := team.NewTeamFromFiles([]string{otherFile, teamFile}, "devops")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !teamObj.HasDeveloperByEmail("grace@example.com") {
+			t.Error("Expected Grace to be in the devops sub-team")
+		}
+
+		_, err = team.NewTeamFromFiles([]string{otherFile, teamFile}, "qa")
+		var notFound *team.SubTeamNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Expected a SubTeamNotFoundError, got %v", err)
+		}
+		if strings.Join(notFound.Available, ",") != "devops,frontend,backend" {
+			t.Errorf("Expected the sub-teams from every file, got %v", notFound.Available)
+		}
+	})
+
+	t.Run("without sub-teams", func(t *testing.T) {
+		plainFile := filepath.Join(t.TempDir(), ".team")
+		if err := ioutil.WriteFile(plainFile, []byte("Alice Lead <alice@example.com>\n"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		_, err := team.NewTeamFromFile(plainFile, "frontend")
+		if err == nil || !strings.Contains(err.Error(), "no sub-teams") {
+			t.Errorf("Expected an error saying there are no sub-teams, got %v", err)
+		}
+	})
+}
+
 func TestTeamFileWithMultipleEmailsPerDeveloper(t *testing.T) {
 	content := `Alice Consolidated <alice@work.com>,<alice@personal.com>,<alice@old.com>
 Bob Single <bob@example.com>
@@ -326,6 +394,436 @@ Dave Backend <dave@example.com>
 	}
 }
 
+func TestNewTeamFromFileSubTeamIgnoresCase(t *testing.T) {
+	content := `Alice Lead <alice@example.com>
+
+[frontend]
+Carol Frontend <carol@example.com>
+
+[ Backend ]
+Eve Backend <eve@example.com>
+`
+	teamFile := filepath.Join(t.TempDir(), ".team")
+	if err := ioutil.WriteFile(teamFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	tests := []struct {
+		subTeam  string
+		expected string
+	}{
+		{"frontend", "carol@example.com"},
+		{"Frontend", "carol@example.com"},
+		{"FRONTEND", "carol@example.com"},
+		{" frontend ", "carol@example.com"},
+		{"backend", "eve@example.com"},
+		{"BACKEND", "eve@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subTeam, func(t *testing.T) {
+			teamObj, err := team.NewTeamFromFile(teamFile, tt.subTeam)
+			if err != nil {
+				t.Fatalf("NewTeamFromFile() failed: %v", err)
+			}
+			developers := teamObj.GetDevelopers()
+			if len(developers) != 1 || developers[0].CanonicalEmail() != tt.expected {
+				t.Errorf("Expected only %s, got %v", tt.expected, developers)
+			}
+			if teamObj.HasDeveloperByEmail("alice@example.com") {
+				t.Error("Expected the main team to be left out")
+			}
+		})
+	}
+
+	// The section's own casing is kept for display
+	teamObj, err := team.NewTeamFromFile(teamFile, "backend")
+	if err != nil {
+		t.Fatalf("NewTeamFromFile() failed: %v", err)
+	}
+	if got := teamObj.SubTeams("eve@example.com"); len(got) != 1 || got[0] != "Backend" {
+		t.Errorf("Expected sub-team tag %q, got %v", "Backend", got)
+	}
+}
+
+func TestTeamSubTeams(t *testing.T) {
+	content := `Alice Lead <alice@example.com>
+Bob Fullstack <bob@example.com>
+
+[frontend]
+Bob Fullstack <bob@example.com>,<bob@personal.com>
+Carol Frontend <carol@example.com>
+
+[backend]
+Bob Fullstack <bob@example.com>
+`
+
+	teamFile := filepath.Join(t.TempDir(), ".team")
+	if err := ioutil.WriteFile(teamFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	teamObj, err := team.NewTeamFromFile(teamFile, "")
+	if err != nil {
+		t.Fatalf("NewTeamFromFile() failed: %v", err)
+	}
+
+	if !teamObj.HasSubTeams() {
+		t.Error("Expected team to have sub-teams")
+	}
+
+	tests := []struct {
+		email    string
+		expected []string
+	}{
+		{"alice@example.com", nil},
+		{"bob@example.com", []string{"frontend", "backend"}},
+		{"bob@personal.com", []string{"frontend"}},
+		{"carol@example.com", []string{"frontend"}},
+	}
+	for _, tt := range tests {
+		if got := teamObj.SubTeams(tt.email); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("SubTeams(%q) = %v, expected %v", tt.email, got, tt.expected)
+		}
+	}
+
+	if team.Empty.HasSubTeams() {
+		t.Error("Expected the empty team to have no sub-teams")
+	}
+}
+
+func TestListSubTeams(t *testing.T) {
+	content := `Alice Lead <alice@example.com>
+Bob Fullstack <bob@example.com>
+
+[frontend]
+Bob Fullstack <bob@example.com>,<bob@personal.com>
+Carol Frontend <carol@example.com>
+Dan Frontend <dan@example.com>
+
+[backend]
+Bob Fullstack <bob@example.com>
+
+[platform]
+`
+	dir := t.TempDir()
+	teamFile := filepath.Join(dir, ".team")
+	if err := ioutil.WriteFile(teamFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	homeFile := filepath.Join(dir, "team")
+	if err := ioutil.WriteFile(homeFile, []byte("[Backend]\nErin Backend <erin@example.com>\n\n[devops]\nGrace Ops <grace@example.com>\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	subTeams, err := team.ListSubTeams([]string{filepath.Join(dir, "missing"), teamFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got []string
+	for _, s := range subTeams {
+		got = append(got, fmt.Sprintf("%s=%d", s.Name, s.Members))
+	}
+	if want := "=2,frontend=3,backend=1,platform=0"; strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+
+	t.Run("sub-teams are merged across files, ignoring case", func(t *testing.T) {
+		subTeams, err := team.ListSubTeams([]string{homeFile, teamFile})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var got []string
+		for _, s := range subTeams {
+			got = append(got, fmt.Sprintf("%s=%d", s.Name, s.Members))
+		}
+		if want := "=2,Backend=2,devops=1,frontend=3,platform=0"; strings.Join(got, ",") != want {
+			t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+		}
+	})
+
+	t.Run("no team files", func(t *testing.T) {
+		if _, err := team.ListSubTeams([]string{filepath.Join(dir, "missing")}); !os.IsNotExist(err) {
+			t.Errorf("Expected a not-exist error, got %v", err)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	shared, _ := team.NewTeam([]string{
+		"Alice Smith <alice@example.com>",
+		"Bob Jones <bob@example.com>,<bob@old.com>",
+		"Carol White <carol@example.com>",
+	})
+	local, _ := team.NewTeam([]string{
+		"Robert Jones <bob@company.com>,<bob@example.com>",
+		"Dave Brown <dave@example.com>",
+	})
+
+	merged := team.Merge(shared, local)
+	emailToName, emailToPrimary := merged.GetEmailMappings()
+
+	tests := []struct {
+		name        string
+		email       string
+		wantName    string
+		wantPrimary string
+	}{
+		{"shared developer is kept", "alice@example.com", "Alice Smith", "alice@example.com"},
+		{"local developer is added", "dave@example.com", "Dave Brown", "dave@example.com"},
+		{"local entry overrides a shared developer's name and primary email", "bob@example.com", "Robert Jones", "bob@company.com"},
+		{"shared aliases of an overridden developer are kept", "bob@old.com", "Robert Jones", "bob@company.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := emailToName[tt.email]; got != tt.wantName {
+				t.Errorf("Expected name %q, got %q", tt.wantName, got)
+			}
+			if got := emailToPrimary[tt.email]; got != tt.wantPrimary {
+				t.Errorf("Expected primary email %q, got %q", tt.wantPrimary, got)
+			}
+		})
+	}
+
+	if developers := merged.GetDevelopers(); len(developers) != 4 {
+		t.Errorf("Expected 4 developers, got %d: %v", len(developers), developers)
+	}
+}
+
+func TestTeamObservers(t *testing.T) {
+	teamFile := filepath.Join(t.TempDir(), ".team")
+	content := `Alice Smith <alice@example.com>
+Mo Manager <mo@example.com>,<mo@old.com> @observer
+
+[frontend]
+Mo Manager <mo@example.com> @observer
+`
+	if err := ioutil.WriteFile(teamFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	teamObj, err := team.NewTeamFromFile(teamFile, "")
+	if err != nil {
+		t.Fatalf("NewTeamFromFile() failed: %v", err)
+	}
+	if developers := teamObj.GetDevelopers(); len(developers) != 2 || developers[1].DisplayName != "Mo Manager" {
+		t.Fatalf("Expected observers to stay on the team, named without the annotation, got %v", developers)
+	}
+	for email, want := range map[string]bool{"alice@example.com": false, "mo@example.com": true, "mo@old.com": true, "nobody@example.com": false} {
+		if got := teamObj.IsObserver(email); got != want {
+			t.Errorf("IsObserver(%q) = %v, want %v", email, got, want)
+		}
+	}
+	if got := teamObj.Observers(); strings.Join(got, ",") != "mo@example.com" {
+		t.Errorf("Expected mo@example.com to be the only observer, got %v", got)
+	}
+	if got := teamObj.SubTeams("mo@example.com"); strings.Join(got, ",") != "frontend" {
+		t.Errorf("Expected an observer's sub-teams to be read, got %v", got)
+	}
+
+	// The overriding team decides who is an observer
+	shared, _ := team.NewTeam([]string{"Alice Smith <alice@example.com> @observer", "Mo Manager <mo@example.com> @observer"})
+	local, _ := team.NewTeam([]string{"Alice Smith <alice@example.com>"})
+	merged := team.Merge(shared, local)
+	if merged.IsObserver("alice@example.com") || !merged.IsObserver("mo@example.com") {
+		t.Errorf("Expected only Mo to stay an observer, got %v", merged.Observers())
+	}
+}
+
+func TestTeamRoles(t *testing.T) {
+	teamObj, err := team.NewTeam([]string{
+		"Alice Smith <alice@example.com> [Tech Lead]",
+		"Bob Jones <bob@example.com>,<bob@old.com> [Developer] @observer",
+		"Carol Davis <carol@example.com>",
+	})
+	if err != nil {
+		t.Fatalf("NewTeam() failed: %v", err)
+	}
+	if developers := teamObj.GetDevelopers(); developers[0].DisplayName != "Alice Smith" || developers[1].DisplayName != "Bob Jones" {
+		t.Fatalf("Expected names without the roles, got %v", developers)
+	}
+	for email, want := range map[string]string{"alice@example.com": "Tech Lead", "bob@old.com": "Developer", "carol@example.com": "", "nobody@example.com": ""} {
+		if got := teamObj.Role(email); got != want {
+			t.Errorf("Role(%q) = %q, want %q", email, got, want)
+		}
+	}
+	if !teamObj.HasRoles() || !teamObj.IsObserver("bob@example.com") {
+		t.Errorf("Expected roles, and Bob to still be an observer")
+	}
+
+	without, _ := team.NewTeam([]string{"Carol Davis <carol@example.com>"})
+	if without.HasRoles() {
+		t.Errorf("Expected no roles in a team without any")
+	}
+
+	// The overriding team decides each developer's role
+	local, _ := team.NewTeam([]string{"Alice Smith <alice@example.com>"})
+	merged := team.Merge(teamObj, local)
+	if merged.Role("alice@example.com") != "" || merged.Role("bob@example.com") != "Developer" {
+		t.Errorf("Expected only Bob to keep a role, got %q and %q", merged.Role("alice@example.com"), merged.Role("bob@example.com"))
+	}
+}
+
+func TestNewTeamFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	sharedFile := filepath.Join(dir, "shared")
+	localFile := filepath.Join(dir, ".team")
+	missingFile := filepath.Join(dir, "missing")
+
+	shared := "Alice Smith <alice@example.com>\n\n[frontend]\nAlice Smith <alice@example.com>\n"
+	local := "Bob Jones <bob@example.com>\n\n[backend]\nAlice Smith <alice@example.com>\n"
+	if err := ioutil.WriteFile(sharedFile, []byte(shared), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := ioutil.WriteFile(localFile, []byte(local), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	teamObj, err := team.NewTeamFromFiles([]string{missingFile, sharedFile, localFile}, "")
+	if err != nil {
+		t.Fatalf("NewTeamFromFiles() failed: %v", err)
+	}
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if !teamObj.HasDeveloperByEmail(email) {
+			t.Errorf("Expected developer %q to be in team", email)
+		}
+	}
+	if got := teamObj.SubTeams("alice@example.com"); strings.Join(got, ",") != "frontend,backend" {
+		t.Errorf("Expected sub-teams from both files, got %v", got)
+	}
+
+	if _, err := team.NewTeamFromFiles([]string{missingFile}, ""); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error when no team file exists, got %v", err)
+	}
+}
+
+func TestTeamFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	base := `Alice Lead <alice@example.com>
+
+[frontend]
+Carol Frontend <carol@example.com>
+`
+	project := `include shared/base.team
+Bob Project <bob@example.com>
+
+[frontend]
+Dave UI <dave@example.com>
+
+[backend]
+include shared/backend.team
+`
+	backend := `Eve Backend <eve@example.com>
+`
+	if err := os.MkdirAll(filepath.Join(dir, "shared"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for name, content := range map[string]string{
+		"shared/base.team":    base,
+		"shared/backend.team": backend,
+		".team":               project,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+	teamFile := filepath.Join(dir, ".team")
+
+	tests := []struct {
+		subTeam  string
+		expected []string
+	}{
+		{"", []string{"alice@example.com", "bob@example.com"}},
+		{"frontend", []string{"carol@example.com", "dave@example.com"}},
+		{"backend", []string{"eve@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run("sub-team "+tt.subTeam, func(t *testing.T) {
+			teamObj, err := team.NewTeamFromFile(teamFile, tt.subTeam)
+			if err != nil {
+				t.Fatalf("NewTeamFromFile() failed: %v", err)
+			}
+			var emails []string
+			for _, dev := range teamObj.GetDevelopers() {
+				emails = append(emails, dev.CanonicalEmail())
+			}
+			if strings.Join(emails, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected developers %v, got %v", tt.expected, emails)
+			}
+		})
+	}
+}
+
+func TestTeamFileIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.team": "Alice <alice@example.com>\ninclude b.team\n",
+		"b.team": "Bob <bob@example.com>\ninclude a.team\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	_, err := team.NewTeamFromFile(filepath.Join(dir, "a.team"), "")
+	if err == nil {
+		t.Fatal("Expected an error for an include cycle, got nil")
+	}
+	if !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected an include cycle error, got %q", err.Error())
+	}
+}
+
+func TestTeamFileMissingInclude(t *testing.T) {
+	dir := t.TempDir()
+	teamFile := filepath.Join(dir, ".team")
+	if err := ioutil.WriteFile(teamFile, []byte("include missing.team\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	_, err := team.NewTeamFromFiles([]string{filepath.Join(dir, "absent"), teamFile}, "")
+	if err == nil {
+		t.Fatal("Expected an error for a missing include, got nil")
+	}
+	if os.IsNotExist(err) {
+		t.Errorf("Expected a missing include not to look like a missing team file, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "include "+filepath.Join(dir, "missing.team")) {
+		t.Errorf("Expected the error to name the missing include, got %q", err.Error())
+	}
+}
+
+func TestUnknownParticipants(t *testing.T) {
+	teamObj, _ := team.NewTeam([]string{
+		"Alice Smith <alice@example.com>",
+		"Bob Jones <bob@example.com>,<bob@company.com>",
+	})
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@company.com>")
+	typo := git.NewDeveloper("Bob Jones <bob@exmaple.com>")
+	carol := git.NewDeveloper("Carol Davis <carol@example.com>")
+	dave := git.NewDeveloper("Dave Brown <dave@example.com>")
+
+	commits := []git.Commit{
+		{Author: alice, CoAuthors: []git.Developer{bob}},
+		{Author: alice, CoAuthors: []git.Developer{typo}},
+		{Author: carol, CoAuthors: []git.Developer{bob}},
+		// Nobody on the team: not reported
+		{Author: dave, CoAuthors: []git.Developer{carol}},
+		{Author: dave},
+	}
+
+	got := teamObj.UnknownParticipants(commits)
+	expected := []string{"bob@exmaple.com", "carol@example.com"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected unknown participants %v, got %v", expected, got)
+	}
+
+	if got := teamObj.UnknownParticipants(commits[:1]); len(got) != 0 {
+		t.Errorf("Expected no unknown participants, got %v", got)
+	}
+}
+
 func TestReadTeamFileErrorHandling(t *testing.T) {
 	// Test non-existent file
 	_, err := team.NewTeamFromFile("/nonexistent/path/.team", "")
@@ -0,0 +1,30 @@
+// Package webhook provides posting of rendered pairing results to HTTP
+// webhooks, such as Slack incoming webhooks, for scheduled publishing.
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Timeout is how long to wait for the webhook to respond
+const Timeout = 5 * time.Second
+
+// Post sends the JSON payload to the URL and returns the HTTP status.
+// A non-2xx response is reported as an error alongside the status.
+func Post(url string, payload []byte) (string, error) {
+	client := &http.Client{Timeout: Timeout}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.Status, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return resp.Status, nil
+}
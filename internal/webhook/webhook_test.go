@@ -0,0 +1,66 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gypsydave5/pairstair/internal/webhook"
+)
+
+func TestPost(t *testing.T) {
+	var gotBody, gotContentType, gotMethod string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotContentType = r.Header.Get("Content-Type")
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := `{"text":"*Pairing Recommendations*"}`
+	status, err := webhook.Post(server.URL, []byte(payload))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+
+	if status != "200 OK" {
+		t.Errorf("Expected status 200 OK, got %q", status)
+	}
+	if gotMethod != http.MethodPost {
+		t.Errorf("Expected POST, got %s", gotMethod)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("Expected application/json content type, got %q", gotContentType)
+	}
+	if gotBody != payload {
+		t.Errorf("Expected payload %q, got %q", payload, gotBody)
+	}
+}
+
+func TestPost_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	status, err := webhook.Post(server.URL, []byte(`{}`))
+	if err == nil {
+		t.Error("Expected error for non-2xx response")
+	}
+	if status != "403 Forbidden" {
+		t.Errorf("Expected status 403 Forbidden, got %q", status)
+	}
+}
+
+func TestPost_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	if _, err := webhook.Post(url, []byte(`{}`)); err == nil {
+		t.Error("Expected error for unreachable webhook")
+	}
+}
@@ -1,25 +1,76 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/identity"
+	"github.com/gypsydave5/pairstair/internal/lastrun"
 	"github.com/gypsydave5/pairstair/internal/output"
 	"github.com/gypsydave5/pairstair/internal/pairing"
 	"github.com/gypsydave5/pairstair/internal/recommend"
+	"github.com/gypsydave5/pairstair/internal/stats"
 	"github.com/gypsydave5/pairstair/internal/team"
 	"github.com/gypsydave5/pairstair/internal/update"
+	"github.com/gypsydave5/pairstair/internal/webhook"
 )
 
 // Version is the fallback version, overridden by build info when available
 const Version = "0.6.0-dev"
 
+// Exit statuses, so that scripts can tell failures apart. Success is 0.
+const (
+	// exitError is the exit status for any error without a status of its own
+	exitError = 1
+	// exitUsage is the exit status for an unknown subcommand or invalid flags
+	exitUsage = 2
+	// exitNotARepo is the exit status when pairstair isn't run in a git repository
+	exitNotARepo = 3
+	// exitThreshold is the exit status when the analysis ran but failed a
+	// threshold, such as coverage dropping by more than -max-coverage-drop or
+	// finding no pairing with -fail-on-empty
+	exitThreshold = 4
+	// exitGitNotFound is the exit status when git isn't installed
+	exitGitNotFound = 5
+)
+
+// Subcommands. With no subcommand pairstair shows the matrix and recommendations.
+const (
+	commandMatrix       = "matrix"
+	commandRecommend    = "recommend"
+	commandStats        = "stats"
+	commandValidateTeam = "validate-team"
+	commandInitTeam     = "init-team"
+	commandInstallHook  = "install-hook"
+)
+
+// commands lists the subcommands in the order they're described in errors
+var commands = []string{commandMatrix, commandRecommend, commandStats, commandValidateTeam, commandInitTeam, commandInstallHook}
+
 func main() {
-	config := parseFlags()
+	command, args, err := splitCommand(os.Args[1:])
+	exitWithStatusOnError(err, "Invalid command", exitUsage)
+	if command == commandInitTeam {
+		exitOnError(runInitTeam(args), "Error initializing team")
+		return
+	}
+	if command == commandInstallHook {
+		exitOnError(runInstallHook(args, os.Stdin), "Error installing hook")
+		return
+	}
+
+	config := parseFlagSet(flag.CommandLine, args)
+	config.Command = command
+	exitWithStatusOnError(config.Validate(), "Invalid options", exitUsage)
 
 	// Check for updates (silent failure, no caching)
 	if updateMessage := update.CheckForUpdate(getVersion()); updateMessage != "" {
@@ -32,11 +83,32 @@ func main() {
 		return
 	}
 
-	wd, err := os.Getwd()
+	wd, err := repoDir(config.Repo)
 	exitOnError(err, "Error getting working directory")
 
-	teamPath := filepath.Join(wd, ".team")
-	teamObj, err := team.NewTeamFromFile(teamPath, config.Team)
+	if config.ShowConfig {
+		exitOnError(printConfig(os.Stdout, config, wd, time.Now()), "Error showing configuration")
+		return
+	}
+
+	if config.ListTeams {
+		exitOnError(printTeams(os.Stdout, wd), "Error listing teams")
+		return
+	}
+
+	runStarted := time.Now()
+	runLog := &runRecord{Window: config.Window, Team: config.Team}
+	if config.LogFile != "" {
+		// Deferred so the record has everything found during the run. Deferred
+		// calls don't run on os.Exit, so exit writes the record of a failed run.
+		defer appendRunLog(config.LogFile, runLog, runStarted)
+		exitHook = func(status int, reason string) {
+			runLog.Status, runLog.Error = status, reason
+			appendRunLog(config.LogFile, runLog, runStarted)
+		}
+	}
+
+	teamObj, err := team.NewTeamFromFiles(teamFiles(wd), config.Team)
 	useTeam := true
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -46,59 +118,1213 @@ func main() {
 		}
 	}
 
-	commits, err := git.GetCommitsSince(config.Window)
+	progress := newProgress(os.Stderr, config.Progress)
+	if config.dateRange() && config.WindowSet {
+		note := "-window is ignored with -since and -until"
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	}
+	if config.Window == git.AllTime && config.Range == "" && config.LastCommits == 0 && !config.dateRange() {
+		note := "-window all reads the repository's whole history, which can be slow in a big repository; -first-parent reads less"
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	}
+	progress.stage("Fetching commits…")
+	commits, err := getCommits(config, wd, progress)
+	if errors.Is(err, git.ErrNotARepo) {
+		exit(exitNotARepo, fmt.Sprintf("%s is not in a git repository: cd into a repository, or pass -repo <path>", wd))
+	}
+	if errors.Is(err, git.ErrGitNotFound) {
+		exit(exitGitNotFound, "git is required but was not found on your PATH: install git, or add it to your PATH")
+	}
 	exitOnError(err, "Error getting git commits")
+	progress.parsed(len(commits))
+	githubUsers, err := identity.ParseGitHubUsers(config.GitHubUsers)
+	exitOnError(err, "Error parsing GitHub users")
+	runLog.Warnings = append(runLog.Warnings, warnInvalidEmails(git.InvalidCoAuthorEmails(commits), config.StrictEmails)...)
+	commits = cleanCommits(config, commits, githubUsers)
+	if config.DetectSelfPairs {
+		runLog.Warnings = append(runLog.Warnings, warnSelfPairs(teamObj, commits, useTeam)...)
+	}
+	if config.Command == commandValidateTeam {
+		exitOnError(validateTeam(wd, commits, useTeam), "Team validation failed")
+		return
+	}
+	if config.StrictTeam {
+		exitOnError(checkStrictTeam(wd, commits, useTeam), "Strict team check failed")
+	}
+	if config.SinceLastRun && !config.DumpCommits {
+		// Deferred so it only runs when we finish without exiting on an error
+		defer recordLastRun(wd, runStarted)
+	}
+
+	mobWeight, err := pairing.ParseMobWeight(config.MobWeight)
+	exitOnError(err, "Error parsing mob weight")
+	hours, err := pairing.ParseHours(config.Hours)
+	exitOnError(err, "Error parsing hours")
+	labels, err := pairing.ParseLabelStyle(config.Labels)
+	exitOnError(err, "Error parsing labels")
+	buildOptions := pairing.BuildOptions{MobWeight: mobWeight, ExcludeToday: config.ExcludeToday, Now: runStarted, PairsOnly: config.PairsOnly, MobsOnly: config.MobsOnly, Hours: hours, Labels: labels, Only: splitList(config.Only)}
+	if config.FrequentNames {
+		buildOptions.Names = identity.MostFrequentNames(commits)
+	}
+	if config.DumpCommits {
+		exitOnError(output.RenderCommitsToWriter(os.Stdout, buildOptions.Filter(commits)), "Error dumping commits")
+		return
+	}
+	progress.stage("Building matrix…")
+	matrix, pairRecency, developers := pairing.BuildPairMatrixWithOptions(teamObj, commits, useTeam, buildOptions)
+	if config.RecencyWindow != "" {
+		progress.stage("Fetching commits for the recency window…")
+		recencyCommits, err := getRecencyCommits(config, wd, progress)
+		exitOnError(err, "Error getting git commits for the recency window")
+		_, pairRecency, _ = pairing.BuildPairMatrixWithOptions(teamObj, cleanCommits(config, recencyCommits, githubUsers), useTeam, buildOptions)
+	}
+	runLog.Developers = len(developers)
+	runLog.Coverage = stats.CalculateCoverage(developers, matrix).Ratio()
+	if config.FailOnEmpty && matrix.Len() == 0 {
+		exit(exitThreshold, fmt.Sprintf("No pairing found in %s", describePeriod(config)))
+	}
+	if config.GroupBySubTeam && subTeamsByDeveloper(teamObj, developers, useTeam) == nil {
+		note := "no developers are in a sub-team of the .team file, so -group-by-subteam has no effect"
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	}
+
+	commitSizes := pairing.CountCommitSizes(teamObj, commits, useTeam, buildOptions)
+
+	if config.Metric != "" {
+		filtered := buildOptions.Filter(commits)
+		value, err := stats.Metric(config.Metric, developers, matrix, filtered, commitSizes)
+		exitOnError(err, "Error calculating metric")
+		fmt.Println(value)
+		return
+	}
+
+	if config.Report != "" {
+		var history *pairing.Participation
+		if config.Report == "newcomers" {
+			progress.stage("Fetching the full history…")
+			historyCommits, err := getHistory(config, wd, progress)
+			exitOnError(err, "Error getting the full git history")
+			history = pairing.BuildParticipation(teamObj, cleanCommits(config, historyCommits, githubUsers), useTeam, buildOptions)
+		}
+		err = printReport(config, teamObj, buildOptions.Filter(commits), useTeam, matrix, pairRecency, developers, history, runStarted)
+		exitOnError(err, "Error generating report")
+		return
+	}
 
-	matrix, pairRecency, developers := pairing.BuildPairMatrix(teamObj, commits, useTeam)
+	if config.Command == commandStats {
+		filtered := buildOptions.Filter(commits)
+		exitOnError(printMetrics(developers, matrix, filtered, commitSizes), "Error calculating metrics")
+		return
+	}
+
+	matrixStyle, err := output.ParseMatrixStyle(config.MatrixStyle)
+	exitOnError(err, "Error parsing matrix style")
+
+	var reviews *pairing.Matrix
+	if config.IncludeReviewers {
+		reviews = pairing.BuildReviewMatrix(teamObj, buildOptions.Filter(commits), useTeam)
+	}
+
+	if config.Command == commandMatrix {
+		if config.Output == "stair" {
+			exitOnError(output.RenderStairToWriter(os.Stdout, matrix, pairRecency, developers), "Error rendering output")
+			return
+		}
+		output.PrintMatrixCLIWithOptions(matrix, developers, output.Options{Totals: config.Totals, SubTeams: subTeamsByDeveloper(teamObj, developers, useTeam), GroupBySubTeam: config.GroupBySubTeam, Roles: rolesByDeveloper(teamObj, developers, useTeam), MatrixStyle: matrixStyle, Recency: pairRecency, Now: runStarted, ASCII: config.NoUnicode, Reviews: reviews, Transpose: config.Transpose, CommitSizes: &commitSizes})
+		return
+	}
 
 	// Generate recommendations based on strategy
 	strategy := parseStrategy(config.Strategy)
-	recommendations := recommend.GenerateRecommendations(developers, matrix, pairRecency, strategy)
+	var observers []string
+	if useTeam {
+		observers = teamObj.Observers()
+	}
+
+	recentThreshold, err := thresholdDays(config.RecentThreshold, runStarted)
+	exitOnError(err, "Error parsing recent threshold")
+	minGap, err := thresholdDays(config.MinGap, runStarted)
+	exitOnError(err, "Error parsing minimum gap")
+	sitOut, err := recommend.ParseSitOutRule(config.SitOut)
+	exitOnError(err, "Error parsing sit-out rule")
+	bigTeamMode, err := recommend.ParseBigTeamMode(config.BigTeamMode)
+	exitOnError(err, "Error parsing big team mode")
+	recommendOptions := recommend.Options{
+		GreedyCutoff:    config.GreedyCutoff,
+		OptimalCutoff:   config.OptimalCutoff,
+		RecentThreshold: recentThreshold,
+		DemoteRecent:    config.DemoteRecent,
+		MinGap:          minGap,
+		Seed:            config.Seed,
+		SitOut:          sitOut,
+		Observers:       observers,
+		WideRecency:     config.RecencyWindow != "",
+		BigTeamMode:     bigTeamMode,
+	}
+
+	if config.Plan > 0 {
+		workingDays, err := recommend.ParseWorkingDays(config.WorkingDays)
+		exitOnError(err, "Error parsing working days")
+		plan := recommend.GeneratePlanWithOptions(developers, matrix, pairRecency, strategy, runStarted, config.Plan, workingDays, recommendOptions)
+		if config.Output == "ics" {
+			renderer := &output.ICSRenderer{Stamp: runStarted}
+			exitOnError(renderer.RenderPlan(plan), "Error rendering plan")
+			return
+		}
+		output.PrintPlanCLI(plan, string(strategy))
+		return
+	}
+
+	// Recommendations are skipped, and say why, when too few developers committed
+	recommendations, algorithm := []recommend.Recommendation(nil), recommend.AlgorithmNone
+	skippedBecause := ""
+	if active := len(recommend.WithoutObservers(pairing.ActiveDevelopers(developers, buildOptions.Filter(commits)), observers)); active < config.MinTeam {
+		skippedBecause = fmt.Sprintf("not enough active developers to recommend (need %d, have %d)", config.MinTeam, active)
+		runLog.Warnings = append(runLog.Warnings, skippedBecause)
+	} else {
+		recommendations, algorithm = recommend.GenerateRecommendationsWithOptions(developers, matrix, pairRecency, strategy, recommendOptions)
+	}
+	if team := len(recommend.WithoutObservers(developers, observers)); skippedBecause == "" && team > config.GreedyCutoff && bigTeamMode != recommend.BigTeamSkip {
+		note := fmt.Sprintf("%d developers is more than the greedy cutoff (%d); recommending for everyone with greedy matching", team, config.GreedyCutoff)
+		if bigTeamMode == recommend.BigTeamSample {
+			note = fmt.Sprintf("%d developers is more than the greedy cutoff (%d); recommending for a random sample of %d", team, config.GreedyCutoff, config.GreedyCutoff)
+		}
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	} else if strategy.Primary() == recommend.Coverage && algorithm == recommend.AlgorithmGreedy {
+		note := fmt.Sprintf("%d developers is more than the optimal cutoff (%d); using greedy matching", team, config.OptimalCutoff)
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	}
+	if unpaired := countUnpaired(recommendations); unpaired > len(recommend.WithoutObservers(developers, observers))%2 {
+		note := unpairedNote(unpaired, config.MinGap)
+		fmt.Fprintln(os.Stderr, "Note: "+note)
+		runLog.Warnings = append(runLog.Warnings, note)
+	}
+
+	if config.Baseline != "" {
+		err = compareWithBaseline(config, output.NewJSONResult(matrix, pairRecency, developers, string(strategy), recommendations))
+		exitOnError(err, "Error comparing with baseline")
+		return
+	}
+
+	recencyUnit, err := output.ParseRecencyUnit(config.RecencyUnit)
+	exitOnError(err, "Error parsing recency unit")
+
+	theme, err := output.ParseTheme(config.Theme)
+	exitOnError(err, "Error parsing theme")
+	dateStyle, err := output.ParseDateStyle(config.DateStyle)
+	exitOnError(err, "Error parsing date style")
+
+	recencyCap, err := thresholdDays(config.RecencyCap, runStarted)
+	exitOnError(err, "Error parsing recency cap")
+
+	options := output.Options{RecencyCap: recencyCap, RecencyUnit: recencyUnit, DateStyle: dateStyle, SubTeams: subTeamsByDeveloper(teamObj, developers, useTeam), Theme: theme, Print: config.Print, Totals: config.Totals, GroupBySubTeam: config.GroupBySubTeam, Roles: rolesByDeveloper(teamObj, developers, useTeam), MatrixStyle: matrixStyle, Recency: pairRecency, Now: runStarted, ASCII: config.NoUnicode, SkippedBecause: skippedBecause, Reviews: reviews, Transpose: config.Transpose, CommitSizes: &commitSizes, Strategy: string(strategy), Algorithm: algorithm}
+	if config.Command == commandRecommend {
+		output.PrintRecommendationsCLIWithOptions(recommendations, string(strategy), options)
+		return
+	}
+	if config.Out != "" {
+		err = writeOutputs(splitList(config.Output), splitList(config.Out), matrix, pairRecency, developers, string(strategy), recommendations, options)
+		exitOnError(err, "Error writing output")
+	} else if !config.Quiet {
+		renderer := output.NewRendererWithOptions(config.Output, config.Open, options)
+		if config.Output == "calendar" {
+			renderer, err = newCalendarRenderer(config, teamObj, commits, useTeam, buildOptions, theme)
+			exitOnError(err, "Error preparing calendar")
+		}
+		if config.Output == "weekdays" {
+			renderer = output.NewWeekdaysRenderer(pairing.BuildParticipation(teamObj, commits, useTeam, buildOptions))
+		}
+		if config.Output == "sparklines" {
+			renderer, err = newSparklineRenderer(config, teamObj, commits, useTeam, buildOptions)
+			exitOnError(err, "Error preparing sparklines")
+		}
+		if config.Output == "report" {
+			renderer = &output.NarrativeRenderer{Period: describePeriod(config)}
+		}
+		err = renderer.Render(matrix, pairRecency, developers, string(strategy), recommendations)
+		exitOnError(err, "Error rendering output")
+	}
+	if config.LabelsOut != "" {
+		exitOnError(writeLabels(config.LabelsOut, developers), "Error writing labels")
+	}
+
+	if config.PostURL != "" {
+		payload, err := output.WebhookPayload(config.Output, matrix, pairRecency, developers, string(strategy), recommendations, options)
+		exitOnError(err, "Error building webhook payload")
+		status, err := webhook.Post(config.PostURL, payload)
+		exitOnError(err, "Error posting to webhook")
+		fmt.Fprintf(os.Stderr, "Posted to webhook: %s\n", status)
+	}
+
+	if config.WriteNotes != "" {
+		var note bytes.Buffer
+		err := output.RenderJSONToWriterWithOptions(&note, matrix, pairRecency, developers, string(strategy), recommendations, options)
+		exitOnError(err, "Error building note")
+		exitOnError(git.AddNote(wd, config.WriteNotes, note.String()), "Error writing git note")
+		fmt.Fprintf(os.Stderr, "Wrote pairing data for %d developers to a git note on HEAD (notes ref %s)\n", len(developers), config.WriteNotes)
+	}
+}
+
+// writeLabels writes the developer for each row of the npmatrix output to a file
+func writeLabels(path string, developers []git.Developer) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = output.RenderNPLabelsToWriter(file, developers)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeOutputs renders the results in each format to the file at the same position
+// in paths, so that several formats come from a single analysis
+func writeOutputs(formats, paths []string, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, strategy string, recommendations []recommend.Recommendation, options output.Options) error {
+	for i, format := range formats {
+		file, err := os.Create(paths[i])
+		if err != nil {
+			return err
+		}
+		err = output.RenderToWriter(file, format, matrix, recencyMatrix, developers, strategy, recommendations, options)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", paths[i], err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s output to %s\n", format, paths[i])
+	}
+	return nil
+}
+
+// printReport prints the configured report to the CLI. history is the pairing over
+// the repository's whole history, which only the newcomers report needs.
+func printReport(config *Config, teamObj team.Team, commits []git.Commit, useTeam bool, matrix *pairing.Matrix, recencyMatrix *pairing.RecencyMatrix, developers []git.Developer, history *pairing.Participation, now time.Time) error {
+	switch report := config.Report; report {
+	case "lone-wolves":
+		soloCommits := pairing.CountSoloCommits(teamObj, commits, useTeam)
+		output.PrintLoneWolvesCLI(stats.LoneWolves(developers, matrix, soloCommits))
+	case "last-paired":
+		output.PrintLastPairingsCLI(stats.LastPairings(developers, recencyMatrix))
+	case "attribution":
+		output.PrintAttributionsCLI(stats.Attributions(developers, matrix))
+		fmt.Println()
+		output.PrintAttributionMatrixCLI(matrix, developers, config.Transpose)
+	case "missing-trailers":
+		authored, missing := pairing.CountMissingTrailers(teamObj, commits, useTeam)
+		output.PrintMissingTrailersCLI(stats.MissingTrailers(developers, authored, missing))
+	case "name-variants":
+		variants := identity.NameVariants(commits)
+		if useTeam {
+			variants = slices.DeleteFunc(variants, func(v identity.NameVariant) bool {
+				return !teamObj.HasDeveloperByEmail(v.Email)
+			})
+		}
+		output.PrintNameVariantsCLI(variants)
+	case "pairing-debt":
+		start, err := windowStart(config, commits, now)
+		if err != nil {
+			return err
+		}
+		output.PrintPairingDebtsCLI(stats.PairingDebts(developers, matrix, recencyMatrix, now, now.Sub(start)))
+	case "newcomers":
+		start, err := windowStart(config, commits, now)
+		if err != nil {
+			return err
+		}
+		output.PrintNewcomersCLI(stats.Newcomers(developers, history, start))
+	case "stale-developers":
+		threshold, err := thresholdDays(config.StaleThreshold, now)
+		if err != nil {
+			return err
+		}
+		output.PrintStaleDevelopersCLI(stats.StaleDevelopers(developers, recencyMatrix, now, threshold), threshold)
+	case "suggestions-per-person":
+		options, err := suggestionOptions(config, teamObj, useTeam, now)
+		if err != nil {
+			return err
+		}
+		output.PrintSuggestionsPerPersonCLI(recommend.BestPartners(developers, matrix, recencyMatrix, parseStrategy(config.Strategy), options))
+	default:
+		return fmt.Errorf("unknown report: %s", report)
+	}
+	return nil
+}
+
+// suggestionOptions builds the recommendation options that apply to suggesting
+// each developer's best partner on their own
+func suggestionOptions(config *Config, teamObj team.Team, useTeam bool, now time.Time) (recommend.Options, error) {
+	recentThreshold, err := thresholdDays(config.RecentThreshold, now)
+	if err != nil {
+		return recommend.Options{}, err
+	}
+	minGap, err := thresholdDays(config.MinGap, now)
+	if err != nil {
+		return recommend.Options{}, err
+	}
+	options := recommend.Options{
+		RecentThreshold: recentThreshold,
+		DemoteRecent:    config.DemoteRecent,
+		MinGap:          minGap,
+		WideRecency:     config.RecencyWindow != "",
+	}
+	if useTeam {
+		options.Observers = teamObj.Observers()
+	}
+	return options, nil
+}
+
+// compareWithBaseline prints the changes since the baseline JSON result, exiting
+// with an error if coverage dropped by more than the configured threshold
+func compareWithBaseline(config *Config, current output.JSONResult) error {
+	baseline, err := output.LoadJSONResultFile(config.Baseline)
+	if err != nil {
+		return err
+	}
+
+	diff := output.DiffJSONResults(baseline, current)
+	if err := output.RenderBaselineDiffToWriter(os.Stdout, diff); err != nil {
+		return err
+	}
+
+	if drop := -diff.CoverageChange(); drop > config.MaxCoverageDrop {
+		exit(exitThreshold, fmt.Sprintf("Coverage dropped by %.1f points, more than the allowed %.1f", drop, config.MaxCoverageDrop))
+	}
+	return nil
+}
+
+// windowStart returns when the -window reaching back from now starts, or the -since
+// date. With -window all, or only -until, which have no start, it's the date of the
+// earliest commit, so that charts of the window don't reach back to year one.
+func windowStart(config *Config, commits []git.Commit, now time.Time) (time.Time, error) {
+	if config.Since != "" {
+		return git.ParseDay(config.Since)
+	}
+	start, err := git.WindowStart(config.Window, now)
+	if err != nil || config.Window != git.AllTime && !config.dateRange() {
+		return start, err
+	}
+	start = now
+	for _, c := range commits {
+		if c.Date.Before(start) {
+			start = c.Date
+		}
+	}
+	return start, nil
+}
+
+// windowEnd returns when the commits being analyzed end: the end of the -until date,
+// if that's before now, or else now
+func windowEnd(config *Config, now time.Time) (time.Time, error) {
+	if config.Until == "" {
+		return now, nil
+	}
+	_, before, err := git.DateRange("", config.Until)
+	if err != nil || !before.Before(now) {
+		return now, err
+	}
+	return before.Add(-time.Nanosecond), nil
+}
+
+// newCalendarRenderer creates the renderer for the calendar output, which needs
+// day-by-day pairing data that the matrix doesn't keep
+func newCalendarRenderer(config *Config, teamObj team.Team, commits []git.Commit, useTeam bool, buildOptions pairing.BuildOptions, theme output.Theme) (output.OutputRenderer, error) {
+	end, err := windowEnd(config, buildOptions.Now)
+	if err != nil {
+		return nil, err
+	}
+	start, err := windowStart(config, commits, end)
+	if err != nil {
+		return nil, err
+	}
+	participation := pairing.BuildParticipation(teamObj, commits, useTeam, buildOptions)
+	renderer := output.NewCalendarRenderer(participation, start, end)
+	renderer.Theme = theme
+	return renderer, nil
+}
+
+// newSparklineRenderer prepares the sparklines output, with a week for every week
+// of the window
+func newSparklineRenderer(config *Config, teamObj team.Team, commits []git.Commit, useTeam bool, buildOptions pairing.BuildOptions) (output.OutputRenderer, error) {
+	end, err := windowEnd(config, buildOptions.Now)
+	if err != nil {
+		return nil, err
+	}
+	start, err := windowStart(config, commits, end)
+	if err != nil {
+		return nil, err
+	}
+	return &output.SparklineRenderer{
+		Participation: pairing.BuildParticipation(teamObj, commits, useTeam, buildOptions),
+		Start:         start,
+		End:           end,
+		Pairs:         config.SparklinePairs,
+		ASCII:         config.NoUnicode,
+	}, nil
+}
+
+// checkStrictTeam reports an error if anyone who made commits with the team isn't
+// in the team files. The whole team is checked, whichever sub-team is selected.
+func checkStrictTeam(wd string, commits []git.Commit, useTeam bool) error {
+	if !useTeam {
+		return fmt.Errorf("-strict-team requires a .team file")
+	}
+	wholeTeam, err := team.NewTeamFromFiles(teamFiles(wd), "")
+	if err != nil {
+		return err
+	}
+	unknown := wholeTeam.UnknownParticipants(commits)
+	if len(unknown) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d participant(s) not in .team:\n  %s", len(unknown), strings.Join(unknown, "\n  "))
+}
+
+// warnInvalidEmails warns about co-author emails that aren't valid addresses,
+// usually from a mistyped Co-authored-by trailer, or with strict notes that they're
+// ignored. It returns the emails it printed.
+func warnInvalidEmails(invalid []string, strict bool) []string {
+	if len(invalid) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(invalid))
+	for i, email := range invalid {
+		quoted[i] = fmt.Sprintf("%q", email)
+	}
+	if strict {
+		fmt.Fprintf(os.Stderr, "Note: ignoring co-authors with invalid emails: %s\n", strings.Join(quoted, ", "))
+		return quoted
+	}
+	fmt.Fprintln(os.Stderr, "Warning: these co-author emails aren't valid addresses; fix the Co-authored-by trailers, or pass -strict-emails to ignore them:")
+	fmt.Fprintln(os.Stderr, "  "+strings.Join(quoted, "\n  "))
+	fmt.Fprintln(os.Stderr, "")
+	return quoted
+}
+
+// warnSelfPairs warns about commits whose author also seems to be a co-author
+// under another email, unless the .team file already links the two emails. It
+// returns the warnings it printed.
+func warnSelfPairs(teamObj team.Team, commits []git.Commit, useTeam bool) []string {
+	_, emailToPrimaryEmail := teamObj.GetEmailMappings()
+	var lines []string
+	for _, selfPair := range identity.SelfPairs(commits) {
+		primary, ok := emailToPrimaryEmail[selfPair.Author]
+		if useTeam && ok && primary == emailToPrimaryEmail[selfPair.CoAuthor] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s with co-author %s (%d commits)", selfPair.Name, selfPair.Author, selfPair.CoAuthor, selfPair.Commits))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "Warning: these developers seem to be co-authors of their own commits under another email; list both emails on their line in .team:")
+	fmt.Fprintln(os.Stderr, "  "+strings.Join(lines, "\n  "))
+	fmt.Fprintln(os.Stderr, "")
+	return lines
+}
+
+// validateTeam checks the team files can be read and that everyone committing with
+// the team in the window is in them
+func validateTeam(wd string, commits []git.Commit, useTeam bool) error {
+	if !useTeam {
+		return fmt.Errorf("no .team file found")
+	}
+	if err := checkStrictTeam(wd, commits, useTeam); err != nil {
+		return err
+	}
+	wholeTeam, err := team.NewTeamFromFiles(teamFiles(wd), "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Team OK: %d developers\n", len(wholeTeam.GetDevelopers()))
+	return nil
+}
+
+// printMetrics prints every metric, one per line
+func printMetrics(developers []git.Developer, matrix *pairing.Matrix, commits []git.Commit, sizes pairing.CommitSizes) error {
+	for _, name := range stats.Metrics {
+		value, err := stats.Metric(name, developers, matrix, commits, sizes)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", name, value)
+	}
+	return nil
+}
+
+// unpairedNote explains why more developers than the odd one out are left unpaired,
+// blaming the minimum gap only if there is one
+func unpairedNote(unpaired int, minGap string) string {
+	if minGap != "" {
+		return fmt.Sprintf("%d developers have no partner outside the %s minimum gap and are left unpaired", unpaired, minGap)
+	}
+	return fmt.Sprintf("%d developers are left unpaired", unpaired)
+}
+
+// countUnpaired counts the recommendations that leave a developer without a partner
+func countUnpaired(recommendations []recommend.Recommendation) int {
+	unpaired := 0
+	for _, rec := range recommendations {
+		if len(rec.B.EmailAddresses) == 0 {
+			unpaired++
+		}
+	}
+	return unpaired
+}
+
+// thresholdDays converts a period such as "7d" or "2w", counted back from now, to
+// a number of days. An empty period is zero days.
+func thresholdDays(period string, now time.Time) (int, error) {
+	if period == "" {
+		return 0, nil
+	}
+	start, err := git.WindowStart(period, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(now.Sub(start).Hours() / 24), nil
+}
+
+// describePeriod puts the commits being analyzed into words, e.g. "the last 2 weeks"
+func describePeriod(config *Config) string {
+	switch {
+	case config.LastCommits == 1:
+		return "the last commit"
+	case config.LastCommits > 0:
+		return fmt.Sprintf("the last %d commits", config.LastCommits)
+	case config.Range != "":
+		return "the commits in " + config.Range
+	case config.SinceLastRun:
+		return "the time since the last run"
+	case config.Since != "" && config.Until != "":
+		return config.Since + " to " + config.Until
+	case config.Since != "":
+		return "the time since " + config.Since
+	case config.Until != "":
+		return "the history up to " + config.Until
+	}
+	units := map[byte]string{'d': "day", 'w': "week", 'm': "month", 'y': "year"}
+	if config.Window == git.AllTime {
+		return "the whole history"
+	}
+	if git.ValidateWindow(config.Window) != nil {
+		return "the last " + config.Window
+	}
+	n, unit := config.Window[:len(config.Window)-1], units[config.Window[len(config.Window)-1]]
+	if n == "1" {
+		return "the last " + unit
+	}
+	return "the last " + n + " " + unit + "s"
+}
+
+// repoDir returns the directory of the repository to analyze: the root of the
+// working tree the -repo path, or the working directory if there isn't one, is in.
+// In a linked worktree that's the worktree's own root, where its .team is. A bare
+// repository, or a directory outside any repository, is returned as it is.
+func repoDir(repo string) (string, error) {
+	dir, err := os.Getwd()
+	if repo != "" {
+		dir, err = filepath.Abs(repo)
+	}
+	if err != nil {
+		return "", err
+	}
+	if top, err := git.TopLevel(dir); err == nil {
+		return top, nil
+	}
+	return dir, nil
+}
+
+// lastRunKey returns what the last run in the repository is recorded under: its
+// common git directory, so that every worktree of a repository shares one record,
+// or the repository's directory if git can't say
+func lastRunKey(repo string) string {
+	if common, err := git.CommonDir(repo); err == nil {
+		return common
+	}
+	return repo
+}
+
+// teamFiles returns the team files to merge, from the most shared to the most local:
+// the user's ~/.pairstair/team, then the repository's .team
+func teamFiles(wd string) []string {
+	var files []string
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".pairstair", "team"))
+	}
+	return append(files, filepath.Join(wd, ".team"))
+}
+
+// subTeamsByDeveloper maps each developer's canonical email to the sub-teams they're
+// listed in, or returns nil when the team file has no sub-teams
+func subTeamsByDeveloper(teamObj team.Team, developers []git.Developer, useTeam bool) map[string][]string {
+	if !useTeam || !teamObj.HasSubTeams() {
+		return nil
+	}
+	subTeams := make(map[string][]string)
+	for _, dev := range developers {
+		if names := teamObj.SubTeams(dev.CanonicalEmail()); len(names) > 0 {
+			subTeams[dev.CanonicalEmail()] = names
+		}
+	}
+	return subTeams
+}
+
+// rolesByDeveloper maps each developer's canonical email to their role on the team,
+// or returns nil when the team file gives no one a role
+func rolesByDeveloper(teamObj team.Team, developers []git.Developer, useTeam bool) map[string]string {
+	if !useTeam || !teamObj.HasRoles() {
+		return nil
+	}
+	roles := make(map[string]string)
+	for _, dev := range developers {
+		if role := teamObj.Role(dev.CanonicalEmail()); role != "" {
+			roles[dev.CanonicalEmail()] = role
+		}
+	}
+	return roles
+}
+
+// getCommits fetches the commits to analyze from git
+func getCommits(config *Config, repo string, progress *progressReporter) ([]git.Commit, error) {
+	opts, err := logOptions(config, repo)
+	if err != nil {
+		return nil, err
+	}
+	opts.Progress = progress.logProgress()
+	commits, err := readCommits(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return importPairings(config, commits, opts.After)
+}
+
+// readCommits reads the commits for the options from the repository and, with
+// -recurse-submodules, from each of its submodules
+func readCommits(config *Config, opts git.LogOptions) ([]git.Commit, error) {
+	commits, err := git.GetCommits(opts)
+	if err != nil || !config.RecurseSubmodules {
+		return commits, err
+	}
+	submodules, err := git.Submodules(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range submodules {
+		opts.Dir = dir
+		submoduleCommits, err := git.GetCommits(opts)
+		if err != nil {
+			return nil, fmt.Errorf("submodule %s: %w", dir, err)
+		}
+		commits = append(commits, submoduleCommits...)
+	}
+	return commits, nil
+}
+
+// getRecencyCommits fetches the commits in the -recency-window, which last
+// pairings are read from instead of the commits being counted
+func getRecencyCommits(config *Config, repo string, progress *progressReporter) ([]git.Commit, error) {
+	after, err := git.WindowStart(config.RecencyWindow, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	dateBasis, err := git.ParseDateBasis(config.DateBasis)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := readCommits(config, git.LogOptions{Since: git.WindowToGitSince(config.RecencyWindow), After: after, DateBasis: dateBasis, AllRefs: config.All, FirstParent: config.FirstParent, NoMailmap: config.NoMailmap, Notes: config.FromNotes, Dir: repo, Progress: progress.logProgress()})
+	if err != nil {
+		return nil, err
+	}
+	return importPairings(config, commits, after)
+}
+
+// getHistory fetches every commit in the repository's history, ignoring the window,
+// for reports that need to know what happened before it
+func getHistory(config *Config, repo string, progress *progressReporter) ([]git.Commit, error) {
+	dateBasis, err := git.ParseDateBasis(config.DateBasis)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := readCommits(config, git.LogOptions{DateBasis: dateBasis, AllRefs: config.All, FirstParent: config.FirstParent, NoMailmap: config.NoMailmap, Notes: config.FromNotes, Dir: repo, Progress: progress.logProgress()})
+	if err != nil {
+		return nil, err
+	}
+	return importPairings(config, commits, time.Time{})
+}
+
+// importPairings adds the pairings from the -import file made after the given
+// time, if there is one, to the commits
+func importPairings(config *Config, commits []git.Commit, after time.Time) ([]git.Commit, error) {
+	if config.Import == "" {
+		return commits, nil
+	}
+	imported, err := pairing.ReadImportFile(config.Import)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", config.Import, err)
+	}
+	for _, c := range imported {
+		if !c.Date.Before(after) {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// cleanCommits drops ignored co-authors and tidies up identities, as set up by
+// -ignore-coauthors, -strict-emails, -github-users, -merge-noreply and -normalize-names
+func cleanCommits(config *Config, commits []git.Commit, githubUsers map[string]string) []git.Commit {
+	commits = git.RemoveCoAuthors(commits, splitList(config.IgnoreCoAuthors))
+	if config.StrictEmails {
+		commits = git.RemoveInvalidCoAuthors(commits)
+	}
+	commits = identity.MergeGitHubNoreply(commits, githubUsers, config.MergeNoreply)
+	if config.NormalizeNames {
+		commits = identity.NormalizeNames(commits)
+	}
+	return commits
+}
+
+// logOptions builds the git log options for the configured window, or since the
+// last recorded run when -since-last-run is set and a previous run exists
+func logOptions(config *Config, repo string) (git.LogOptions, error) {
+	dateBasis, err := git.ParseDateBasis(config.DateBasis)
+	if err != nil {
+		return git.LogOptions{}, err
+	}
+	opts := git.LogOptions{AllRefs: config.All, FirstParent: config.FirstParent, NoMailmap: config.NoMailmap, Notes: config.FromNotes, Dir: repo, Limit: config.LastCommits, DateBasis: dateBasis}
+
+	if config.Range != "" {
+		opts.Range = config.Range
+		return opts, git.ValidateRange(config.Range)
+	}
+	if config.LastCommits > 0 {
+		return opts, nil
+	}
+
+	if config.dateRange() {
+		opts.After, opts.Before, err = git.DateRange(config.Since, config.Until)
+		if err != nil {
+			return opts, err
+		}
+		if !opts.After.IsZero() {
+			opts.Since = opts.After.Format(time.RFC3339)
+		}
+		// --until compares committer dates, which are never before the author
+		// dates, so it would drop commits authored in the range but committed later
+		if !opts.Before.IsZero() && dateBasis == git.CommitterDate {
+			opts.Until = opts.Before.Format(time.RFC3339)
+		}
+		return opts, nil
+	}
+
+	if config.SinceLastRun {
+		if store, err := lastrun.NewDefaultStore(); err == nil {
+			if last, ok := store.Get(lastRunKey(repo)); ok {
+				opts.Since = last.Format(time.RFC3339)
+				opts.After = last
+				return opts, nil
+			}
+		}
+	}
+
+	opts.After, err = git.WindowStart(config.Window, time.Now())
+	if err != nil {
+		return opts, err
+	}
+	opts.Since = git.WindowToGitSince(config.Window)
+	return opts, nil
+}
 
-	renderer := output.NewRendererWithOpen(config.Output, config.Open)
-	err = renderer.Render(matrix, pairRecency, developers, config.Strategy, recommendations)
-	exitOnError(err, "Error rendering output")
+// recordLastRun stores the time of this run, warning rather than failing if it can't
+func recordLastRun(repo string, when time.Time) {
+	store, err := lastrun.NewDefaultStore()
+	if err == nil {
+		err = store.Set(lastRunKey(repo), when)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record last run time: %v\n", err)
+	}
 }
 
 // Config holds all command-line configuration
 type Config struct {
-	Window   string
-	Output   string
-	Strategy string
-	Team     string
-	Version  bool
-	Open     bool
+	Window            string
+	Output            string
+	Strategy          string
+	Team              string
+	Version           bool
+	Open              bool
+	Plan              int
+	WorkingDays       string
+	SinceLastRun      bool
+	Report            string
+	RecencyUnit       string
+	All               bool
+	PostURL           string
+	Quiet             bool
+	ExcludeToday      bool
+	GreedyCutoff      int
+	OptimalCutoff     int
+	MobWeight         string
+	MergeNoreply      bool
+	GitHubUsers       string
+	Baseline          string
+	MaxCoverageDrop   float64
+	IgnoreCoAuthors   string
+	StrictTeam        bool
+	Theme             string
+	Print             bool
+	DateStyle         string
+	FromNotes         string
+	RecentThreshold   string
+	DemoteRecent      bool
+	Metric            string
+	WriteNotes        string
+	DetectSelfPairs   bool
+	Totals            bool
+	Hours             string
+	LogFile           string
+	Labels            string
+	Range             string
+	ShowConfig        bool
+	Repo              string
+	FrequentNames     bool
+	PairsOnly         bool
+	MobsOnly          bool
+	RecencyCap        string
+	MinGap            string
+	Out               string
+	Seed              int64
+	Only              string
+	NormalizeNames    bool
+	GroupBySubTeam    bool
+	LastCommits       int
+	SitOut            string
+	RecencyWindow     string
+	LabelsOut         string
+	Progress          bool
+	DateBasis         string
+	SparklinePairs    int
+	NoUnicode         bool
+	StrictEmails      bool
+	BigTeamMode       string
+	Import            string
+	DumpCommits       bool
+	FirstParent       bool
+	MatrixStyle       string
+	RecurseSubmodules bool
+	MinTeam           int
+	IncludeReviewers  bool
+	StaleThreshold    string
+	NoMailmap         bool
+	Since             string
+	Until             string
+	ListTeams         bool
+	Transpose         bool
+	FailOnEmpty       bool
+	// Command is the subcommand being run, or empty for the default matrix and recommendations
+	Command string
+	// WindowSet records whether -window was given, rather than left at its default
+	WindowSet bool
+}
+
+// Validate reports an error for flag combinations that don't make sense together
+func (c *Config) Validate() error {
+	switch {
+	case c.Open && c.Output != "html":
+		return fmt.Errorf("-open only applies to -output html")
+	case c.Plan < 0:
+		return fmt.Errorf("-plan must not be negative")
+	case c.Plan > 0 && c.Report != "":
+		return fmt.Errorf("-plan and -report can't be used together")
+	case c.Plan > 0 && c.Output != "cli" && c.Output != "ics":
+		return fmt.Errorf("-plan only supports -output cli or ics")
+	case c.Plan == 0 && c.Output == "ics":
+		return fmt.Errorf("-output ics requires -plan")
+	case c.Report != "" && c.Output != "cli":
+		return fmt.Errorf("-report only supports -output cli")
+	case c.PostURL != "" && c.Output != "slack" && c.Output != "json":
+		return fmt.Errorf("-post-url requires -output slack or json")
+	case c.Quiet && c.PostURL == "":
+		return fmt.Errorf("-quiet requires -post-url, otherwise there is no output")
+	case c.Baseline != "" && (c.Plan > 0 || c.Report != "" || c.PostURL != "" || c.Output != "cli"):
+		return fmt.Errorf("-baseline prints its own comparison and can't be used with -plan, -report, -post-url or -output")
+	case c.MaxCoverageDrop < 0:
+		return fmt.Errorf("-max-coverage-drop must not be negative")
+	case c.GreedyCutoff < 0 || c.OptimalCutoff < 0:
+		return fmt.Errorf("-greedy-cutoff and -optimal-cutoff must not be negative")
+	case c.Theme != "" && c.Theme != "light" && !c.hasOutput("html") && c.Output != "calendar":
+		return fmt.Errorf("-theme only applies to -output html or calendar")
+	case c.Print && !c.hasOutput("html"):
+		return fmt.Errorf("-print only applies to -output html")
+	case c.Metric != "" && (c.Plan > 0 || c.Report != "" || c.Baseline != "" || c.PostURL != "" || c.Output != "cli"):
+		return fmt.Errorf("-metric prints a single value and can't be used with -plan, -report, -baseline, -post-url or -output")
+	case c.DemoteRecent && c.RecentThreshold == "":
+		return fmt.Errorf("-demote-recent requires -recent-threshold")
+	case c.WriteNotes != "" && (c.Plan > 0 || c.Report != "" || c.Metric != "" || c.Baseline != "" || c.Command != ""):
+		return fmt.Errorf("-write-notes can't be used with -plan, -report, -metric, -baseline or a subcommand")
+	case c.Totals && c.Output != "cli" && !c.hasOutput("html") && !c.hasOutput("confluence"):
+		return fmt.Errorf("-totals only applies to -output cli, html or confluence")
+	case c.Range != "" && (c.WindowSet || c.SinceLastRun || c.All):
+		return fmt.Errorf("-range can't be used with -window, -since-last-run or -all")
+	case c.LastCommits < 0:
+		return fmt.Errorf("-last-commits must not be negative")
+	case c.LastCommits > 0 && (c.WindowSet || c.SinceLastRun):
+		return fmt.Errorf("-last-commits can't be used with -window or -since-last-run")
+	case c.GroupBySubTeam && !c.hasOutput("cli") && !c.hasOutput("html"):
+		return fmt.Errorf("-group-by-subteam only applies to -output cli or html")
+	case c.SparklinePairs < 0:
+		return fmt.Errorf("-sparkline-pairs must not be negative")
+	case c.MatrixStyle == string(output.RecencyBandsStyle) && c.Output != "cli":
+		return fmt.Errorf("-matrix-style recency-bands only applies to -output cli")
+	case c.NoUnicode && c.Output != "sparklines" && c.MatrixStyle != string(output.RecencyBandsStyle):
+		return fmt.Errorf("-no-unicode only applies to -output sparklines or -matrix-style recency-bands")
+	case c.LabelsOut != "" && !c.hasOutput("npmatrix"):
+		return fmt.Errorf("-labels-out only applies to -output npmatrix")
+	case c.DumpCommits && (c.Command != "" || c.Plan > 0 || c.Report != "" || c.Metric != "" || c.Baseline != "" || c.WriteNotes != "" || c.Output != "cli"):
+		return fmt.Errorf("-dump-commits prints the commits instead of the results, so it can't be used with a subcommand, -output, -plan, -report, -metric, -baseline or -write-notes")
+	case c.Window != "" && git.ValidateWindow(c.Window) != nil:
+		return fmt.Errorf("invalid -window %q: use a number and d, w, m or y, such as 2w, or all", c.Window)
+	case c.MobWeight == string(pairing.MobWeightSplit) && c.hasOutput("npmatrix"):
+		return fmt.Errorf("-output npmatrix only has whole counts, so it can't show -mob-weight split; use -output tsv for the weights")
+	case c.Transpose && c.Output != "cli" && !c.hasOutput("html"):
+		return fmt.Errorf("-transpose only applies to -output cli or html")
+	case c.ListTeams && c.Command != "":
+		return fmt.Errorf("-list-teams prints the teams instead of the results, so it can't be used with a subcommand")
+	case c.Since != "" && git.ValidateDay(c.Since) != nil:
+		return fmt.Errorf("invalid -since %q: use YYYY-MM-DD, such as 2024-01-01", c.Since)
+	case c.Until != "" && git.ValidateDay(c.Until) != nil:
+		return fmt.Errorf("invalid -until %q: use YYYY-MM-DD, such as 2024-03-31", c.Until)
+	case c.Since != "" && c.Until != "" && c.Since > c.Until:
+		return fmt.Errorf("-since %s is after -until %s", c.Since, c.Until)
+	case (c.Since != "" || c.Until != "") && (c.Range != "" || c.LastCommits > 0 || c.SinceLastRun):
+		return fmt.Errorf("-since and -until can't be used with -range, -last-commits or -since-last-run")
+	case c.StaleThreshold != "" && (c.StaleThreshold == git.AllTime || git.ValidateWindow(c.StaleThreshold) != nil):
+		return fmt.Errorf("invalid -stale-threshold %q: use a number and d, w, m or y, such as 14d", c.StaleThreshold)
+	case c.IncludeReviewers && (c.Output != "cli" || c.Command == commandStats || c.Report != ""):
+		return fmt.Errorf("-include-reviewers only applies to the -output cli matrix")
+	case c.MinTeam < 0:
+		return fmt.Errorf("-min-team must not be negative")
+	case c.RecurseSubmodules && (c.Range != "" || c.LastCommits > 0):
+		return fmt.Errorf("-recurse-submodules can't be used with -range or -last-commits")
+	case c.Import != "" && (c.Range != "" || c.LastCommits > 0):
+		return fmt.Errorf("-import can't be used with -range or -last-commits, which don't cover a period of time")
+	case c.RecencyWindow != "" && (c.Range != "" || c.LastCommits > 0):
+		return fmt.Errorf("-recency-window can't be used with -range or -last-commits")
+	case c.RecencyWindow != "" && c.Plan > 0:
+		return fmt.Errorf("-recency-window doesn't apply to -plan")
+	case c.MinGap != "" && c.Plan > 0:
+		return fmt.Errorf("-min-gap doesn't apply to -plan")
+	case c.Out == "" && len(splitList(c.Output)) > 1:
+		return fmt.Errorf("more than one -output format requires -out with a file for each")
+	case c.Out != "" && len(splitList(c.Out)) != len(splitList(c.Output)):
+		return fmt.Errorf("-output has %d formats but -out has %d files", len(splitList(c.Output)), len(splitList(c.Out)))
+	case c.Out != "" && slices.ContainsFunc(splitList(c.Output), func(format string) bool { return !slices.Contains(output.FileFormats, format) }):
+		return fmt.Errorf("-out only supports -output %s", strings.Join(output.FileFormats, ", "))
+	case c.Out != "" && (c.Plan > 0 || c.Report != "" || c.Metric != "" || c.Baseline != "" || c.Quiet || c.Open || c.Command != ""):
+		return fmt.Errorf("-out can't be used with -plan, -report, -metric, -baseline, -quiet, -open or a subcommand")
+	case c.Command == commandMatrix && (c.Plan > 0 || c.Report != "" || c.Metric != "" || c.Baseline != "" || c.PostURL != ""):
+		return fmt.Errorf("matrix can't be used with -plan, -report, -metric, -baseline or -post-url")
+	case c.Command == commandMatrix && c.Output != "cli" && c.Output != "stair":
+		return fmt.Errorf("matrix only supports -output cli or stair")
+	case c.Command == commandRecommend && (c.Report != "" || c.Metric != "" || c.Baseline != "" || c.PostURL != ""):
+		return fmt.Errorf("recommend can't be used with -report, -metric, -baseline or -post-url")
+	case c.Command == commandRecommend && c.Plan == 0 && c.Output != "cli":
+		return fmt.Errorf("recommend only supports -output cli")
+	case c.Command == commandStats && (c.Plan > 0 || c.Baseline != "" || c.PostURL != "" || c.Output != "cli"):
+		return fmt.Errorf("stats can't be used with -plan, -baseline, -post-url or non-cli output")
+	case c.Command == commandValidateTeam && (c.Plan > 0 || c.Report != "" || c.Metric != "" || c.Baseline != "" || c.PostURL != ""):
+		return fmt.Errorf("validate-team can't be used with -plan, -report, -metric, -baseline or -post-url")
+	case c.PairsOnly && c.MobsOnly:
+		return fmt.Errorf("-pairs-only (or -dedicated-only) and -mobs-only can't be used together")
+	}
+	return nil
+}
+
+// dateRange reports whether -since or -until choose the commits, instead of -window
+func (c *Config) dateRange() bool {
+	return c.Since != "" || c.Until != ""
+}
+
+// hasOutput reports whether the format is the -output, or one of several written with -out
+func (c *Config) hasOutput(format string) bool {
+	return slices.Contains(splitList(c.Output), format)
+}
+
+// splitCommand separates a leading subcommand from the flags that follow it.
+// Arguments that start with a flag have no subcommand, so pairstair runs as it
+// did before subcommands.
+func splitCommand(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", args, nil
+	}
+	if !slices.Contains(commands, args[0]) {
+		return "", nil, fmt.Errorf("unknown command: %s (expected %s)", args[0], strings.Join(commands, ", "))
+	}
+	return args[0], args[1:], nil
 }
 
 // parseFlags parses command-line flags and returns a Config
 func parseFlags() *Config {
+	return parseFlagSet(flag.CommandLine, os.Args[1:])
+}
+
+// parseFlagSet defines the flags on the flag set, which every subcommand shares,
+// then parses the arguments and returns a Config
+func parseFlagSet(flags *flag.FlagSet, args []string) *Config {
 	config := &Config{}
-	flag.StringVar(&config.Window, "window", "1w", "Time window to examine (e.g. 1d, 2w, 3m, 1y)")
-	flag.StringVar(&config.Output, "output", "cli", "Output format: 'cli' (default) or 'html'")
-	flag.StringVar(&config.Strategy, "strategy", "least-paired", "Recommendation strategy: 'least-paired' (default) or 'least-recent'")
-	flag.StringVar(&config.Team, "team", "", "Sub-team to analyze (e.g. 'frontend', 'backend')")
-	flag.BoolVar(&config.Version, "version", false, "Show version information")
-	flag.BoolVar(&config.Open, "open", false, "Open HTML output in browser (only applies when -output=html)")
-	flag.Parse()
+	flags.StringVar(&config.Window, "window", "1w", "Time window to examine (e.g. 1d, 2w, 3m, 1y), or 'all' for the whole history")
+	flags.StringVar(&config.Output, "output", "cli", "Output format: 'cli' (default), 'html', 'slack', 'json', 'stair', 'calendar', 'weekdays', 'confluence', 'tsv', 'npmatrix' (counts for numpy.loadtxt), 'sparklines' (weekly pairing of the most paired pairs), 'report' (a summary in prose) or 'ics' (with -plan); a comma-separated list with -out")
+	flags.StringVar(&config.Strategy, "strategy", "least-paired", "Recommendation strategy: 'least-paired' (default), 'least-recent' or 'coverage'; combine with commas to break ties (e.g. 'least-paired,least-recent')")
+	flags.StringVar(&config.Team, "team", "", "Sub-team to analyze (e.g. 'frontend', 'backend')")
+	flags.BoolVar(&config.Version, "version", false, "Show version information")
+	flags.BoolVar(&config.Open, "open", false, "Open HTML output in browser (requires -output=html)")
+	flags.IntVar(&config.Plan, "plan", 0, "Plan pairings for the next N working days instead of a single recommendation")
+	flags.StringVar(&config.WorkingDays, "working-days", "mon,tue,wed,thu,fri", "Working days used by -plan (comma-separated, e.g. 'mon,tue,wed')")
+	flags.BoolVar(&config.SinceLastRun, "since-last-run", false, "Only analyze commits since the last successful run in this repository (falls back to -window on first run)")
+	flags.StringVar(&config.Report, "report", "", "Print a report instead of the matrix: 'lone-wolves', 'last-paired', 'pairing-debt', 'attribution', 'name-variants', 'missing-trailers', 'suggestions-per-person', 'newcomers', 'stale-developers'")
+	flags.StringVar(&config.RecencyUnit, "recency-unit", "days", "Unit for showing how long ago pairs last paired: 'days' (default) or 'weeks'")
+	flags.BoolVar(&config.All, "all", false, "Read commits from all refs (branches, tags, remotes), not just the current branch")
+	flags.StringVar(&config.PostURL, "post-url", "", "POST the rendered output to a webhook URL (requires -output slack or json)")
+	flags.BoolVar(&config.Quiet, "quiet", false, "Don't print the rendered output to stdout (useful with -post-url)")
+	flags.BoolVar(&config.ExcludeToday, "exclude-today", false, "Ignore commits made today, e.g. for end-of-day reports on completed work")
+	flags.IntVar(&config.GreedyCutoff, "greedy-cutoff", recommend.DefaultOptions.GreedyCutoff, "Largest number of developers to make recommendations for")
+	flags.IntVar(&config.OptimalCutoff, "optimal-cutoff", recommend.DefaultOptions.OptimalCutoff, "Largest number of developers for the exhaustive 'coverage' matcher; larger teams fall back to greedy matching")
+	flags.StringVar(&config.MobWeight, "mob-weight", "equal", "How pairs in commits with several co-authors count: 'equal' (default, every pair counts fully) or 'split' (the commit's pairing is divided between its pairs)")
+	flags.BoolVar(&config.MergeNoreply, "merge-noreply", false, "Treat GitHub noreply emails as the same developer as another email whose local part matches the GitHub username")
+	flags.StringVar(&config.GitHubUsers, "github-users", "", "Map GitHub usernames to emails, so noreply commits count as that developer (e.g. 'octocat=octo@example.com,alice=alice@example.com')")
+	flags.StringVar(&config.Baseline, "baseline", "", "Compare with a JSON result saved from -output json, printing coverage and pair count changes")
+	flags.Float64Var(&config.MaxCoverageDrop, "max-coverage-drop", 0, "With -baseline, exit with an error if coverage dropped by more than this many percentage points")
+	flags.StringVar(&config.IgnoreCoAuthors, "ignore-coauthors", strings.Join(git.DefaultPlaceholderCoAuthors, ","), "Comma-separated co-author names or emails to ignore, such as template placeholders (empty to keep all)")
+	flags.BoolVar(&config.PairsOnly, "pairs-only", false, "Only count two-person commits, ignoring mob commits with more than one co-author, so a pair only counts days they had a dedicated session")
+	flags.BoolVar(&config.PairsOnly, "dedicated-only", false, "Same as -pairs-only: only count days a pair had a dedicated two-person session")
+	flags.BoolVar(&config.MobsOnly, "mobs-only", false, "Only count mob commits with three or more participants, ignoring two-person pairing")
+	flags.BoolVar(&config.StrictTeam, "strict-team", false, "Exit with an error listing anyone who made commits with the team but isn't in the .team file")
+	flags.StringVar(&config.Theme, "theme", "light", "Color scheme for -output html or calendar: 'light' (default) or 'dark'")
+	flags.BoolVar(&config.Print, "print", false, "Optimize HTML output for printing: black on white, with the matrix scaled to fit the page (requires -output=html)")
+	flags.StringVar(&config.DateStyle, "date-style", "relative", "How recommendations show when a pair last paired: 'relative' (default, e.g. '3 days ago') or 'absolute' (e.g. '2024-05-12')")
+	flags.StringVar(&config.FromNotes, "from-notes", "", "Also read Co-authored-by trailers from git notes in this notes ref (e.g. 'commits' for the default notes)")
+	flags.StringVar(&config.RecentThreshold, "recent-threshold", "", "Flag recommended pairs who paired within this long (e.g. 7d, 2w) as recently paired")
+	flags.BoolVar(&config.DemoteRecent, "demote-recent", false, "Recommend pairs within -recent-threshold only after every other pair")
+	flags.StringVar(&config.Metric, "metric", "", "Print a single value instead of the matrix: "+strings.Join(stats.Metrics, ", "))
+	flags.StringVar(&config.WriteNotes, "write-notes", "", "Write the results as JSON to a git note on HEAD under this notes ref (e.g. 'pairstair'), replacing any note already there")
+	flags.BoolVar(&config.DetectSelfPairs, "detect-self-pairs", false, "Warn about commits where the author seems to be a co-author under another email (same name, different email)")
+	flags.BoolVar(&config.Totals, "totals", false, "Add each developer's total pairings to the matrix, as a row and column (with -output cli, html or confluence)")
+	flags.StringVar(&config.Hours, "hours", "", "Only count commits made during these working hours in the local timezone (e.g. '09:00-18:00'); default all hours")
+	flags.StringVar(&config.LogFile, "log-file", "", "Append a JSON record of each run (window, team, developers, coverage and warnings) to this file")
+	flags.StringVar(&config.Labels, "labels", "initials", "How developers are labelled in the matrix: 'initials' (default), 'firstname', 'emailprefix' or a number of characters from the start of their name")
+	flags.StringVar(&config.Range, "range", "", "Analyze the commits in a git revision range (e.g. 'v1.0..v1.1') instead of a time window")
+	flags.BoolVar(&config.ShowConfig, "show-config", false, "Print the configuration that would be used, including the resolved window, team files and git command, without running the analysis")
+	flags.StringVar(&config.Repo, "repo", "", "Path to the git repository to analyze (default: the current directory)")
+	flags.BoolVar(&config.FrequentNames, "frequent-names", false, "Name developers by the name they commit under most often, when the .team file doesn't name them or there is no .team file")
+	flags.StringVar(&config.RecencyCap, "recency-cap", "", "Longest time since a pair last paired to show (e.g. 1y); longer is shown as the cap with a '+', e.g. '365+ days ago'. Default is no cap")
+	flags.StringVar(&config.MinGap, "min-gap", "", "Never recommend a pair who paired more recently than this (e.g. 3d); developers with no other partner are left unpaired")
+	flags.StringVar(&config.Out, "out", "", "Write the output to these files instead of stdout, one per -output format, e.g. -output html,json -out report.html,report.json")
+	flags.Int64Var(&config.Seed, "seed", 0, "Break ties between equally good pairs at random, reproducibly for the same non-zero seed (default: alphabetically)")
+	flags.StringVar(&config.Only, "only", "", "Only count pairing between these developers, as a comma-separated list of emails, dropping everyone else from each commit")
+	flags.BoolVar(&config.NormalizeNames, "normalize-names", false, "Title-case display names committed all in lowercase or uppercase, e.g. 'bob jones' as 'Bob Jones'; .team file names are used as written")
+	flags.BoolVar(&config.GroupBySubTeam, "group-by-subteam", false, "Order the matrix by sub-team, with a gap (or a thicker border in HTML) between sub-teams; developers in several sub-teams are shown in the first listed for them")
+	flags.IntVar(&config.LastCommits, "last-commits", 0, "Analyze the N most recent commits, whatever their age, instead of a time window (e.g. for quiet repositories)")
+	flags.BoolVar(&config.FailOnEmpty, "fail-on-empty", false, "Exit with status 4, instead of showing an empty matrix, if no pairing is found")
+	flags.BoolVar(&config.Transpose, "transpose", false, "Swap the rows and columns of the matrix, so the attribution report's matrix has the authors across the top")
+	flags.BoolVar(&config.ListTeams, "list-teams", false, "Print the main team and each sub-team of the team files, with how many developers they have, without running the analysis")
+	flags.StringVar(&config.Since, "since", "", "Analyze commits from this date (YYYY-MM-DD) instead of a -window, e.g. the start of a quarter")
+	flags.StringVar(&config.Until, "until", "", "Analyze commits up to and including this date (YYYY-MM-DD) instead of up to now")
+	flags.BoolVar(&config.NoMailmap, "no-mailmap", false, "Read names and emails as committed, without mapping them through the repository's .mailmap as git log does")
+	flags.StringVar(&config.StaleThreshold, "stale-threshold", "14d", "Flag developers in -report stale-developers who last paired longer ago than this (e.g. 7d, 2w)")
+	flags.BoolVar(&config.IncludeReviewers, "include-reviewers", false, "Also show a separate matrix of Reviewed-by trailers, counted as reviews rather than pairing")
+	flags.IntVar(&config.MinTeam, "min-team", 2, "The fewest active developers, who committed in the window, to recommend pairs for")
+	flags.BoolVar(&config.RecurseSubmodules, "recurse-submodules", false, "Also read the commits of the repository's checked out submodules, and theirs in turn")
+	flags.StringVar(&config.MatrixStyle, "matrix-style", string(output.CountStyle), "What the CLI matrix shows for each pair: 'count' or 'recency-bands', a symbol for how recently they last paired")
+	flags.BoolVar(&config.FirstParent, "first-parent", false, "Only read commits on the first-parent line of history (git log --first-parent), skipping commits on merged branches so pairing comes from merge and squash commits")
+	flags.BoolVar(&config.DumpCommits, "dump-commits", false, "Print the commits as parsed and filtered, one JSON object per line, instead of analyzing them (for debugging counts)")
+	flags.StringVar(&config.Import, "import", "", "Also count pairings recorded outside git, from a CSV file of 'date,emailA,emailB' lines (dates as YYYY-MM-DD)")
+	flags.StringVar(&config.BigTeamMode, "big-team-mode", "skip", "What to do for teams larger than -greedy-cutoff: 'skip' recommendations, 'sample' a random (-seed) set of -greedy-cutoff developers, or match everyone 'greedy'")
+	flags.BoolVar(&config.StrictEmails, "strict-emails", false, "Ignore co-authors whose email isn't a valid address (e.g. 'Co-authored-by: Alice <not an email>') instead of just warning about them")
+	flags.IntVar(&config.SparklinePairs, "sparkline-pairs", output.DefaultSparklinePairs, "With -output sparklines, how many of the most paired pairs to show")
+	flags.BoolVar(&config.NoUnicode, "no-unicode", false, "Draw -output sparklines with ASCII characters instead of Unicode blocks")
+	flags.StringVar(&config.DateBasis, "date-basis", "author", "Which commit date to use for the window and recency: 'author' (when the change was written) or 'committer' (when it landed, e.g. after a rebase)")
+	flags.BoolVar(&config.Progress, "progress", false, "Print progress to stderr while fetching commits and building the matrix, for large repositories")
+	flags.StringVar(&config.LabelsOut, "labels-out", "", "With -output npmatrix, write the email for each row of the matrix to this file")
+	flags.StringVar(&config.RecencyWindow, "recency-window", "", "Read when pairs last paired from this wider window (e.g. 1y), so pairs with no count in -window can still show when they last paired")
+	flags.StringVar(&config.SitOut, "sit-out", "most-paired", "Who to leave unpaired when there's an odd number of developers: 'most-paired', 'most-recent' (whoever paired last) or 'any'")
+	flags.Parse(args)
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "window" {
+			config.WindowSet = true
+		}
+	})
 	return config
 }
 
-// parseStrategy converts a strategy string to a recommend.Strategy type
+// parseStrategy converts a strategy string to a recommend.Strategy type.
+// Multiple comma-separated strategies are combined, with later ones breaking ties.
 func parseStrategy(strategyStr string) recommend.Strategy {
+	var components []string
+	for _, component := range recommend.Strategy(strategyStr).Components() {
+		components = append(components, string(parseSingleStrategy(string(component))))
+	}
+	if len(components) == 0 {
+		return recommend.LeastPaired
+	}
+	return recommend.Strategy(strings.Join(components, ","))
+}
+
+// parseSingleStrategy converts a single strategy name to a recommend.Strategy type
+func parseSingleStrategy(strategyStr string) recommend.Strategy {
 	switch strategyStr {
 	case "least-recent":
 		return recommend.LeastRecent
+	case "coverage":
+		return recommend.Coverage
 	default: // least-paired
 		return recommend.LeastPaired
 	}
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // exitOnError exits the program with an error message if err is not nil
 func exitOnError(err error, message string) {
+	exitWithStatusOnError(err, message, exitError)
+}
+
+// exitWithStatusOnError exits the program with an error message and the given
+// exit status if err is not nil
+func exitWithStatusOnError(err error, message string, status int) {
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
-		os.Exit(1)
+		exit(status, fmt.Sprintf("%s: %v", message, err))
+	}
+}
+
+// exitHook, if set, is called with the exit status and the reason before exit
+// exits, e.g. to write the run log
+var exitHook func(status int, reason string)
+
+// exit prints the reason to stderr and exits with the given status, calling the
+// exitHook first
+func exit(status int, reason string) {
+	fmt.Fprintln(os.Stderr, reason)
+	if exitHook != nil {
+		exitHook(status, reason)
 	}
+	os.Exit(status)
 }
 
 // getVersion returns the version string, preferring build info over the constant
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/github"
+	"github.com/gypsydave5/pairstair/internal/output"
 	"github.com/gypsydave5/pairstair/internal/pairing"
 	"github.com/gypsydave5/pairstair/internal/recommend"
 	"github.com/gypsydave5/pairstair/internal/team"
@@ -576,12 +583,10 @@ Grace Ops <grace@example.com>
 	}
 
 	// Test reading non-existent sub-team
-	nonExistentTeam, err := team.ReadTeamFile(teamFile, "nonexistent")
-	if err != nil {
-		t.Fatalf("Failed to read team file: %v", err)
-	}
-	if len(nonExistentTeam) != 0 {
-		t.Errorf("Expected empty team for non-existent sub-team, got %d members", len(nonExistentTeam))
+	_, err = team.ReadTeamFile(teamFile, "nonexistent")
+	var notFound *team.SubTeamNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected a sub-team not found error for non-existent sub-team, got %v", err)
 	}
 }
 
@@ -845,3 +850,780 @@ func TestConfigOpenFlag(t *testing.T) {
 		t.Error("Expected Output to be html")
 	}
 }
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name:   "defaults are valid",
+			config: Config{Output: "cli"},
+		},
+		{
+			name:   "open with html output",
+			config: Config{Output: "html", Open: true},
+		},
+		{
+			name:    "open with cli output",
+			config:  Config{Output: "cli", Open: true},
+			wantErr: "-open",
+		},
+		{
+			name:    "negative plan",
+			config:  Config{Output: "cli", Plan: -1},
+			wantErr: "-plan",
+		},
+		{
+			name:    "plan with report",
+			config:  Config{Output: "cli", Plan: 3, Report: "lone-wolves"},
+			wantErr: "-plan and -report",
+		},
+		{
+			name:    "plan with html output",
+			config:  Config{Output: "html", Plan: 3},
+			wantErr: "-plan only supports",
+		},
+		{
+			name:   "plan with ics output",
+			config: Config{Output: "ics", Plan: 5},
+		},
+		{
+			name:    "ics output without plan",
+			config:  Config{Output: "ics"},
+			wantErr: "-output ics requires -plan",
+		},
+		{
+			name:    "report with slack output",
+			config:  Config{Output: "slack", Report: "last-paired"},
+			wantErr: "-output cli",
+		},
+		{
+			name:    "post-url with cli output",
+			config:  Config{Output: "cli", PostURL: "http://example.com"},
+			wantErr: "-post-url",
+		},
+		{
+			name:   "post-url with slack output",
+			config: Config{Output: "slack", PostURL: "http://example.com", Quiet: true},
+		},
+		{
+			name:    "quiet without post-url",
+			config:  Config{Output: "cli", Quiet: true},
+			wantErr: "-quiet",
+		},
+		{
+			name:   "baseline on its own",
+			config: Config{Output: "cli", Baseline: "baseline.json", MaxCoverageDrop: 5},
+		},
+		{
+			name:    "baseline with html output",
+			config:  Config{Output: "html", Baseline: "baseline.json"},
+			wantErr: "-baseline",
+		},
+		{
+			name:    "baseline with report",
+			config:  Config{Output: "cli", Baseline: "baseline.json", Report: "lone-wolves"},
+			wantErr: "-baseline",
+		},
+		{
+			name:    "negative cutoff",
+			config:  Config{Output: "cli", OptimalCutoff: -1},
+			wantErr: "cutoff",
+		},
+		{
+			name:   "dark theme with html output",
+			config: Config{Output: "html", Theme: "dark"},
+		},
+		{
+			name:    "dark theme with cli output",
+			config:  Config{Output: "cli", Theme: "dark"},
+			wantErr: "-theme",
+		},
+		{
+			name:    "print with calendar output",
+			config:  Config{Output: "calendar", Print: true},
+			wantErr: "-print",
+		},
+		{
+			name:    "demote-recent without a threshold",
+			config:  Config{Output: "cli", DemoteRecent: true},
+			wantErr: "-recent-threshold",
+		},
+		{
+			name:   "metric on its own",
+			config: Config{Output: "cli", Metric: "coverage"},
+		},
+		{
+			name:    "metric with json output",
+			config:  Config{Output: "json", Metric: "coverage"},
+			wantErr: "-metric",
+		},
+		{
+			name:   "write-notes with json output",
+			config: Config{Output: "json", WriteNotes: "pairstair"},
+		},
+		{
+			name:    "write-notes with a report",
+			config:  Config{Output: "cli", WriteNotes: "pairstair", Report: "lone-wolves"},
+			wantErr: "-write-notes can't be used with",
+		},
+		{
+			name:    "write-notes with a subcommand",
+			config:  Config{Output: "cli", WriteNotes: "pairstair", Command: commandMatrix},
+			wantErr: "-write-notes can't be used with",
+		},
+		{
+			name:   "range",
+			config: Config{Output: "cli", Range: "v1.0..v1.1"},
+		},
+		{
+			name:    "range with window",
+			config:  Config{Output: "cli", Range: "v1.0..v1.1", WindowSet: true},
+			wantErr: "-range can't be used with",
+		},
+		{
+			name:    "range with since-last-run",
+			config:  Config{Output: "cli", Range: "v1.0..v1.1", SinceLastRun: true},
+			wantErr: "-range can't be used with",
+		},
+		{
+			name:    "no-unicode without sparklines",
+			config:  Config{Output: "cli", NoUnicode: true},
+			wantErr: "-no-unicode only applies to -output sparklines",
+		},
+		{
+			name:   "no-unicode with recency bands",
+			config: Config{Output: "cli", MatrixStyle: "recency-bands", NoUnicode: true},
+		},
+		{
+			name:    "recency bands without cli output",
+			config:  Config{Output: "html", MatrixStyle: "recency-bands"},
+			wantErr: "-matrix-style recency-bands only applies to -output cli",
+		},
+		{
+			name:   "no-unicode with sparklines",
+			config: Config{Output: "sparklines", NoUnicode: true, SparklinePairs: 5},
+		},
+		{
+			name:    "labels-out without npmatrix",
+			config:  Config{Output: "tsv", LabelsOut: "labels.txt"},
+			wantErr: "-labels-out only applies to -output npmatrix",
+		},
+		{
+			name:   "labels-out with npmatrix",
+			config: Config{Output: "npmatrix", LabelsOut: "labels.txt"},
+		},
+		{
+			name:    "dump-commits with report",
+			config:  Config{Output: "cli", DumpCommits: true, Report: "last-paired"},
+			wantErr: "-dump-commits prints the commits instead of the results",
+		},
+		{
+			name:   "dump-commits with a window",
+			config: Config{Output: "cli", DumpCommits: true, Window: "1m", WindowSet: true},
+		},
+		{
+			name:    "invalid window",
+			config:  Config{Output: "cli", Window: "2x"},
+			wantErr: `invalid -window "2x": use a number and d, w, m or y, such as 2w, or all`,
+		},
+		{
+			name:   "window all",
+			config: Config{Output: "cli", Window: "all"},
+		},
+		{
+			name:    "split mob weight with npmatrix",
+			config:  Config{Output: "npmatrix", MobWeight: "split"},
+			wantErr: "-output npmatrix only has whole counts",
+		},
+		{
+			name:   "split mob weight with tsv",
+			config: Config{Output: "tsv", MobWeight: "split"},
+		},
+		{
+			name:    "transpose with json",
+			config:  Config{Output: "json", Transpose: true},
+			wantErr: "-transpose only applies to -output cli or html",
+		},
+		{
+			name:   "transpose with html",
+			config: Config{Output: "html", Transpose: true},
+		},
+		{
+			name:    "list-teams with a subcommand",
+			config:  Config{Output: "cli", ListTeams: true, Command: "matrix"},
+			wantErr: "-list-teams prints the teams instead of the results",
+		},
+		{
+			name:    "invalid since",
+			config:  Config{Output: "cli", Since: "1/1/2024"},
+			wantErr: `invalid -since "1/1/2024": use YYYY-MM-DD`,
+		},
+		{
+			name:    "since after until",
+			config:  Config{Output: "cli", Since: "2024-03-31", Until: "2024-01-01"},
+			wantErr: "-since 2024-03-31 is after -until 2024-01-01",
+		},
+		{
+			name:   "since and until on the same day",
+			config: Config{Output: "cli", Since: "2024-03-31", Until: "2024-03-31"},
+		},
+		{
+			name:    "until with range",
+			config:  Config{Output: "cli", Until: "2024-03-31", Range: "v1..v2"},
+			wantErr: "-since and -until can't be used with -range",
+		},
+		{
+			name:    "invalid stale-threshold",
+			config:  Config{Output: "cli", StaleThreshold: "all"},
+			wantErr: `invalid -stale-threshold "all"`,
+		},
+		{
+			name:    "include-reviewers with html",
+			config:  Config{Output: "html", IncludeReviewers: true},
+			wantErr: "-include-reviewers only applies to the -output cli matrix",
+		},
+		{
+			name:    "negative min-team",
+			config:  Config{Output: "cli", MinTeam: -1},
+			wantErr: "-min-team must not be negative",
+		},
+		{
+			name:    "recurse-submodules with range",
+			config:  Config{Output: "cli", Range: "v1.0..v1.1", RecurseSubmodules: true},
+			wantErr: "-recurse-submodules can't be used with -range or -last-commits",
+		},
+		{
+			name:    "import with last-commits",
+			config:  Config{Output: "cli", Import: "pairings.csv", LastCommits: 10},
+			wantErr: "-import can't be used with -range or -last-commits",
+		},
+		{
+			name:    "recency-window with range",
+			config:  Config{Output: "cli", RecencyWindow: "1y", Range: "v1.0..v1.1"},
+			wantErr: "-recency-window can't be used with",
+		},
+		{
+			name:    "recency-window with plan",
+			config:  Config{Output: "cli", RecencyWindow: "1y", Plan: 5},
+			wantErr: "-recency-window doesn't apply to -plan",
+		},
+		{
+			name:    "last-commits with window",
+			config:  Config{Output: "cli", LastCommits: 10, WindowSet: true},
+			wantErr: "-last-commits can't be used with",
+		},
+		{
+			name:    "negative last-commits",
+			config:  Config{Output: "cli", LastCommits: -1},
+			wantErr: "-last-commits must not be negative",
+		},
+		{
+			name:   "last-commits with range",
+			config: Config{Output: "cli", LastCommits: 10, Range: "v1.0..v1.1"},
+		},
+		{
+			name:    "min-gap with plan",
+			config:  Config{Output: "cli", MinGap: "3d", Plan: 5},
+			wantErr: "-min-gap doesn't apply to -plan",
+		},
+		{
+			name:   "min-gap",
+			config: Config{Output: "cli", MinGap: "3d"},
+		},
+		{
+			name:    "several outputs without out",
+			config:  Config{Output: "html,json"},
+			wantErr: "requires -out",
+		},
+		{
+			name:    "fewer out files than outputs",
+			config:  Config{Output: "html,json", Out: "report.html"},
+			wantErr: "-output has 2 formats but -out has 1 files",
+		},
+		{
+			name:    "out with an output that can't be written to a file",
+			config:  Config{Output: "html,cli", Out: "report.html,report.txt"},
+			wantErr: "-out only supports",
+		},
+		{
+			name:    "out with a report",
+			config:  Config{Output: "json", Out: "report.json", Report: "lone-wolves"},
+			wantErr: "-report only supports -output cli",
+		},
+		{
+			name:   "several outputs with out",
+			config: Config{Output: "html,json", Out: "report.html,report.json"},
+		},
+		{
+			name:   "theme with several outputs including html",
+			config: Config{Output: "html,json", Out: "report.html,report.json", Theme: "dark"},
+		},
+		{
+			name:   "matrix subcommand with stair output",
+			config: Config{Output: "stair", Command: commandMatrix},
+		},
+		{
+			name:    "matrix subcommand with a report",
+			config:  Config{Output: "cli", Command: commandMatrix, Report: "lone-wolves"},
+			wantErr: "matrix can't be used with",
+		},
+		{
+			name:    "matrix subcommand with html output",
+			config:  Config{Output: "html", Command: commandMatrix},
+			wantErr: "matrix only supports",
+		},
+		{
+			name:   "recommend subcommand with a plan",
+			config: Config{Output: "ics", Command: commandRecommend, Plan: 5},
+		},
+		{
+			name:    "recommend subcommand with json output",
+			config:  Config{Output: "json", Command: commandRecommend},
+			wantErr: "recommend only supports",
+		},
+		{
+			name:   "stats subcommand with a report",
+			config: Config{Output: "cli", Command: commandStats, Report: "last-paired"},
+		},
+		{
+			name:    "stats subcommand with a plan",
+			config:  Config{Output: "cli", Command: commandStats, Plan: 5},
+			wantErr: "stats can't be used with",
+		},
+		{
+			name:    "validate-team subcommand with a metric",
+			config:  Config{Output: "cli", Command: commandValidateTeam, Metric: "coverage"},
+			wantErr: "validate-team can't be used with",
+		},
+		{
+			name:    "pairs-only with mobs-only",
+			config:  Config{Output: "cli", PairsOnly: true, MobsOnly: true},
+			wantErr: "-pairs-only (or -dedicated-only) and -mobs-only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error mentioning %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error mentioning %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCommand string
+		wantArgs    []string
+		wantErr     string
+	}{
+		{name: "no arguments", args: nil, wantCommand: "", wantArgs: nil},
+		{name: "flags without a subcommand", args: []string{"-window", "2w"}, wantCommand: "", wantArgs: []string{"-window", "2w"}},
+		{name: "subcommand with flags", args: []string{"matrix", "-window", "2w"}, wantCommand: commandMatrix, wantArgs: []string{"-window", "2w"}},
+		{name: "subcommand alone", args: []string{"validate-team"}, wantCommand: commandValidateTeam, wantArgs: []string{}},
+		{name: "init-team", args: []string{"init-team", "-github-org", "acme"}, wantCommand: commandInitTeam, wantArgs: []string{"-github-org", "acme"}},
+		{name: "install-hook", args: []string{"install-hook", "-force"}, wantCommand: commandInstallHook, wantArgs: []string{"-force"}},
+		{name: "unknown subcommand", args: []string{"matrx"}, wantErr: "unknown command: matrx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args, err := splitCommand(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error mentioning %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if command != tt.wantCommand {
+				t.Errorf("Expected command %q, got %q", tt.wantCommand, command)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("Expected args %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestParseFlagSetSharedFlags(t *testing.T) {
+	for _, argv := range [][]string{
+		{"-window", "2w", "-team", "frontend"},
+		{"stats", "-window", "2w", "-team", "frontend"},
+		{"recommend", "-window", "2w", "-team", "frontend"},
+	} {
+		_, args, err := splitCommand(argv)
+		if err != nil {
+			t.Fatalf("Expected no error for %v, got %v", argv, err)
+		}
+		config := parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), args)
+		if config.Window != "2w" || config.Team != "frontend" {
+			t.Errorf("Expected window 2w and team frontend for %v, got %q and %q", argv, config.Window, config.Team)
+		}
+	}
+}
+
+func TestParseFlagSetDefaults(t *testing.T) {
+	config := parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), nil)
+	if config.Window != "1w" || config.Output != "cli" || config.Command != "" {
+		t.Errorf("Expected the default window, output and no command, got %q, %q and %q", config.Window, config.Output, config.Command)
+	}
+	if config.WindowSet {
+		t.Error("Expected the default window not to count as set")
+	}
+
+	config = parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), []string{"-window", "1w"})
+	if !config.WindowSet {
+		t.Error("Expected -window to count as set, even with the default value")
+	}
+}
+
+func TestParseFlagSetDedicatedOnly(t *testing.T) {
+	config := parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), []string{"-dedicated-only"})
+	if !config.PairsOnly {
+		t.Error("Expected -dedicated-only to set the same option as -pairs-only")
+	}
+
+	config = parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), []string{"-dedicated-only", "-mobs-only"})
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "-dedicated-only) and -mobs-only") {
+		t.Errorf("Expected -dedicated-only with -mobs-only to be rejected, got %v", err)
+	}
+}
+
+func TestWriteDraftTeam(t *testing.T) {
+	members := []github.Member{
+		{ID: 583231, Login: "octocat", Name: "The Octocat", Email: "octocat@github.com"},
+		{ID: 42, Login: "hubot"},
+	}
+
+	var b strings.Builder
+	if err := writeDraftTeam(&b, "acme", members); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".team")
+	if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatalf("failed to write team file: %v", err)
+	}
+	teamObj, err := team.NewTeamFromFile(path, "")
+	if err != nil {
+		t.Fatalf("draft team file should parse: %v", err)
+	}
+
+	developers := teamObj.GetDevelopers()
+	if len(developers) != 2 {
+		t.Fatalf("Expected 2 developers in the draft, got %d", len(developers))
+	}
+	if !strings.Contains(b.String(), "acme") {
+		t.Errorf("Expected the draft to mention the organization, got:\n%s", b.String())
+	}
+}
+
+func TestCoAuthorHook(t *testing.T) {
+	hook := filepath.Join(t.TempDir(), "prepare-commit-msg")
+	developers := []git.Developer{
+		git.NewDeveloper("Alice Smith <alice@example.com>"),
+		git.NewDeveloper("Bob Jones <bob@example.com>"),
+	}
+	if err := os.WriteFile(hook, []byte(coAuthorHook(developers)), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	runHook := func(message string, args ...string) string {
+		t.Helper()
+		messageFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		if err := os.WriteFile(messageFile, []byte(message), 0644); err != nil {
+			t.Fatalf("failed to write message: %v", err)
+		}
+		if out, err := exec.Command("sh", append([]string{hook, messageFile}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("hook failed: %v\n%s", err, out)
+		}
+		written, err := os.ReadFile(messageFile)
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		return string(written)
+	}
+
+	got := runHook("\n# Please enter the commit message for your changes.\n")
+	for _, want := range []string{"# Co-authored-by: Alice Smith <alice@example.com>\n", "# Co-authored-by: Bob Jones <bob@example.com>\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected a new commit's message to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for name, args := range map[string][]string{"message given with -m": {"message"}, "amend": {"commit", "HEAD"}, "merge": {"merge"}} {
+		if got := runHook("Fix the build\n", args...); got != "Fix the build\n" {
+			t.Errorf("%s: expected the message to be left alone, got:\n%s", name, got)
+		}
+	}
+	if got := runHook("\nCo-authored-by: Bob Jones <bob@example.com>\n"); strings.Contains(got, "Alice") {
+		t.Errorf("Expected a message that already has trailers to be left alone, got:\n%s", got)
+	}
+
+	if template := coAuthorHook(nil); !strings.Contains(template, "# Co-authored-by: Name <email@example.com>\n") {
+		t.Errorf("Expected an example trailer without a team, got:\n%s", template)
+	}
+}
+
+func TestWriteRunLog(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 30, 0, 0, time.UTC)
+	record := runRecord{
+		Window:     "2w",
+		Team:       "frontend",
+		Developers: 4,
+		Coverage:   0.5,
+		Warnings:   []string{"Alice: alice@example.com with co-author alice@home.example (1 commits)"},
+	}
+
+	var b strings.Builder
+	if err := writeRunLog(&b, record, now); err != nil {
+		t.Fatalf("writeRunLog failed: %v", err)
+	}
+	if !strings.HasSuffix(b.String(), "}\n") || strings.Count(b.String(), "\n") != 1 {
+		t.Errorf("Expected a single line of JSON, got %q", b.String())
+	}
+
+	var logged struct {
+		Time       time.Time `json:"time"`
+		Msg        string    `json:"msg"`
+		Window     string    `json:"window"`
+		Team       string    `json:"team"`
+		Developers int       `json:"developers"`
+		Coverage   float64   `json:"coverage"`
+		Warnings   []string  `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &logged); err != nil {
+		t.Fatalf("Expected the log record to be JSON: %v", err)
+	}
+	if !logged.Time.Equal(now) {
+		t.Errorf("Expected time %v, got %v", now, logged.Time)
+	}
+	if logged.Window != "2w" || logged.Team != "frontend" || logged.Developers != 4 || logged.Coverage != 0.5 {
+		t.Errorf("Expected the window, team, developers and coverage to be logged, got %+v", logged)
+	}
+	if len(logged.Warnings) != 1 || logged.Warnings[0] != record.Warnings[0] {
+		t.Errorf("Expected the warnings to be logged, got %v", logged.Warnings)
+	}
+
+	// No warnings are logged as an empty list
+	b.Reset()
+	if err := writeRunLog(&b, runRecord{Window: "1w"}, now); err != nil {
+		t.Fatalf("writeRunLog failed: %v", err)
+	}
+	if !strings.Contains(b.String(), `"warnings":[]`) {
+		t.Errorf("Expected an empty list of warnings, got %s", b.String())
+	}
+	if !strings.Contains(b.String(), `"level":"INFO"`) || !strings.Contains(b.String(), `"status":0`) || strings.Contains(b.String(), `"error"`) {
+		t.Errorf("Expected a successful run to log status 0 and no error, got %s", b.String())
+	}
+
+	// A failed run is logged as an error, with why it failed
+	b.Reset()
+	if err := writeRunLog(&b, runRecord{Window: "1w", Status: exitThreshold, Error: "No pairing found in the last week"}, now); err != nil {
+		t.Fatalf("writeRunLog failed: %v", err)
+	}
+	for _, want := range []string{`"level":"ERROR"`, `"status":4`, `"error":"No pairing found in the last week"`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected the failed run's record to contain %s, got %s", want, b.String())
+		}
+	}
+}
+
+func TestAppendRunLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairstair.log")
+	now := time.Date(2024, 6, 12, 9, 30, 0, 0, time.UTC)
+
+	appendRunLog(path, &runRecord{Window: "1w"}, now)
+	appendRunLog(path, &runRecord{Window: "2w"}, now)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the log file to be written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"window":"1w"`) || !strings.Contains(lines[1], `"window":"2w"`) {
+		t.Errorf("Expected one record appended per run, got:\n%s", content)
+	}
+
+	// A log file that can't be written only warns
+	appendRunLog(filepath.Join(t.TempDir(), "missing", "pairstair.log"), &runRecord{}, now)
+}
+
+func TestPrintConfig(t *testing.T) {
+	wd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(wd, ".team"), []byte("Alice Smith <alice@example.com>\n"), 0644); err != nil {
+		t.Fatalf("failed to write team file: %v", err)
+	}
+	config := parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), []string{"-window", "2w", "-team", "frontend", "-strategy", "least-recent", "-ignore-coauthors", "Bot"})
+	now := time.Date(2024, 6, 29, 12, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+	if err := printConfig(&b, config, wd, now); err != nil {
+		t.Fatalf("printConfig failed: %v", err)
+	}
+	shown := b.String()
+
+	for _, want := range []string{
+		"Window:",
+		"2w (2024-06-15 to 2024-06-29)",
+		filepath.Join(wd, ".team") + "\n",
+		"Sub-team:",
+		"frontend",
+		"Strategy:",
+		"least-recent",
+		"Ignored co-authors:",
+		"Bot",
+		"Git command:",
+		"git log --since=2.weeks",
+	} {
+		if !strings.Contains(shown, want) {
+			t.Errorf("Expected the configuration to contain %q, got:\n%s", want, shown)
+		}
+	}
+
+	config = parseFlagSet(flag.NewFlagSet("pairstair", flag.ContinueOnError), []string{"-range", "v1.0..v1.1"})
+	b.Reset()
+	if err := printConfig(&b, config, wd, now); err != nil {
+		t.Fatalf("printConfig failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "v1.0..v1.1 --\n") || strings.Contains(b.String(), "Window:") {
+		t.Errorf("Expected the range in place of the window, got:\n%s", b.String())
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	alice := git.NewDeveloper("Alice Smith <alice@example.com>")
+	bob := git.NewDeveloper("Bob Jones <bob@example.com>")
+	commits := []git.Commit{{Date: time.Now(), Author: alice, CoAuthors: []git.Developer{bob}}}
+	matrix, recencyMatrix, developers := pairing.BuildPairMatrix(team.Empty, commits, false)
+	recommendations := recommend.GenerateRecommendations(developers, matrix, recencyMatrix, recommend.LeastPaired)
+
+	dir := t.TempDir()
+	htmlPath, jsonPath := filepath.Join(dir, "report.html"), filepath.Join(dir, "report.json")
+	err := writeOutputs([]string{"html", "json"}, []string{htmlPath, jsonPath}, matrix, recencyMatrix, developers, string(recommend.LeastPaired), recommendations, output.Options{})
+	if err != nil {
+		t.Fatalf("writeOutputs failed: %v", err)
+	}
+
+	html, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Expected the HTML file to be written: %v", err)
+	}
+	if !strings.Contains(string(html), "<html") || !strings.Contains(string(html), "Alice Smith") {
+		t.Errorf("Expected an HTML page with the developers, got:\n%s", html)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Expected the JSON file to be written: %v", err)
+	}
+	var result output.JSONResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, content)
+	}
+	if len(result.Developers) != 2 {
+		t.Errorf("Expected 2 developers in the JSON, got %d", len(result.Developers))
+	}
+
+	// A file that can't be created is an error
+	err = writeOutputs([]string{"json"}, []string{filepath.Join(dir, "missing", "report.json")}, matrix, recencyMatrix, developers, string(recommend.LeastPaired), recommendations, output.Options{})
+	if err == nil {
+		t.Error("Expected an error for a file in a missing directory")
+	}
+}
+
+func TestDescribePeriod(t *testing.T) {
+	tests := []struct {
+		config Config
+		want   string
+	}{
+		{Config{Window: "2w"}, "the last 2 weeks"},
+		{Config{Window: "1m"}, "the last month"},
+		{Config{Window: "90d", LastCommits: 50}, "the last 50 commits"},
+		{Config{Window: "1d", LastCommits: 1}, "the last commit"},
+		{Config{Window: "1d", Range: "v1.0..v1.1"}, "the commits in v1.0..v1.1"},
+		{Config{Window: "1d", SinceLastRun: true}, "the time since the last run"},
+		{Config{Window: "all"}, "the whole history"},
+	}
+	for _, tt := range tests {
+		if got := describePeriod(&tt.config); got != tt.want {
+			t.Errorf("describePeriod(%+v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestUnpairedNote(t *testing.T) {
+	if got, want := unpairedNote(3, "2w"), "3 developers have no partner outside the 2w minimum gap and are left unpaired"; got != want {
+		t.Errorf("unpairedNote with a gap = %q, want %q", got, want)
+	}
+	if got, want := unpairedNote(3, ""), "3 developers are left unpaired"; got != want {
+		t.Errorf("unpairedNote without a gap = %q, want %q", got, want)
+	}
+}
+
+func TestLogOptionsForAllTime(t *testing.T) {
+	opts, err := logOptions(&Config{Window: "all"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Since != "" || !opts.After.IsZero() {
+		t.Errorf("Expected no since or after for the whole history, got %q and %v", opts.Since, opts.After)
+	}
+}
+
+func TestWindowStartForAllTime(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 0, 0, 0, time.UTC)
+	earliest := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	commits := []git.Commit{{Date: now.AddDate(0, -1, 0)}, {Date: earliest}}
+
+	if start, err := windowStart(&Config{Window: "all"}, commits, now); err != nil || !start.Equal(earliest) {
+		t.Errorf("Expected the whole history to start at the earliest commit, got %v, %v", start, err)
+	}
+	if start, err := windowStart(&Config{Window: "1w"}, commits, now); err != nil || !start.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("Expected a week's window to start a week ago, got %v, %v", start, err)
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	var buf strings.Builder
+	progress := newProgress(&buf, true)
+	progress.stage("Fetching commits…")
+	for i := 1; i <= 2500; i++ {
+		progress.logProgress()(i)
+	}
+	progress.parsed(2500)
+
+	want := "Fetching commits…\nParsed 1000 commits…\nParsed 2000 commits…\nParsed 2500 commits\n"
+	if buf.String() != want {
+		t.Errorf("Expected progress:\n%q\ngot:\n%q", want, buf.String())
+	}
+
+	// Without -progress there's nothing to report, and nothing to call
+	disabled := newProgress(&buf, false)
+	disabled.stage("Building matrix…")
+	disabled.parsed(10)
+	if disabled.logProgress() != nil {
+		t.Error("Expected no git log callback without -progress")
+	}
+	if buf.String() != want {
+		t.Errorf("Expected nothing more written without -progress, got %q", buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// progressEvery is how many commits are parsed between running counts
+const progressEvery = 1000
+
+// progressReporter prints what a long analysis is doing with -progress. It writes to
+// stderr, never stdout, so the output stays machine-readable. A nil reporter
+// prints nothing, so callers needn't check whether -progress was given.
+type progressReporter struct {
+	w io.Writer
+}
+
+// newProgress returns a reporter writing to w if enabled, or nil otherwise
+func newProgress(w io.Writer, enabled bool) *progressReporter {
+	if !enabled {
+		return nil
+	}
+	return &progressReporter{w: w}
+}
+
+// stage reports the start of a step, e.g. "Fetching commits…"
+func (p *progressReporter) stage(message string) {
+	if p == nil {
+		return
+	}
+	fmt.Fprintln(p.w, message)
+}
+
+// commits reports a running count of the commits parsed so far, every
+// progressEvery commits
+func (p *progressReporter) commits(parsed int) {
+	if p == nil || parsed%progressEvery != 0 {
+		return
+	}
+	fmt.Fprintf(p.w, "Parsed %d commits…\n", parsed)
+}
+
+// parsed reports the total number of commits parsed
+func (p *progressReporter) parsed(commits int) {
+	p.stage(fmt.Sprintf("Parsed %d commits", commits))
+}
+
+// logProgress returns the callback for git.LogOptions.Progress, or nil if there
+// is no progress to report
+func (p *progressReporter) logProgress() func(int) {
+	if p == nil {
+		return nil
+	}
+	return p.commits
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// runRecord is the summary of a run appended to the -log-file, for auditing
+// scheduled runs
+type runRecord struct {
+	Window     string
+	Team       string
+	Developers int
+	Coverage   float64
+	Warnings   []string
+	// Status is the exit status, and Error why the run failed if it did
+	Status int
+	Error  string
+}
+
+// appendRunLog appends the record to the log file as a line of JSON, warning
+// rather than failing if it can't, so logging never stops the main output
+func appendRunLog(path string, record *runRecord, now time.Time) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		err = writeRunLog(file, *record, now)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write to log file: %v\n", err)
+	}
+}
+
+// writeRunLog writes the record as a JSON slog record made at the given time, at
+// the error level if the run failed
+func writeRunLog(w io.Writer, record runRecord, now time.Time) error {
+	handler := slog.NewJSONHandler(w, nil)
+	level := slog.LevelInfo
+	if record.Status != 0 {
+		level = slog.LevelError
+	}
+	r := slog.NewRecord(now, level, "pairstair run", 0)
+	r.AddAttrs(
+		slog.String("window", record.Window),
+		slog.String("team", record.Team),
+		slog.Int("developers", record.Developers),
+		slog.Float64("coverage", record.Coverage),
+		slog.Any("warnings", warningsOrEmpty(record.Warnings)),
+		slog.Int("status", record.Status),
+	)
+	if record.Error != "" {
+		r.AddAttrs(slog.String("error", record.Error))
+	}
+	return handler.Handle(context.Background(), r)
+}
+
+// warningsOrEmpty returns the warnings, or an empty list rather than nil so the
+// log always has a list to read
+func warningsOrEmpty(warnings []string) []string {
+	if warnings == nil {
+		return []string{}
+	}
+	return warnings
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gypsydave5/pairstair/internal/git"
+	"github.com/gypsydave5/pairstair/internal/team"
+)
+
+// printConfig writes the configuration a run would use, with the window resolved
+// to dates, the team files that would be read and the git command used to read
+// commits, without running the analysis
+func printConfig(w io.Writer, config *Config, wd string, now time.Time) error {
+	opts, err := logOptions(config, wd)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(name, value string) {
+		fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+	}
+
+	row("Command", valueOr(config.Command, "(none: matrix and recommendations)"))
+	switch {
+	case opts.Range != "" && opts.Limit > 0:
+		row("Range", fmt.Sprintf("%s (the last %d commits)", opts.Range, opts.Limit))
+	case opts.Range != "":
+		row("Range", opts.Range)
+	case opts.Limit > 0:
+		row("Last commits", fmt.Sprint(opts.Limit))
+	case config.dateRange():
+		row("Dates", fmt.Sprintf("%s to %s", valueOr(config.Since, "(the first commit)"), valueOr(config.Until, now.Format("2006-01-02"))))
+	case config.SinceLastRun && opts.Since != git.WindowToGitSince(config.Window):
+		row("Since last run", opts.Since)
+	case config.Window == git.AllTime:
+		row("Window", "all (the whole history)")
+	default:
+		start, err := git.WindowStart(config.Window, now)
+		if err != nil {
+			return err
+		}
+		row("Window", fmt.Sprintf("%s (%s to %s)", config.Window, start.Format("2006-01-02"), now.Format("2006-01-02")))
+	}
+	row("Date basis", string(opts.DateBasis)+" date")
+	row("All refs", fmt.Sprint(config.All))
+	row("Submodules", fmt.Sprint(config.RecurseSubmodules))
+
+	var files []string
+	for _, file := range teamFiles(wd) {
+		if _, err := os.Stat(file); err != nil {
+			file += " (not found)"
+		}
+		files = append(files, file)
+	}
+	row("Team files", strings.Join(files, ", "))
+	row("Sub-team", valueOr(config.Team, "(whole team)"))
+	row("Strategy", string(parseStrategy(config.Strategy)))
+	row("Output", config.Output)
+	row("Report", valueOr(config.Report, "(none)"))
+	row("Metric", valueOr(config.Metric, "(none)"))
+	row("Mob weight", config.MobWeight)
+	row("Pairs only", fmt.Sprint(config.PairsOnly))
+	row("Mobs only", fmt.Sprint(config.MobsOnly))
+	row("Exclude today", fmt.Sprint(config.ExcludeToday))
+	row("Hours", valueOr(config.Hours, "(all hours)"))
+	row("Only", valueOr(strings.Join(splitList(config.Only), ", "), "(everyone)"))
+	row("Import", valueOr(config.Import, "(none)"))
+	row("Ignored co-authors", valueOr(strings.Join(splitList(config.IgnoreCoAuthors), ", "), "(none)"))
+	row("Labels", config.Labels)
+	row("Normalize names", fmt.Sprint(config.NormalizeNames))
+	row("Recency cap", valueOr(config.RecencyCap, "(none)"))
+	row("Recency window", valueOr(config.RecencyWindow, "(same as the counts)"))
+	row("Minimum gap", valueOr(config.MinGap, "(none)"))
+	row("Sit out", config.SitOut)
+	row("Big team mode", config.BigTeamMode)
+	if config.Seed != 0 {
+		row("Seed", fmt.Sprint(config.Seed))
+	} else {
+		row("Seed", "(none, ties are broken alphabetically)")
+	}
+	row("Git command", "git "+strings.Join(git.BuildLogArgs(opts), " "))
+	return tw.Flush()
+}
+
+// valueOr returns the value, or the fallback if the value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// printTeams writes the main team and each sub-team of the team files, with how many
+// developers are in them, for choosing a -team
+func printTeams(w io.Writer, wd string) error {
+	subTeams, err := team.ListSubTeams(teamFiles(wd))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no team file: create %s or ~/.pairstair/team", filepath.Join(wd, ".team"))
+	}
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Teams (choose a sub-team with -team):")
+	for _, subTeam := range subTeams {
+		name := valueOr(subTeam.Name, "(main)")
+		members := "members"
+		if subTeam.Members == 1 {
+			members = "member"
+		}
+		fmt.Fprintf(tw, "  %s\t%d %s\n", name, subTeam.Members, members)
+	}
+	return tw.Flush()
+}